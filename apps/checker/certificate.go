@@ -0,0 +1,102 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+// certificates tracks the expiry of the certificates of the monitors, to
+// notify each threshold once per certificate.
+type certificates struct {
+	// thresholds are in days, in decreasing order.
+	thresholds []int
+
+	mu       sync.Mutex
+	notified map[string]certificate
+}
+
+// certificate is the last threshold notified for the certificate of a
+// monitor.
+type certificate struct {
+	expiry    time.Time
+	threshold int
+}
+
+func newCertificates(days []int) *certificates {
+	thresholds := append([]int(nil), days...)
+	sort.Sort(sort.Reverse(sort.IntSlice(thresholds)))
+
+	return &certificates{thresholds: thresholds, notified: map[string]certificate{}}
+}
+
+// crossed returns the threshold crossed by the certificate of the monitor
+// at now, if not notified yet. A renewed certificate starts over.
+func (c *certificates) crossed(monitorID string, expiry, now time.Time) (int, bool) {
+	threshold := 0
+	for _, days := range c.thresholds {
+		if expiry.Sub(now) <= time.Duration(days)*24*time.Hour {
+			threshold = days
+		}
+	}
+	if threshold == 0 {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, ok := c.notified[monitorID]
+	if ok && last.expiry.Equal(expiry) && last.threshold <= threshold {
+		return 0, false
+	}
+	c.notified[monitorID] = certificate{expiry: expiry, threshold: threshold}
+
+	return threshold, true
+}
+
+// WithCertificateThresholds notifies the certificates of the monitors
+// expiring within the thresholds, in days, e.g. 30, 14, 7 and 1, once per
+// threshold.
+func WithCertificateThresholds(days ...int) RunnerOption {
+	return func(r *Runner) {
+		r.certificates = newCertificates(days)
+	}
+}
+
+// certificate notifies the threshold crossed by the certificate of the
+// monitor, if any.
+func (r Runner) certificate(ctx context.Context, req request.CheckerRequest, data PingData) {
+	if r.certificates == nil || r.notifier == nil || data.CertificateExpiry == 0 {
+		return
+	}
+
+	expiry := time.UnixMilli(data.CertificateExpiry).UTC()
+	threshold, ok := r.certificates.crossed(req.MonitorID, expiry, time.Now())
+	if !ok {
+		return
+	}
+
+	err := r.notifier.Notify(ctx, notify.Notification{
+		WorkspaceID:       req.WorkspaceID,
+		MonitorID:         req.MonitorID,
+		URL:               req.URL,
+		Region:            data.Region,
+		Status:            "certificate",
+		Severity:          req.Severity,
+		Tags:              req.Tags,
+		Message:           fmt.Sprintf("The certificate expires within %d days, on %s", threshold, expiry.Format(time.RFC1123)),
+		Timestamp:         time.Now().UTC().UnixMilli(),
+		CertificateExpiry: data.CertificateExpiry,
+		Channels:          channels(req.Notifications),
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("monitor", req.MonitorID).Msg("failed to notify the certificate expiry")
+	}
+}
@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := now.Add(20 * 24 * time.Hour)
+	c := newCertificates([]int{1, 7, 30, 14})
+
+	threshold, ok := c.crossed("1", expiry, now)
+	require.True(t, ok)
+	require.Equal(t, 30, threshold)
+
+	_, ok = c.crossed("1", expiry, now.Add(24*time.Hour))
+	require.False(t, ok, "a threshold should be notified once")
+
+	threshold, ok = c.crossed("1", expiry, now.Add(10*24*time.Hour))
+	require.True(t, ok)
+	require.Equal(t, 14, threshold)
+
+	_, ok = c.crossed("1", expiry.Add(90*24*time.Hour), now.Add(10*24*time.Hour))
+	require.False(t, ok, "a renewed certificate should not be notified")
+
+	threshold, ok = c.crossed("1", expiry.Add(90*24*time.Hour), now.Add(90*24*time.Hour))
+	require.True(t, ok, "a renewed certificate should start over")
+	require.Equal(t, 30, threshold)
+}
+
+func TestPingCertificate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	data, err := Ping(context.Background(), server.Client(), nil, request.CheckerRequest{MonitorID: "1", URL: server.URL, Method: http.MethodGet})
+	require.NoError(t, err)
+	require.Equal(t, server.Certificate().NotAfter.UnixMilli(), data.CertificateExpiry)
+}
@@ -2,28 +2,88 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
 	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/accesslog"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/admin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/aggregate"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/allowlist"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/api"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/cloudtasks"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/config"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/cors"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/debug"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/encrypt"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/export"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fanout"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/group"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/health"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/heartbeat"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/hostlimit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/idempotency"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/incident"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/jsonlog"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/leader"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/libsql"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/logger"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/loki"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/maintenance"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pause"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/policy"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pool"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/queue"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/quorum"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ratelimit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/recovery"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/redact"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/rpc"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sampling"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/statsd"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/store"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/stream"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/updates"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/webhook"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 	"github.com/rs/zerolog/log"
-
-	backoff "github.com/cenkalti/backoff/v4"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
-type statusCode int
-
-func (s statusCode) IsSuccessful() bool {
-	return s >= 200 && s < 300
-}
+const maxBatchSize = 500
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -37,124 +97,1941 @@ func main() {
 		cancel()
 	}()
 
+	// The run command runs a single check, e.g. in CI pipelines.
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		code := runOnce(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		cancel()
+		os.Exit(code)
+	}
+
+	// The configuration is read from the flags, the environment variables
+	// and the config file, in that order of precedence.
+	flags := flag.NewFlagSet("checker", flag.ExitOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path of the YAML, TOML or JSON config file")
+	var sets repeated
+	flags.Var(&sets, "set", `value of a key, e.g. "WORKERS=32", repeatable`)
+	flags.Parse(os.Args[1:])
+
+	overrides := map[string]string{}
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			log.Ctx(ctx).Warn().Str("set", set).Msg("invalid flag, expected KEY=value, ignoring")
+			continue
+		}
+		overrides[strings.TrimSpace(key)] = value
+	}
+	settings = config.New(overrides)
+	if *configFile != "" {
+		if err := settings.Load(*configFile); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to load config")
+		}
+	}
+
 	// environment variables.
 	flyRegion := env("FLY_REGION", "local")
 	cronSecret := env("CRON_SECRET", "")
+	cronSecrets := env("CRON_SECRETS", "")
+	cronSecretsFile := env("CRON_SECRETS_FILE", "")
+	cronSecretsRefresh := env("CRON_SECRETS_REFRESH", "1m")
+	adminSecret := env("ADMIN_SECRET", "")
+	sentryDSN := env("SENTRY_DSN", "")
+	signatureTolerance := env("SIGNATURE_TOLERANCE", "5m")
+	basicAuth := env("BASIC_AUTH", "true") == "true"
+	statusUpdateURL := env("STATUS_UPDATE_URL", checker.APIURL)
+	statusUpdateAuthorization := env("STATUS_UPDATE_AUTHORIZATION", "Basic "+cronSecret)
+	statusUpdateTimeout := env("STATUS_UPDATE_TIMEOUT", "10s")
+	statusUpdaterType := env("STATUS_UPDATER", "api")
+	databaseURL := env("DATABASE_URL", "")
+	databaseAuthToken := env("DATABASE_AUTH_TOKEN", "")
+	statusUpdatesFile := env("STATUS_UPDATES_FILE", "")
+	statusUpdatesRetries := env("STATUS_UPDATES_RETRIES", "10")
+	statusUpdatesMaxBackoff := env("STATUS_UPDATES_MAX_BACKOFF", "5m")
+	statusUpdatesBatch := env("STATUS_UPDATES_BATCH", "1")
+	statusUpdatesLinger := env("STATUS_UPDATES_LINGER", "1s")
+	reportOnly := env("REPORT_ONLY", "false") == "true"
 	tinyBirdToken := env("TINYBIRD_TOKEN", "")
+	tinyBirdURL := env("TINYBIRD_URL", "https://api.tinybird.co/v0/events")
+	tinyBirdDatasources := env("TINYBIRD_DATASOURCES", "ping=ping_response__v5,rollup=ping_rollup__v0,aggregate=ping_aggregate__v0,missed=ping_missed__v0,group=ping_group__v0,heartbeat=ping_heartbeat__v0")
+	tinyBirdWait := env("TINYBIRD_WAIT", "false") == "true"
 	logLevel := env("LOG_LEVEL", "warn")
+	sinkNames := env("SINKS", "tinybird")
+	sinkTimeout := env("SINK_TIMEOUT", sink.DefaultTimeout.String())
+	auditSinks := env("AUDIT_SINKS", "")
+	auditFile := env("AUDIT_FILE", "audit.log")
+	auditDatasource := env("AUDIT_TINYBIRD_DATASOURCE", "checker_audit__v0")
+	webhookURL := env("WEBHOOK_URL", "")
+	webhookAuthorization := env("WEBHOOK_AUTHORIZATION", "")
+	statsdAddr := env("STATSD_ADDR", "127.0.0.1:8125")
+	statsdPrefix := env("STATSD_PREFIX", "openstatus.")
+	lokiURL := env("LOKI_URL", "")
+	lokiUsername := env("LOKI_USERNAME", "")
+	lokiPassword := env("LOKI_PASSWORD", "")
+	sqlitePath := env("SQLITE_PATH", "checker.db")
+	sqliteRetention := env("SQLITE_RETENTION", "24h")
+	encryptedSinks := env("ENCRYPTED_SINKS", "")
+	ageRecipients := env("AGE_RECIPIENTS", "")
+	samplingConfig := env("SAMPLING", "")
+	aggregateWindow := env("AGGREGATE_WINDOW", "100")
+	aggregateInterval := env("AGGREGATE_INTERVAL", "")
+	otlpEndpoint := env("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	metricsPort := env("METRICS_PORT", "")
+	grpcPort := env("GRPC_PORT", "")
+	monitorGroups := env("MONITOR_GROUPS", "{}")
+	redactPatterns := env("REDACT_PATTERNS", "[]")
+	mode := env("MODE", "")
+	monitorsFile := env("MONITORS_FILE", "monitors.json")
+	monitorsRefresh := env("MONITORS_REFRESH", "1m")
+	monitorsURL := env("MONITORS_URL", "")
+	monitorsToken := env("MONITORS_TOKEN", "")
+	monitorsCache := env("MONITORS_CACHE", "monitors.cache.json")
+	schedulerJitter := env("SCHEDULER_JITTER", "0")
+	leaderElection := env("LEADER_ELECTION", "false") == "true"
+	workspaceRateLimits := env("WORKSPACE_RATE_LIMITS", "")
+	rateLimitBucket := env("RATE_LIMIT_BUCKET", "")
+	inboundRateLimits := env("INBOUND_RATE_LIMITS", "")
+	allowedSchemes := env("ALLOWED_SCHEMES", strings.Join(request.DefaultLimits.Schemes, ","))
+	maxHeaders := env("MAX_HEADERS", strconv.Itoa(request.DefaultLimits.MaxHeaders))
+	maxHeaderSize := env("MAX_HEADER_SIZE", strconv.Itoa(request.DefaultLimits.MaxHeaderSize))
+	maxBodySize := env("MAX_BODY_SIZE", strconv.Itoa(request.DefaultLimits.MaxBodySize))
+	maxTimeout := env("MAX_TIMEOUT", request.DefaultLimits.MaxTimeout.String())
+	leaderBucket := env("LEADER_BUCKET", "checker-leader")
+	leaderTTL := env("LEADER_TTL", "15s")
+	leaderID := env("FLY_ALLOC_ID", "")
+	workers := env("WORKERS", "64")
+	hostConcurrency := env("HOST_CONCURRENCY", "4")
+	hostRate := env("HOST_RATE", "10")
+	// The private locations check their own network.
+	ssrfProtection := env("SSRF_PROTECTION", strconv.FormatBool(mode != "agent")) == "true"
+	ssrfAllowedRanges := env("SSRF_ALLOWED_RANGES", "")
+	policyFile := env("POLICY_FILE", "")
+	policyRefresh := env("POLICY_REFRESH", "1m")
+	highPriorityWorkers := env("HIGH_PRIORITY_WORKERS", "8")
+	maxQueueDepth := env("MAX_QUEUE_DEPTH", "256")
+	queueRetryAfter := env("QUEUE_RETRY_AFTER", "10s")
+	shutdownTimeout := env("SHUTDOWN_TIMEOUT", "30s")
+	healthTimeout := env("HEALTH_TIMEOUT", "5s")
+	drainDelay := env("DRAIN_DELAY", "0s")
+	natsURL := env("NATS_URL", "nats://127.0.0.1:4222")
+	natsSubject := env("NATS_SUBJECT", fmt.Sprintf("checker.requests.%s", flyRegion))
+	natsDurable := env("NATS_DURABLE", fmt.Sprintf("checker-%s", flyRegion))
+	natsBatch := env("NATS_BATCH", "10")
+	tlsCertFile := env("TLS_CERT_FILE", "")
+	tlsKeyFile := env("TLS_KEY_FILE", "")
+	tlsClientCAFile := env("TLS_CLIENT_CA_FILE", "")
+	tlsClientNames := env("TLS_CLIENT_NAMES", "")
+	tlsClientCertFile := env("TLS_CLIENT_CERT_FILE", "")
+	tlsClientKeyFile := env("TLS_CLIENT_KEY_FILE", "")
+	tlsCAFile := env("TLS_CA_FILE", "")
+	oidcIssuer := env("OIDC_ISSUER", "https://accounts.google.com")
+	oidcAudience := env("OIDC_AUDIENCE", "")
+	oidcEmails := env("OIDC_EMAILS", "")
+	cloudTasksQueue := env("CLOUD_TASKS_QUEUE", "")
+	cloudTasksTargetURL := env("CLOUD_TASKS_TARGET_URL", "")
+	cloudTasksServiceAccount := env("CLOUD_TASKS_SERVICE_ACCOUNT", "")
+	cloudTasksRetries := env("CLOUD_TASKS_RETRIES", "1")
+	cloudTasksRetryDelay := env("CLOUD_TASKS_RETRY_DELAY", "30s")
+	agentTokens := env("AGENT_TOKENS", "")
+	coordinatorURL := env("COORDINATOR_URL", "")
+	agentID := env("AGENT_ID", "")
+	agentToken := env("AGENT_TOKEN", "")
+	agentLocation := env("AGENT_LOCATION", "")
+	checkerURL := env("CHECKER_URL", "https://openstatus-checker.fly.dev")
+	quorumRegions := env("QUORUM_REGIONS", "")
+	quorumSize := env("QUORUM", "1")
+	verifyRegions := env("VERIFY_REGIONS", "")
+	failureThreshold := env("FAILURE_THRESHOLD", "1")
+	recoveryThreshold := env("RECOVERY_THRESHOLD", "1")
+	severityThresholds := env("SEVERITY_THRESHOLDS", "")
+	certificateThresholds := env("CERTIFICATE_THRESHOLDS", "30,14,7,1")
+	maintenanceFile := env("MAINTENANCE_FILE", "")
+	maintenanceRefresh := env("MAINTENANCE_REFRESH", "1m")
+	notificationsFile := env("NOTIFICATIONS_FILE", "")
+	notificationsRefresh := env("NOTIFICATIONS_REFRESH", "1m")
+	escalationsFile := env("ESCALATIONS_FILE", "")
+	incidents := env("INCIDENTS", "")
+	incidentsURL := env("INCIDENTS_URL", "https://openstatus-api.fly.dev/incidents")
+	incidentAfter := env("INCIDENT_AFTER", "5m")
+	notificationsWindow := env("NOTIFICATIONS_GROUP_WINDOW", "10s")
+	notificationsBucket := env("NOTIFICATIONS_BUCKET", "")
+	heartbeatsFile := env("HEARTBEATS_FILE", "")
+	heartbeatsRefresh := env("HEARTBEATS_REFRESH", "1m")
+	configRefresh := env("CONFIG_REFRESH", "10s")
+	corsOrigins := env("CORS_ORIGINS", "")
+	corsMaxAge := env("CORS_MAX_AGE", "10m")
+	accessLog := env("ACCESS_LOG", "true") == "true"
+	accessLogSample := env("ACCESS_LOG_SAMPLE", "1")
+	accessLogSkip := env("ACCESS_LOG_SKIP", "/ping,/healthz,/readyz")
+	allowedIPs := env("ALLOWED_IPS", "")
+	trustedProxies := env("TRUSTED_PROXIES", "")
+	clientIPHeader := env("CLIENT_IP_HEADER", "")
+	idempotencyTTL := env("IDEMPOTENCY_TTL", "10m")
+	idempotencyBucket := env("IDEMPOTENCY_BUCKET", "")
 
 	logger.Configure(logLevel)
 
-	// packages.
-	httpClient := &http.Client{}
+	// The log level and the configuration files, e.g. the keys or the
+	// notification channels, are reloaded with the config, on SIGHUP or once
+	// the config file is modified. The other values require a restart.
+	settings.OnReload(func() {
+		logger.Configure(env("LOG_LEVEL", "warn"))
+	})
+	reload := func(path string, load func(path string) error) {
+		settings.OnReload(func() {
+			if err := load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("path", path).Msg("failed to reload config")
+			}
+		})
+	}
+
+	// The metrics are served to Prometheus when a metrics port is
+	// configured.
+	var metrics *telemetry.Prometheus
+	var readers []sdkmetric.Reader
+	if metricsPort != "" {
+		metrics = telemetry.NewPrometheus()
+		readers = append(readers, metrics.Reader())
+	}
+
+	// Telemetry is only set up when exported over OTLP, or served to
+	// Prometheus.
+	if otlpEndpoint != "" || len(readers) > 0 {
+		setup := func() (func(context.Context) error, error) {
+			if otlpEndpoint == "" {
+				return telemetry.SetupMetrics("openstatus-checker", flyRegion, readers...)
+			}
+			return telemetry.Setup(ctx, "openstatus-checker", flyRegion, readers...)
+		}
+		shutdown, err := setup()
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to setup telemetry")
+		} else {
+			defer func() {
+				if err := shutdown(context.Background()); err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("failed to shutdown telemetry")
+				}
+			}()
+		}
+	}
+
+	// packages. The calls to the other services, e.g. Tinybird, carry the
+	// trace context and the ID of the request.
+	httpClient := &http.Client{Transport: telemetry.Transport(nil)}
 	defer httpClient.CloseIdleConnections()
 
-	tinybirdClient := tinybird.NewClient(httpClient, tinyBirdToken)
+	// The panics of the requests and of the checks are recovered, so a single
+	// malformed monitor cannot crash the checker, and reported to Sentry when
+	// configured, or to stderr.
+	var panics recovery.Reporter = recovery.NewJSON(os.Stderr)
+	if sentryDSN != "" {
+		sentryClient := &http.Client{Timeout: 5 * time.Second, Transport: telemetry.Transport(nil)}
+		reporter, err := recovery.NewSentry(sentryClient, sentryDSN, flyRegion)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("invalid sentry dsn, reporting the panics to stderr")
+		} else {
+			panics = reporter
+		}
+	}
 
-	router := gin.New()
-	router.POST("/checker", func(c *gin.Context) {
-		ctx := c.Request.Context()
+	tolerance, err := time.ParseDuration(signatureTolerance)
+	if err != nil || tolerance <= 0 {
+		log.Ctx(ctx).Warn().Str("tolerance", signatureTolerance).Msg("invalid signature tolerance, using 5m")
+		tolerance = 5 * time.Minute
+	}
+	// The cron secret signs the outbound requests. The other keys are also
+	// accepted, e.g. the previous secret during a rotation.
+	keys := []auth.Key{{Name: "cron-secret", Secret: cronSecret}}
+	var names []string
+	secrets := keyValues(cronSecrets)
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keys = append(keys, auth.Key{Name: name, Secret: secrets[name]})
+	}
+	// The checker is only ready once its configuration is loaded.
+	readiness := health.NewReadiness()
 
-		if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", cronSecret) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-			return
+	keyring := auth.NewKeyring(keys...)
+	if cronSecretsFile != "" {
+		refresh, err := time.ParseDuration(cronSecretsRefresh)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid cron secrets refresh, using 1m")
+			refresh = time.Minute
 		}
+		go syncConfig(ctx, keyring, cronSecretsFile, refresh, readiness.Pending("cron_secrets"))
+		reload(cronSecretsFile, keyring.Load)
+	}
 
-		var req request.CheckerRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
-			return
+	// The requests are signed with the keys. The Basic secrets are only
+	// accepted during the migration of the callers.
+	authenticators := []auth.Authenticator{auth.NewHMAC(keyring, tolerance)}
+	if basicAuth {
+		authenticators = append(authenticators, auth.NewBasic(keyring))
+	}
+	// ID tokens of the issuer, Google by default, e.g. from Cloud Scheduler
+	// or Cloud Tasks, are accepted when an audience is configured, only for
+	// the allowed service accounts.
+	oidcAudiences := strings.Split(oidcAudience, ",")
+	if oidcAudience != "" {
+		authenticator, err := auth.NewOIDC(ctx, oidcIssuer, oidcAudiences, strings.Split(oidcEmails, ","))
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to setup oidc authentication")
+		} else {
+			authenticators = append(authenticators, authenticator)
 		}
+	}
+	authenticator := auth.Any(authenticators...)
 
-		op := func() error {
-			res, err := checker.Ping(ctx, httpClient, req)
-			if err != nil {
-				return fmt.Errorf("unable to ping: %w", err)
+	// The admin endpoints are authenticated by their own secret, signed or
+	// as Basic, and only served when it is set.
+	var adminAuthenticator auth.Authenticator
+	if adminSecret != "" {
+		admin := auth.NewKeyring(auth.Key{Name: "admin", Secret: adminSecret})
+		adminAuthenticator = auth.Any(auth.NewHMAC(admin, tolerance), auth.NewBasic(admin))
+	}
+
+	// With a certificate, the servers are served over TLS. With a client CA,
+	// the authenticated endpoints, the admin and debugging ones included,
+	// also require a client certificate issued by it, e.g. to the control
+	// plane.
+	var serverTLS *tls.Config
+	if tlsCertFile != "" {
+		serverTLS, err = auth.ServerTLS(tlsCertFile, tlsKeyFile, tlsClientCAFile)
+		if err != nil {
+			// Serving without the configured tls would expose the checker.
+			log.Ctx(ctx).Fatal().Err(err).Msg("failed to setup tls")
+		}
+		if tlsClientCAFile != "" {
+			clientCert := auth.NewClientCert(strings.Split(tlsClientNames, ","))
+			authenticator = auth.All(clientCert, authenticator)
+			if adminAuthenticator != nil {
+				adminAuthenticator = auth.All(clientCert, adminAuthenticator)
 			}
+		}
+	} else if tlsClientCAFile != "" {
+		log.Ctx(ctx).Warn().Msg("client ca without tls certificate, client certificates are not verified")
+	}
 
-			statusCode := statusCode(res.StatusCode)
-			if !statusCode.IsSuccessful() {
-				// Q: Why here we do not check if the status was previously active?
-				checker.UpdateStatus(ctx, checker.UpdateData{
-					MonitorId:  req.MonitorID,
-					Status:     "error",
-					StatusCode: res.StatusCode,
-					Region:     flyRegion,
-				})
-			} else if req.Status == "error" && statusCode.IsSuccessful() {
-				// Q: Why here we check the data before updating the status in this scenario?
-				checker.UpdateStatus(ctx, checker.UpdateData{
-					MonitorId:  req.MonitorID,
-					Status:     "active",
-					Region:     flyRegion,
-					StatusCode: res.StatusCode,
-				})
+	var tasksClient cloudtasks.Client
+	if cloudTasksQueue != "" {
+		tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to find google credentials")
+		} else {
+			tasksClient = cloudtasks.NewClient(oauth2.NewClient(ctx, tokenSource), cloudTasksQueue, cloudTasksTargetURL, cloudTasksServiceAccount, oidcAudiences[0])
+		}
+	}
+	maxRetries, err := strconv.Atoi(cloudTasksRetries)
+	if err != nil {
+		log.Ctx(ctx).Warn().Str("retries", cloudTasksRetries).Msg("invalid cloud tasks retries, using 1")
+		maxRetries = 1
+	}
+	retryDelay, err := time.ParseDuration(cloudTasksRetryDelay)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid cloud tasks retry delay, using 30s")
+		retryDelay = 30 * time.Second
+	}
+
+	var resultStore *store.Store
+
+	sinks := map[string]sink.Sink{}
+	for _, name := range strings.Split(sinkNames, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "tinybird":
+			opts := []tinybird.Option{tinybird.WithBaseURL(tinyBirdURL), tinybird.WithWait(tinyBirdWait)}
+			for eventType, datasource := range keyValues(tinyBirdDatasources) {
+				opts = append(opts, tinybird.WithDatasource(eventType, datasource))
+			}
+			sinks[name] = tinybird.NewClient(httpClient, tinyBirdToken, opts...)
+		case "webhook":
+			headers := map[string]string{}
+			if webhookAuthorization != "" {
+				headers["Authorization"] = webhookAuthorization
+			}
+			sinks[name] = webhook.NewClient(httpClient, webhookURL, headers)
+		case "statsd":
+			client, err := statsd.NewClient(statsdAddr, statsdPrefix)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to create statsd client")
+				continue
 			}
+			sinks[name] = client
+		case "stdout":
+			sinks[name] = jsonlog.NewWriter(os.Stdout)
+		case "loki":
+			sinks[name] = loki.NewClient(httpClient, lokiURL, lokiUsername, lokiPassword, map[string]string{
+				"service": "openstatus-checker",
+				"region":  flyRegion,
+			})
+		case "sqlite":
+			retention, err := time.ParseDuration(sqliteRetention)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("invalid sqlite retention, using 24h")
+				retention = 24 * time.Hour
+			}
+			resultStore, err = store.Open(sqlitePath, retention)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to open sqlite store")
+				continue
+			}
+			defer resultStore.Close()
+			go resultStore.Run(ctx, time.Minute)
+			sinks[name] = resultStore
+		case "":
+		default:
+			log.Ctx(ctx).Warn().Str("sink", name).Msg("unknown sink, ignoring")
+		}
+	}
 
-			if err := tinybirdClient.SendEvent(ctx, res); err != nil {
-				log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	if encryptedSinks != "" {
+		recipients, err := encrypt.ParseRecipients(strings.Split(ageRecipients, ","))
+		for _, name := range strings.Split(encryptedSinks, ",") {
+			name = strings.TrimSpace(name)
+			if _, ok := sinks[name]; !ok {
+				continue
 			}
+			// Never send the results in clear to a sink expecting them encrypted.
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("sink", name).Msg("invalid age recipients, disabling sink")
+				delete(sinks, name)
+				continue
+			}
+			sinks[name] = encrypt.NewSink(sinks[name], recipients)
+		}
+	}
+
+	samplingConfigs := map[string]sampling.Config{}
+	for workspaceID, value := range keyValues(samplingConfig) {
+		config, err := sampling.ParseConfig(value)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("workspace", workspaceID).Msg("invalid sampling config, ignoring")
+			continue
+		}
+		samplingConfigs[workspaceID] = config
+	}
+	// A hung sink gives up the event after the timeout, rather than holding
+	// the check and its worker.
+	sendTimeout, err := time.ParseDuration(sinkTimeout)
+	if err != nil || sendTimeout <= 0 {
+		log.Ctx(ctx).Warn().Str("timeout", sinkTimeout).Msgf("invalid sink timeout, using %s", sink.DefaultTimeout)
+		sendTimeout = sink.DefaultTimeout
+	}
+	sampler := sampling.New(sink.NewFanout(sinks, sink.WithTimeout(sendTimeout)), samplingConfigs)
+	go sampler.Run(ctx, 10*time.Second)
+
+	windowSize, err := strconv.Atoi(aggregateWindow)
+	if err != nil || windowSize < 1 {
+		log.Ctx(ctx).Warn().Str("window", aggregateWindow).Msg("invalid aggregate window, using 100")
+		windowSize = 100
+	}
+	aggregator := aggregate.New(sampler, windowSize)
+	// The aggregates are only emitted when an interval is configured.
+	var emitAggregates bool
+	if aggregateInterval != "" {
+		interval, err := time.ParseDuration(aggregateInterval)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid aggregate interval, not emitting aggregates")
+		} else {
+			emitAggregates = true
+			go aggregator.Run(ctx, interval)
+		}
+	}
+
+	broker := stream.NewBroker(aggregator, 100)
+
+	var patterns []*regexp.Regexp
+	var rawPatterns []string
+	if err := json.Unmarshal([]byte(redactPatterns), &rawPatterns); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid redact patterns, ignoring")
+	}
+	for _, raw := range rawPatterns {
+		p, err := regexp.Compile(raw)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("pattern", raw).Msg("invalid redact pattern, ignoring")
+			continue
+		}
+		patterns = append(patterns, p)
+	}
+
+	// The status of the monitor groups is computed from the results, and
+	// streamed with them.
+	var groups map[string][]string
+	if err := json.Unmarshal([]byte(monitorGroups), &groups); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid monitor groups, ignoring")
+	}
+	tracker := group.New(broker, groups)
 
+	// The sustained failures open incidents, through the API or in the
+	// local store, resolved with the first success.
+	var next sink.Sink = tracker
+	var incidentStore incident.Store
+	var incidentManager *incident.Manager
+	switch incidents {
+	case "api":
+		incidentStore = incident.NewAPIStore(httpClient, incidentsURL, "Basic "+cronSecret)
+	case "sqlite":
+		if resultStore == nil {
+			log.Ctx(ctx).Warn().Msg("sqlite incidents require the sqlite sink, ignoring")
+		} else {
+			incidentStore = resultStore
+		}
+	case "":
+	default:
+		log.Ctx(ctx).Warn().Str("incidents", incidents).Msg("unknown incidents store, ignoring")
+	}
+	if incidentStore != nil {
+		after, err := time.ParseDuration(incidentAfter)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid incident after, using 5m")
+			after = 5 * time.Minute
+		}
+		incidentManager = incident.NewManager(tracker, incidentStore, after)
+		next = incidentManager
+	}
+
+	// The results are redacted before leaving the checker, streams included.
+	redactor := redact.New(patterns)
+	redacted := redact.NewSink(next, redactor)
+
+	// The NATS buckets share a single connection, opened on first use and
+	// drained on shutdown.
+	var natsConn *nats.Conn
+	connectNATS := sync.OnceValues(func() (*nats.Conn, error) {
+		nc, err := nats.Connect(natsURL)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to nats: %w", err)
+		}
+		natsConn = nc
+		return nc, nil
+	})
+
+	// The checks, and the notifications of the channels given by the
+	// requests, only connect to public addresses, unless allowed, and to the
+	// addresses allowed by the policy of their workspace, whatever the
+	// redirects and the DNS answers.
+	var control func(network, address string, c syscall.RawConn) error
+	if ssrfProtection {
+		guard, err := ssrf.New(strings.Split(ssrfAllowedRanges, ","))
+		if err != nil {
+			log.Ctx(ctx).Fatal().Err(err).Msg("invalid ssrf allowed ranges")
+		}
+		control = guard.Control
+	}
+	pingTransport := checker.Transport(control)
+
+	// The status transitions are notified to the channels of the monitors
+	// in their request, or else to the channels of the notifications file,
+	// through the escalation policies of the monitors having one.
+	registry := notify.NewRegistry(httpClient)
+	channels := notify.NewDispatcher(registry, notify.WithTransport(pingTransport))
+	var (
+		notifier  notify.Notifier = channels
+		escalator *notify.Escalator
+	)
+	refresh, err := time.ParseDuration(notificationsRefresh)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid notifications refresh, using 1m")
+		refresh = time.Minute
+	}
+	if notificationsFile != "" {
+		go channels.Run(ctx, notificationsFile, refresh)
+		reload(notificationsFile, channels.Load)
+	}
+	if escalationsFile != "" {
+		escalator = notify.NewEscalator(channels)
+		go escalator.Run(ctx, escalationsFile, refresh, 30*time.Second)
+		reload(escalationsFile, escalator.Load)
+		notifier = escalator
+	}
+
+	// The silenced monitors are not notified, their statuses are still
+	// grouped to summarize the downtime on recovery.
+	silencer := notify.NewSilencer(notifier)
+	notifier = silencer
+
+	// The statuses already notified are skipped, and the regions reporting
+	// the same status are notified together, across the checkers when
+	// shared through NATS.
+	window, err := time.ParseDuration(notificationsWindow)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid notifications group window, using 10s")
+		window = 10 * time.Second
+	}
+	notificationGroups := notify.NewMemoryGroups()
+	if notificationsBucket != "" {
+		var natsGroups notify.Groups
+		nc, err := connectNATS()
+		if err == nil {
+			natsGroups, err = notify.NewNATSGroups(nc, notificationsBucket, 24*time.Hour)
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to open notifications bucket, grouping per instance")
+		} else {
+			notificationGroups = natsGroups
+		}
+	}
+	grouper := notify.NewGrouper(notifier, notificationGroups, window)
+	// The notifications are redacted as the results, before leaving the
+	// checker.
+	notifier = redact.NewNotifier(grouper, redactor)
+
+	// The status of the monitors is written to the database of the main app
+	// when explicitly chosen, rather than through its API, at the cost of the
+	// audit log and the alerts of the API.
+	updateTimeout, err := time.ParseDuration(statusUpdateTimeout)
+	if err != nil || updateTimeout <= 0 {
+		log.Ctx(ctx).Warn().Str("timeout", statusUpdateTimeout).Msg("invalid status update timeout, using 10s")
+		updateTimeout = 10 * time.Second
+	}
+	updateClient := &http.Client{Timeout: updateTimeout, Transport: telemetry.Transport(nil)}
+	var statusUpdater checker.StatusUpdater = checker.NewAPIUpdater(updateClient, statusUpdateURL, statusUpdateAuthorization)
+	switch statusUpdaterType {
+	case "api":
+		if databaseURL != "" {
+			log.Ctx(ctx).Warn().Msg("database url ignored, set STATUS_UPDATER=database to update the status in the database")
+		}
+	case "database":
+		if databaseURL == "" {
+			log.Ctx(ctx).Error().Msg("missing database url, updating the status through the api")
+			break
+		}
+		log.Ctx(ctx).Warn().Msg("status updated in the database, without the audit log and the alerts of the api")
+		statusUpdater = libsql.NewUpdater(updateClient, databaseURL, databaseAuthToken)
+	default:
+		log.Ctx(ctx).Warn().Str("updater", statusUpdaterType).Msg("invalid status updater, using api")
+	}
+	// The updates are retried in the background, independently of the
+	// checks, and persisted across the restarts to the file, if any.
+	updateRetries, err := strconv.Atoi(statusUpdatesRetries)
+	if err != nil || updateRetries < 0 {
+		log.Ctx(ctx).Warn().Str("retries", statusUpdatesRetries).Msg("invalid status updates retries, using 10")
+		updateRetries = 10
+	}
+	updateBackoff, err := time.ParseDuration(statusUpdatesMaxBackoff)
+	if err != nil || updateBackoff < time.Second {
+		log.Ctx(ctx).Warn().Str("backoff", statusUpdatesMaxBackoff).Msg("invalid status updates max backoff, using 5m")
+		updateBackoff = 5 * time.Minute
+	}
+	updateOpts := []updates.Option{updates.WithRetries(updateRetries), updates.WithBackoff(time.Second, updateBackoff), updates.WithTimeout(updateTimeout)}
+	// The changes of a large outage are sent by batches, when enabled.
+	if batch, err := strconv.Atoi(statusUpdatesBatch); err != nil || batch < 1 {
+		log.Ctx(ctx).Warn().Str("batch", statusUpdatesBatch).Msg("invalid status updates batch, sending them one by one")
+	} else if batch > 1 {
+		linger, err := time.ParseDuration(statusUpdatesLinger)
+		if err != nil || linger < 0 {
+			log.Ctx(ctx).Warn().Str("linger", statusUpdatesLinger).Msg("invalid status updates linger, using 1s")
+			linger = time.Second
+		}
+		updateOpts = append(updateOpts, updates.WithBatch(batch, linger))
+	}
+	updateQueue, err := updates.New(statusUpdater, statusUpdatesFile, updateOpts...)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to open status updates file, queueing in memory")
+		updateQueue, _ = updates.New(statusUpdater, "", updateOpts...)
+	}
+	go updateQueue.Run(ctx)
+	statusUpdater = updateQueue
+	// In report only mode, the control plane decides of the status of the
+	// monitors from the results of the checks, which never update it.
+	if reportOnly {
+		statusUpdater = checker.StatusUpdaterFunc(func(context.Context, checker.UpdateData) error {
 			return nil
+		})
+	}
+
+	// The heartbeat monitors are flipped to error when their heartbeat is
+	// late, and back to active with the next one.
+	heartbeats := heartbeat.NewTracker(redacted, statusUpdater, notifier, flyRegion)
+	if heartbeatsFile != "" {
+		refresh, err := time.ParseDuration(heartbeatsRefresh)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid heartbeats refresh, using 1m")
+			refresh = time.Minute
+		}
+		go heartbeats.Run(ctx, heartbeatsFile, refresh, 10*time.Second)
+		reload(heartbeatsFile, heartbeats.Load)
+	}
+
+	// The requests to the other regions are signed with the cron secret.
+	// They present the client certificate, when configured, to the regions
+	// requiring one.
+	var transport http.RoundTripper = http.DefaultTransport
+	if tlsClientCertFile != "" {
+		config, err := auth.ClientTLS(tlsClientCertFile, tlsClientKeyFile, tlsCAFile)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to setup client tls")
+		} else {
+			t := http.DefaultTransport.(*http.Transport).Clone()
+			t.TLSClientConfig = config
+			transport = t
+		}
+	}
+	signingClient := &http.Client{Transport: telemetry.Transport(auth.NewSigner(transport, keyring))}
+	dispatcher := fanout.NewDispatcher(signingClient, checkerURL)
+
+	runnerOpts := []checker.RunnerOption{checker.WithStatusUpdater(statusUpdater)}
+	if reportOnly {
+		runnerOpts = append(runnerOpts, checker.WithReportOnly())
+	}
+	// The checks and the status transitions are recorded, with who triggered
+	// them, to the audit sinks, apart from the results.
+	auditSinkList := map[string]sink.Sink{}
+	for _, name := range strings.Split(auditSinks, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "stdout":
+			auditSinkList[name] = jsonlog.NewWriter(os.Stdout)
+		case "file":
+			f, err := os.OpenFile(auditFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to open audit file")
+				continue
+			}
+			defer f.Close()
+			auditSinkList[name] = jsonlog.NewWriter(f)
+		case "tinybird":
+			auditSinkList[name] = tinybird.NewClient(httpClient, tinyBirdToken, tinybird.WithBaseURL(tinyBirdURL), tinybird.WithDatasource("audit", auditDatasource))
+		case "":
+		default:
+			log.Ctx(ctx).Warn().Str("sink", name).Msg("unknown audit sink, ignoring")
+		}
+	}
+	var auditLog *audit.Log
+	if len(auditSinkList) > 0 {
+		auditLog = audit.New(sink.NewFanout(auditSinkList, sink.WithTimeout(sendTimeout)))
+		runnerOpts = append(runnerOpts, checker.WithAudit(auditLog))
+	}
+	// With a quorum, the failures are confirmed by the other regions before
+	// flipping the monitors to error. Otherwise they can be verified by a
+	// second region.
+	if n, err := strconv.Atoi(quorumSize); err == nil && n > 1 {
+		confirmer := quorum.NewConfirmer(dispatcher, otherRegions(quorumRegions, flyRegion), n, "Basic "+cronSecret)
+		runnerOpts = append(runnerOpts, checker.WithConfirmer(confirmer))
+	} else if verifyRegions != "" {
+		verifier := quorum.NewVerifier(dispatcher, otherRegions(verifyRegions, flyRegion), "Basic "+cronSecret)
+		runnerOpts = append(runnerOpts, checker.WithConfirmer(verifier))
+	}
+
+	failures, err := strconv.Atoi(failureThreshold)
+	if err != nil || failures < 1 {
+		log.Ctx(ctx).Warn().Str("threshold", failureThreshold).Msg("invalid failure threshold, using 1")
+		failures = 1
+	}
+	recoveries, err := strconv.Atoi(recoveryThreshold)
+	if err != nil || recoveries < 1 {
+		log.Ctx(ctx).Warn().Str("threshold", recoveryThreshold).Msg("invalid recovery threshold, using 1")
+		recoveries = 1
+	}
+	runnerOpts = append(runnerOpts, checker.WithThresholds(failures, recoveries))
+	// The thresholds of a severity are "<failures>/<recoveries>".
+	for severity, value := range keyValues(severityThresholds) {
+		failuresValue, recoveriesValue, _ := strings.Cut(value, "/")
+		failures, err := strconv.Atoi(failuresValue)
+		if err != nil || failures < 1 {
+			log.Ctx(ctx).Warn().Str("severity", severity).Str("threshold", value).Msg("invalid severity thresholds, ignoring")
+			continue
+		}
+		recoveries, err := strconv.Atoi(recoveriesValue)
+		if err != nil || recoveries < 1 {
+			log.Ctx(ctx).Warn().Str("severity", severity).Str("threshold", value).Msg("invalid severity thresholds, ignoring")
+			continue
+		}
+		runnerOpts = append(runnerOpts, checker.WithSeverityThresholds(severity, failures, recoveries))
+	}
+	runnerOpts = append(runnerOpts, checker.WithNotifier(notifier))
+
+	// The certificates expiring are notified once per threshold, in days.
+	var days []int
+	for _, value := range strings.Split(certificateThresholds, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		d, err := strconv.Atoi(value)
+		if err != nil || d < 1 {
+			log.Ctx(ctx).Warn().Str("threshold", value).Msg("invalid certificate threshold, ignoring")
+			continue
+		}
+		days = append(days, d)
+	}
+	if len(days) > 0 {
+		runnerOpts = append(runnerOpts, checker.WithCertificateThresholds(days...))
+	}
+
+	// The checks run during a maintenance window are recorded without
+	// updating the status of the monitors.
+	if maintenanceFile != "" {
+		refresh, err := time.ParseDuration(maintenanceRefresh)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid maintenance refresh, using 1m")
+			refresh = time.Minute
+		}
+		calendar := maintenance.NewCalendar()
+		go calendar.Run(ctx, maintenanceFile, refresh)
+		reload(maintenanceFile, calendar.Load)
+		runnerOpts = append(runnerOpts, checker.WithMaintenance(calendar))
+	}
+
+	// The checks of the workspaces are limited per minute, globally when the
+	// counts are shared through NATS.
+	if workspaceRateLimits != "" {
+		limits := map[string]int64{}
+		for workspaceID, value := range keyValues(workspaceRateLimits) {
+			limit, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("workspace", workspaceID).Msg("invalid workspace rate limit, ignoring")
+				continue
+			}
+			limits[workspaceID] = limit
+		}
+
+		counter := ratelimit.NewMemoryCounter()
+		if rateLimitBucket != "" {
+			var natsCounter ratelimit.Counter
+			nc, err := connectNATS()
+			if err == nil {
+				natsCounter, err = ratelimit.NewNATSCounter(nc, rateLimitBucket)
+			}
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to open rate limit bucket, limiting per instance")
+			} else {
+				counter = natsCounter
+			}
+		}
+		runnerOpts = append(runnerOpts, checker.WithLimiter(ratelimit.New(counter, limits)))
+	}
+
+	// The monitors can be paused at the checker level, without changing
+	// their upstream scheduling.
+	pauses := pause.NewRegistry()
+	runnerOpts = append(runnerOpts, checker.WithPauses(pauses))
+
+	// The monitors are pinged with their own client, limiting the load on
+	// the hosts shared by several monitors.
+	concurrency, err := strconv.Atoi(hostConcurrency)
+	if err != nil || concurrency < 0 {
+		log.Ctx(ctx).Warn().Str("concurrency", hostConcurrency).Msg("invalid host concurrency, using 4")
+		concurrency = 4
+	}
+	rps, err := strconv.ParseFloat(hostRate, 64)
+	if err != nil || rps < 0 {
+		log.Ctx(ctx).Warn().Str("rate", hostRate).Msg("invalid host rate, using 10")
+		rps = 10
+	}
+	pingClient := &http.Client{Transport: hostlimit.NewTransport(pingTransport, concurrency, rps)}
+
+	// The targets the workspaces may check are restricted by the rules of
+	// the policy file.
+	var targetPolicy checker.Policy
+	if policyFile != "" {
+		refresh, err := time.ParseDuration(policyRefresh)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid policy refresh, using 1m")
+			refresh = time.Minute
 		}
+		engine := policy.New(nil)
+		go syncConfig(ctx, engine, policyFile, refresh, readiness.Pending("policy"))
+		reload(policyFile, engine.Load)
+		targetPolicy = engine
+		runnerOpts = append(runnerOpts, checker.WithPolicy(engine))
+	}
+	defer pingClient.CloseIdleConnections()
+
+	// The checker requests are validated within the limits before running.
+	limits := request.DefaultLimits
+	limits.Schemes = strings.Split(allowedSchemes, ",")
+	for name, value := range map[string]struct {
+		value string
+		limit *int
+	}{
+		"max headers":     {maxHeaders, &limits.MaxHeaders},
+		"max header size": {maxHeaderSize, &limits.MaxHeaderSize},
+		"max body size":   {maxBodySize, &limits.MaxBodySize},
+	} {
+		limit, err := strconv.Atoi(value.value)
+		if err != nil || limit < 0 {
+			log.Ctx(ctx).Warn().Str("value", value.value).Msgf("invalid %s, using %d", name, *value.limit)
+			continue
+		}
+		*value.limit = limit
+	}
+	if timeout, err := time.ParseDuration(maxTimeout); err != nil || timeout <= 0 {
+		log.Ctx(ctx).Warn().Str("timeout", maxTimeout).Msgf("invalid max timeout, using %s", limits.MaxTimeout)
+	} else {
+		limits.MaxTimeout = timeout
+	}
+	// The channels given by the requests must be valid ones.
+	limits.Channel = func(c request.NotificationChannel) error {
+		return registry.Validate(notify.Channel{Name: c.Name, Type: c.Type, Config: c.Config})
+	}
+	// The checks without timeout run within the max one.
+	runnerOpts = append(runnerOpts, checker.WithDefaultTimeout(limits.MaxTimeout), checker.WithLimits(limits))
+
+	runner := checker.NewRunner(pingClient, redacted, flyRegion, runnerOpts...)
+
+	// The checks triggered by the users run on their own workers, so they
+	// are not delayed by the scheduled ones.
+	normalSize, err := strconv.Atoi(workers)
+	if err != nil || normalSize < 1 {
+		log.Ctx(ctx).Warn().Str("workers", workers).Msg("invalid workers, using 64")
+		normalSize = 64
+	}
+	highSize, err := strconv.Atoi(highPriorityWorkers)
+	if err != nil || highSize < 1 {
+		log.Ctx(ctx).Warn().Str("workers", highPriorityWorkers).Msg("invalid high priority workers, using 8")
+		highSize = 8
+	}
+	lanes := pool.NewLanes(highSize, normalSize, pool.WithRecover(recovery.Recover(panics)))
+	// The results are answered redacted, as sent to the sinks, e.g. over
+	// gRPC.
+	pooledRun := pooled(lanes, runner)
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		return redactor.PingData(pooledRun(ctx, req))
+	}
+
+	// The schedulers and the consumers submit the checks to the workers, the
+	// workers are only stopped once they are.
+	var producers sync.WaitGroup
+	produce := func(fn func()) {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			fn()
+		}()
+	}
+
+	maxDepth, err := strconv.Atoi(maxQueueDepth)
+	if err != nil || maxDepth < 0 {
+		log.Ctx(ctx).Warn().Str("depth", maxQueueDepth).Msg("invalid max queue depth, using 256")
+		maxDepth = 256
+	}
+	retryAfter, err := time.ParseDuration(queueRetryAfter)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid queue retry after, using 10s")
+		retryAfter = 10 * time.Second
+	}
+
+	// The dependencies of the checker are verified by the health endpoints.
+	// A full queue rejects the checks, it takes the checker down.
+	queued := func() int {
+		return lanes.Queued(pool.High) + lanes.Queued(pool.Normal)
+	}
+	// The queue and the sinks are required by the checker.
+	required := []health.Dependency{
+		{Name: "queue", Critical: true, Check: health.Backlog(queued, maxDepth)},
+	}
+	if _, ok := sinks["tinybird"]; ok {
+		required = append(required, health.Dependency{Name: "tinybird", Check: health.HTTP(httpClient, tinyBirdURL)})
+	}
+	if resultStore != nil {
+		required = append(required, health.Dependency{Name: "disk_buffer", Check: resultStore.Ping})
+	}
+	dependencies := append([]health.Dependency{
+		{Name: "status_api", Check: health.HTTP(httpClient, strings.TrimSuffix(statusUpdateURL, "/")+"/updateStatus")},
+	}, required...)
+	checkTimeout, err := time.ParseDuration(healthTimeout)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid health timeout, using 5s")
+		checkTimeout = 5 * time.Second
+	}
+	healthChecker := health.New(checkTimeout, dependencies...)
+
+	jitter, err := strconv.ParseFloat(schedulerJitter, 64)
+	if err != nil || jitter < 0 {
+		log.Ctx(ctx).Warn().Str("jitter", schedulerJitter).Msg("invalid scheduler jitter, using 0")
+		jitter = 0
+	}
+
+	// The monitors are pulled from the API when configured, or read from a
+	// file.
+	monitorSource := scheduler.NewFileSource(monitorsFile)
+	if monitorsURL != "" {
+		monitorSource = scheduler.NewHTTPSource(httpClient, monitorsURL, monitorsToken, monitorsCache)
+	}
 
-		if err := backoff.Retry(op, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3)); err != nil {
-			if err := tinybirdClient.SendEvent(ctx, checker.PingData{
-				URL:           req.URL,
-				Region:        flyRegion,
-				Message:       err.Error(),
-				CronTimestamp: req.CronTimestamp,
-				Timestamp:     req.CronTimestamp,
-				MonitorID:     req.MonitorID,
-				WorkspaceID:   req.WorkspaceID,
+	switch mode {
+	case "standalone":
+		// The checker schedules the monitors itself.
+		refresh, err := time.ParseDuration(monitorsRefresh)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid monitors refresh, using 1m")
+			refresh = time.Minute
+		}
+		opts := []scheduler.Option{scheduler.WithJitter(jitter), scheduler.WithMissed(redacted, flyRegion)}
+		// The checkers of a region elect the one running the monitors.
+		if leaderElection {
+			ttl, err := time.ParseDuration(leaderTTL)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("invalid leader ttl, using 15s")
+				ttl = 15 * time.Second
+			}
+			if leaderID == "" {
+				leaderID, _ = os.Hostname()
+			}
+			var kv leader.KeyValue
+			nc, err := connectNATS()
+			if err == nil {
+				kv, err = leader.NewNATSKeyValue(nc, leaderBucket, ttl)
+			}
+			if err != nil {
+				// Without election, every checker would run the monitors.
+				log.Ctx(ctx).Error().Err(err).Msg("failed to open leader bucket")
+				cancel()
+				break
+			}
+			elector := leader.NewElector(kv, flyRegion, leaderID, ttl/3)
+			go elector.Run(ctx)
+			opts = append(opts, scheduler.WithElector(elector))
+		}
+		produce(func() {
+			scheduler.New(monitorSource, run, refresh, opts...).Run(audit.WithActor(ctx, audit.Actor{Source: "scheduler"}))
+		})
+	case "agent":
+		// The checker runs in a private location, the monitors are assigned
+		// by the coordinator and the results reported back to it.
+		refresh, err := time.ParseDuration(monitorsRefresh)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("invalid monitors refresh, using 1m")
+			refresh = time.Minute
+		}
+		agentClient := agent.NewClient(httpClient, coordinatorURL, agentID, agentToken, agentLocation)
+		if err := agentClient.Register(ctx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to register with the coordinator")
+		}
+		// The coordinator forwards the results, the agent never updates the
+		// status of the monitors itself.
+		agentRunner := checker.NewRunner(pingClient, agentClient, agentLocation, checker.WithReportOnly(), checker.WithDefaultTimeout(limits.MaxTimeout), checker.WithLimits(limits))
+		produce(func() {
+			scheduler.New(agentClient, pooled(lanes, agentRunner), refresh, scheduler.WithJitter(jitter)).Run(ctx)
+		})
+	case "queue":
+		// The checker pulls the requests from a queue, on top of the http ones.
+		batch, err := strconv.Atoi(natsBatch)
+		if err != nil || batch < 1 {
+			log.Ctx(ctx).Warn().Str("batch", natsBatch).Msg("invalid nats batch, using 10")
+			batch = 10
+		}
+		consumer := queue.NewNATSConsumer(natsURL, natsSubject, natsDurable, batch)
+		produce(func() {
+			if err := consumer.Consume(audit.WithActor(ctx, audit.Actor{Source: "queue"}), func(ctx context.Context, req request.CheckerRequest) error {
+				// The misrouted checks are acknowledged without running.
+				if !req.TargetsRegion(flyRegion) {
+					log.Ctx(ctx).Warn().Str("monitor", req.MonitorID).Strs("regions", req.Regions).Msg("check not intended for this region, skipping it")
+					return nil
+				}
+				return lanes.Do(ctx, pool.Priority(req.Priority), func(ctx context.Context) {
+					runner.Run(ctx, req)
+				})
 			}); err != nil {
-				log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+				log.Ctx(ctx).Error().Err(err).Msg("failed to consume queue")
+				cancel()
+			}
+		})
+	}
+
+	// The keys of the checks already run, shared by the checkers of the
+	// region when a bucket is configured.
+	ttl, err := time.ParseDuration(idempotencyTTL)
+	if err != nil || ttl <= 0 {
+		log.Ctx(ctx).Warn().Str("ttl", idempotencyTTL).Msg("invalid idempotency ttl, using 10m")
+		ttl = 10 * time.Minute
+	}
+	idempotencyStore := idempotency.NewMemoryStore(ttl)
+	if idempotencyBucket != "" {
+		// The claims of a checker which crashed during the check are given up
+		// once the check would have timed out on its worker.
+		var natsStore idempotency.Store
+		nc, err := connectNATS()
+		if err == nil {
+			natsStore, err = idempotency.NewNATSStore(nc, idempotencyBucket, ttl, pool.DefaultTimeout)
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to open idempotency bucket, deduplicating per instance")
+		} else {
+			idempotencyStore = natsStore
+		}
+	}
+
+	// check runs a check of the http endpoints on the workers of its lane,
+	// answering its result redacted.
+	check := func(ctx context.Context, req request.CheckerRequest) (checker.PingData, error) {
+		var result checker.PingData
+		err := lanes.Do(ctx, pool.Priority(req.Priority), func(ctx context.Context) {
+			result = redactor.PingData(runner.Run(ctx, req))
+		})
+		if err != nil {
+			return result, err
+		}
+
+		// A failed check is verified again later, through Cloud Tasks.
+		if tasksClient != nil && !result.Paused && !result.Throttled && !result.Inactive && !result.Misrouted && !result.Invalid && (result.StatusCode < 200 || result.StatusCode >= 300) && req.Retry < maxRetries {
+			retry := req
+			retry.Retry++
+			if err := tasksClient.CreateTask(ctx, retry, time.Now().Add(retryDelay)); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to schedule retry check")
+			}
+		}
+
+		return result, nil
+	}
+
+	// busy tells why the checks of the priority are rejected, with its http
+	// status, when too many are already waiting for a worker, for the
+	// callers to back off instead of piling on.
+	busy := func(priority pool.Priority) (int, string) {
+		// The intake is paused by the admins, e.g. during an incident.
+		if lanes.Paused() {
+			return http.StatusServiceUnavailable, "intake paused"
+		}
+		if maxDepth == 0 || lanes.Queued(priority) < maxDepth {
+			return 0, ""
+		}
+
+		return http.StatusTooManyRequests, "too many checks queued"
+	}
+	overloaded := func(c *gin.Context, priority pool.Priority) bool {
+		c.Header("X-Queue-Depth", strconv.Itoa(lanes.Queued(priority)))
+		code, message := busy(priority)
+		if code == 0 {
+			return false
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		c.JSON(code, gin.H{"error": message})
+		return true
+	}
+
+	// The requests of the workspaces are limited per minute, per checker, for
+	// a single workspace not to starve the others.
+	inboundLimits := map[string]int64{}
+	for workspaceID, value := range keyValues(inboundRateLimits) {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("workspace", workspaceID).Msg("invalid inbound rate limit, ignoring")
+			continue
+		}
+		inboundLimits[workspaceID] = limit
+	}
+	inbound := ratelimit.NewInbound(inboundLimits)
+	limited := func(c *gin.Context, workspaceID string) bool {
+		ok, retry := inbound.Allow(workspaceID, time.Now())
+		if ok {
+			return false
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "workspace rate limit exceeded"})
+		return true
+	}
+
+	// repeat runs the next runs of a sub-minute check, until the next cron
+	// tick or the shutdown of the checker, once per monitor and tick.
+	repeater := scheduler.NewRepeater()
+	repeat := func(req request.CheckerRequest, interval time.Duration) {
+		produce(func() {
+			repeater.Repeat(audit.WithActor(ctx, audit.Actor{Source: "scheduler"}), req, interval, run)
+		})
+	}
+
+	var coordinator *agent.Coordinator
+	if agentTokens != "" {
+		coordinator = agent.NewCoordinator(monitorSource, redacted, keyValues(agentTokens))
+	}
+
+	// routes registers the endpoints, on the legacy routes and on the
+	// versioned ones.
+	routes := func(router gin.IRouter) {
+		router.POST("/checker", auth.Middleware(authenticator), func(c *gin.Context) {
+			ctx := c.Request.Context()
+
+			var req request.CheckerRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+				invalidRequest(c, err)
+				return
+			}
+			accesslog.Annotate(c, req.WorkspaceID, req.MonitorID)
+			if err := req.Validate(limits); err != nil {
+				invalidRequest(c, err)
+				return
+			}
+			if !req.TargetsRegion(flyRegion) {
+				c.JSON(http.StatusMisdirectedRequest, gin.H{"error": "wrong region", "region": flyRegion, "regions": req.Regions})
+				return
+			}
+			// The duplicate deliveries, with the same key or the same tick of
+			// the monitor, run once, the others replaying its response.
+			key := c.GetHeader(idempotency.Header)
+			if key == "" && req.CronTimestamp != 0 {
+				key = fmt.Sprintf("%s:%d:%d", req.MonitorID, req.CronTimestamp, req.Retry)
+			}
+			if key != "" {
+				done, ok := idempotency.Begin(c, idempotencyStore, fmt.Sprintf("%s:%s:%s", flyRegion, req.WorkspaceID, key))
+				if !ok {
+					return
+				}
+				defer done()
+			}
+			interval, err := scheduler.SubMinuteInterval(req)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if limited(c, req.WorkspaceID) {
+				return
+			}
+			if overloaded(c, pool.Priority(req.Priority)) {
+				return
+			}
+
+			result, err := check(ctx, req)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to wait for a worker")
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "checker overloaded"})
+				return
 			}
 
-			// If the status was previously active, we update it to error.
-			// Q: Why not always updating the status? My idea is that the checker should be dumb and only check the status and return it.
-			if req.Status == "active" {
-				checker.UpdateStatus(ctx, checker.UpdateData{
-					MonitorId: req.MonitorID,
-					Status:    "error",
-					Message:   err.Error(),
-					Region:    flyRegion,
+			if interval > 0 {
+				repeat(req, interval)
+			}
+
+			c.JSON(http.StatusOK, checkerResponse{Message: "ok", Result: result})
+		})
+
+		// The checks run on demand, e.g. to test a monitor, return their complete
+		// result without updating the status of the monitor nor being recorded.
+		router.POST("/check/run", auth.Middleware(authenticator), func(c *gin.Context) {
+			ctx := c.Request.Context()
+
+			var req request.CheckerRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to decode check request")
+				invalidRequest(c, err)
+				return
+			}
+			accesslog.Annotate(c, req.WorkspaceID, req.MonitorID)
+			if err := req.Validate(limits); err != nil {
+				invalidRequest(c, err)
+				return
+			}
+			if overloaded(c, pool.High) {
+				return
+			}
+
+			if auditLog != nil {
+				auditLog.Record(ctx, audit.Event{
+					Action:      audit.CheckInspected,
+					WorkspaceID: req.WorkspaceID,
+					MonitorID:   req.MonitorID,
+					Region:      flyRegion,
 				})
 			}
+
+			var inspection checker.Inspection
+			err := lanes.Do(ctx, pool.High, func(ctx context.Context) {
+				var err error
+				inspection, err = checker.Inspect(ctx, pingClient, targetPolicy, req)
+				if err != nil {
+					inspection = checker.Inspection{PingData: checker.PingData{
+						URL:         req.URL,
+						Region:      flyRegion,
+						MonitorID:   req.MonitorID,
+						WorkspaceID: req.WorkspaceID,
+						Timestamp:   time.Now().UTC().UnixMilli(),
+						Message:     err.Error(),
+					}}
+				}
+			})
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to wait for a worker")
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "checker overloaded"})
+				return
+			}
+
+			c.JSON(http.StatusOK, inspection)
+		})
+
+		router.POST("/checker/batch", auth.Middleware(authenticator), func(c *gin.Context) {
+			ctx := c.Request.Context()
+
+			var reqs []request.CheckerRequest
+			if err := c.ShouldBindJSON(&reqs); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to decode batch request")
+				invalidRequest(c, err)
+				return
+			}
+			if len(reqs) == 0 || len(reqs) > maxBatchSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a batch holds between 1 and %d checks", maxBatchSize)})
+				return
+			}
+			invalid := &request.ValidationError{}
+			for i, req := range reqs {
+				var validation *request.ValidationError
+				if errors.As(req.Validate(limits), &validation) {
+					invalid.Fields = append(invalid.Fields, validation.Prefix(fmt.Sprintf("[%d].", i)).Fields...)
+				}
+			}
+			if len(invalid.Fields) > 0 {
+				invalidRequest(c, invalid)
+				return
+			}
+			if overloaded(c, pool.Normal) {
+				return
+			}
+
+			// The checks run concurrently, bounded by the workers of their lane.
+			outcomes := make([]batchOutcome, len(reqs))
+			var wg sync.WaitGroup
+			for i, req := range reqs {
+				wg.Add(1)
+				go func(i int, req request.CheckerRequest) {
+					defer wg.Done()
+
+					outcomes[i].MonitorID = req.MonitorID
+					if !req.TargetsRegion(flyRegion) {
+						outcomes[i].Error = "wrong region"
+						return
+					}
+					if ok, _ := inbound.Allow(req.WorkspaceID, time.Now()); !ok {
+						outcomes[i].Error = "workspace rate limit exceeded"
+						return
+					}
+					result, err := check(ctx, req)
+					if err != nil {
+						outcomes[i].Error = err.Error()
+						return
+					}
+					outcomes[i].Result = &result
+				}(i, req)
+			}
+			wg.Wait()
+
+			c.JSON(http.StatusOK, batchResponse{Results: outcomes})
+		})
+
+		router.POST("/checker/fanout", auth.Middleware(authenticator), func(c *gin.Context) {
+			ctx := c.Request.Context()
+
+			var req fanoutRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to decode fanout request")
+				invalidRequest(c, err)
+				return
+			}
+			accesslog.Annotate(c, req.Request.WorkspaceID, req.Request.MonitorID)
+			var validation *request.ValidationError
+			if errors.As(req.Request.Validate(limits), &validation) {
+				invalidRequest(c, validation.Prefix("request."))
+				return
+			}
+
+			// The regional checkers are called with the credentials of the caller.
+			results := dispatcher.Dispatch(ctx, req.Request, req.Regions, c.GetHeader("Authorization"))
+
+			c.JSON(http.StatusOK, fanoutResponse{Results: results})
+		})
+
+		// The results can only be served when they are stored locally.
+		if resultStore != nil {
+			router.GET("/results", auth.Middleware(authenticator), func(c *gin.Context) {
+				ctx := c.Request.Context()
+
+				q := store.Query{MonitorID: c.Query("monitor_id")}
+				if limit := c.Query("limit"); limit != "" {
+					n, err := strconv.Atoi(limit)
+					if err != nil || n < 0 {
+						c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+						return
+					}
+					q.Limit = n
+				}
+
+				results, err := resultStore.Results(ctx, q)
+				if err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("failed to query results")
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+					return
+				}
+
+				c.JSON(http.StatusOK, resultsResponse{Results: results})
+			})
+
+			router.GET("/results/export", auth.Middleware(authenticator), func(c *gin.Context) {
+				ctx := c.Request.Context()
+
+				from, err := parseTime(c.Query("from"))
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+					return
+				}
+				to, err := parseTime(c.Query("to"))
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+					return
+				}
+
+				format := export.Format(c.DefaultQuery("format", string(export.FormatNDJSON)))
+				enc, err := export.NewEncoder(format, c.Writer)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+					return
+				}
+
+				c.Header("Content-Type", format.ContentType())
+				c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=results.%s", format))
+				c.Status(http.StatusOK)
+
+				q := store.Query{MonitorID: c.Query("monitor_id"), From: from, To: to, Limit: -1}
+				if err := resultStore.Each(ctx, q, enc.Encode); err != nil {
+					// The status is already sent, the export is truncated.
+					log.Ctx(ctx).Error().Err(err).Msg("failed to export results")
+					return
+				}
+				if err := enc.Flush(); err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("failed to export results")
+				}
+			})
+
+			router.GET("/incidents", auth.Middleware(authenticator), func(c *gin.Context) {
+				ctx := c.Request.Context()
+
+				incidents, err := resultStore.Incidents(ctx, c.Query("monitor_id"), 0)
+				if err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("failed to query incidents")
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+					return
+				}
+
+				c.JSON(http.StatusOK, incidentsResponse{Incidents: incidents})
+			})
 		}
 
-		c.JSON(http.StatusOK, gin.H{"message": "ok"})
-	})
+		pauses.Register(router.Group("/", auth.Middleware(authenticator)))
+		// The test notifications only connect to the public addresses, as
+		// the checks.
+		channels.Register(router.Group("/", auth.Middleware(authenticator)), pingTransport)
+		if escalator != nil {
+			escalator.Register(router.Group("/", auth.Middleware(authenticator)))
+		}
+		silencer.Register(router.Group("/", auth.Middleware(authenticator)))
 
-	router.GET("/ping", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"message": "pong", "fly_region": flyRegion})
-		return
-	})
+		// The checker coordinates the private locations agents when they have
+		// tokens.
+		if coordinator != nil {
+			coordinator.Register(router)
+		}
+
+		router.GET("/stream", auth.Middleware(authenticator), func(c *gin.Context) {
+			reqCtx := c.Request.Context()
+
+			monitorID, workspaceID := c.Query("monitor_id"), c.Query("workspace_id")
+			events, unsubscribe := broker.Subscribe(func(event any) bool {
+				if monitorID == "" && workspaceID == "" {
+					return true
+				}
+				data, ok := event.(checker.PingData)
+				return ok && (monitorID == "" || data.MonitorID == monitorID) && (workspaceID == "" || data.WorkspaceID == workspaceID)
+			})
+			defer unsubscribe()
+
+			// The streams end with the request, or when the checker shuts down.
+			c.Stream(func(w io.Writer) bool {
+				select {
+				case <-reqCtx.Done():
+					return false
+				case <-ctx.Done():
+					return false
+				case event := <-events:
+					name := "message"
+					if typed, ok := event.(interface{ EventType() string }); ok {
+						name = typed.EventType()
+					}
+					c.SSEvent(name, event)
+					return true
+				}
+			})
+		})
+
+		// The health of the dependencies is only served to the callers, the
+		// load balancers use /ping.
+		router.GET("/health", auth.Middleware(authenticator), func(c *gin.Context) {
+			report := healthChecker.Check(c.Request.Context())
+
+			code := http.StatusOK
+			if report.Status == health.Down {
+				code = http.StatusServiceUnavailable
+			}
+			c.JSON(code, healthResponse{
+				Status:       report.Status,
+				Region:       flyRegion,
+				QueuedChecks: queued(),
+				Dependencies: report.Dependencies,
+			})
+		})
+
+		router.GET("/groups", auth.Middleware(authenticator), func(c *gin.Context) {
+			c.JSON(http.StatusOK, groupsResponse{Groups: tracker.Statuses()})
+		})
+
+		// The heartbeats are authenticated by the token of their monitor.
+		beat := func(c *gin.Context) {
+			if !heartbeats.Beat(c.Request.Context(), c.Param("token")) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "unknown heartbeat"})
+				return
+			}
+			c.JSON(http.StatusOK, api.Message{Message: "ok"})
+		}
+		router.GET("/heartbeat/:token", beat)
+		router.POST("/heartbeat/:token", beat)
+
+		// The orchestrators restart the checker when it is not live, and only
+		// route the traffic to it while ready.
+		router.GET("/healthz", func(c *gin.Context) {
+			c.JSON(http.StatusOK, statusResponse{Status: "ok"})
+		})
+		// The readiness only depends on the local state of the checker, its
+		// configuration loaded and not draining: an unreachable sink would
+		// take every checker out of the rotation at once.
+		router.GET("/readyz", func(c *gin.Context) {
+			if err := readiness.Ready(); err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, statusResponse{Status: "ready"})
+		})
+
+		router.GET("/ping", func(c *gin.Context) {
+			c.JSON(http.StatusOK, pingResponse{Message: "pong", FlyRegion: flyRegion})
+			return
+		})
+	}
+
+	router := gin.New()
+	// The client IP is read from X-Forwarded-For when sent by one of the
+	// trusted proxies, or from the header set by the platform, e.g.
+	// Fly-Client-IP. Without trusted proxies, the header is ignored.
+	if err := allowlist.TrustProxies(router, strings.Split(trustedProxies, ",")); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid trusted proxies, trusting none")
+	}
+	router.TrustedPlatform = clientIPHeader
+	router.Use(telemetry.Middleware())
+	// The requests are logged once answered, the panics included.
+	if accessLog {
+		sample, err := strconv.ParseFloat(accessLogSample, 64)
+		if err != nil || sample < 0 || sample > 1 {
+			log.Ctx(ctx).Warn().Str("sample", accessLogSample).Msg("invalid access log sample, using 1")
+			sample = 1
+		}
+		var skip []string
+		for _, path := range strings.Split(accessLogSkip, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				skip = append(skip, path)
+			}
+		}
+		router.Use(accesslog.Middleware(accesslog.Config{Sample: sample, Skip: skip}))
+	}
+	router.Use(recovery.Middleware(panics))
+	// Only the sources of the allowlist call the checker, when configured. An
+	// invalid allowlist allows no one, and stops the checker.
+	var allowed *allowlist.Allowlist
+	if allowedIPs != "" {
+		allowed, err = allowlist.Parse(strings.Split(allowedIPs, ","))
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("invalid allowed ips")
+			allowed = &allowlist.Allowlist{}
+			cancel()
+		}
+		router.Use(allowlist.Middleware(allowed))
+	}
+	// The browsers of the allowed origins, e.g. the dashboard of a self-hosted
+	// OpenStatus, call the checker directly.
+	if corsOrigins != "" {
+		maxAge, err := time.ParseDuration(corsMaxAge)
+		if err != nil || maxAge < 0 {
+			log.Ctx(ctx).Warn().Str("max_age", corsMaxAge).Msg("invalid cors max age, using 10m")
+			maxAge = 10 * time.Minute
+		}
+		var origins []string
+		for _, origin := range strings.Split(corsOrigins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		router.Use(cors.Middleware(cors.Config{Origins: origins, MaxAge: maxAge}))
+	}
+	router.Use(audit.Middleware())
+	routes(router)
+	// The versioned routes answer the errors in the documented envelope.
+	routes(router.Group(api.Version, api.Envelope()))
+	if adminAuthenticator != nil {
+		adminRouter := router.Group("/", auth.Middleware(adminAuthenticator))
+		debug.Register(adminRouter, func() map[string]any {
+			return map[string]any{
+				"queues": gin.H{
+					"high":     lanes.Queued(pool.High),
+					"normal":   lanes.Queued(pool.Normal),
+					"maxDepth": maxDepth,
+				},
+				"workers": gin.H{
+					"high":   lanes.Workers(pool.High),
+					"normal": lanes.Workers(pool.Normal),
+				},
+				"paused":        lanes.Paused(),
+				"draining":      readiness.Draining(),
+				"statusUpdates": updateQueue.Pending(),
+			}
+		})
+		admin.Register(adminRouter, lanes, func(ctx context.Context) error {
+			if emitAggregates {
+				if err := aggregator.Emit(ctx); err != nil {
+					return err
+				}
+			}
+			return sampler.Flush(ctx, time.Time{})
+		})
+	}
+	// The document describes the routes as registered, so once they all
+	// are.
+	router.GET("/openapi.json", document(router.Routes(), metrics != nil).Handler())
 
 	httpServer := &http.Server{
-		Addr:    fmt.Sprintf("0.0.0.0:%s", env("PORT", "8080")),
-		Handler: router,
+		Addr:      fmt.Sprintf("0.0.0.0:%s", env("PORT", "8080")),
+		Handler:   router,
+		TLSConfig: serverTLS,
 	}
 
 	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if serverTLS != nil {
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to start http server")
 			cancel()
 		}
 	}()
 
+	// The metrics are served on their own port, not exposed publicly.
+	var metricsServer *http.Server
+	if metrics != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		metricsServer = &http.Server{
+			Addr:    fmt.Sprintf("0.0.0.0:%s", metricsPort),
+			Handler: mux,
+		}
+
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to start metrics server")
+			}
+		}()
+	}
+
+	// The gRPC server only runs when a port is configured.
+	var grpcServer *grpc.Server
+	if grpcPort != "" {
+		var opts []grpc.ServerOption
+		if serverTLS != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(serverTLS)))
+		}
+		if allowed != nil {
+			opts = append(opts, allowed.ServerOptions()...)
+		}
+		getHealth := func(reqCtx context.Context) rpc.Health {
+			return rpc.Health{
+				Region:   flyRegion,
+				Queued:   queued(),
+				Draining: readiness.Draining(),
+				Report:   healthChecker.Check(reqCtx),
+			}
+		}
+		// The checks run through the guards of the http endpoints: the
+		// idempotency keys, shared with them, the rate limit of the
+		// workspaces and the backpressure of the queue.
+		guardedRun := func(ctx context.Context, req request.CheckerRequest) (checker.PingData, error) {
+			guarded := func() (idempotency.Response, error) {
+				if ok, retry := inbound.Allow(req.WorkspaceID, time.Now()); !ok {
+					return idempotency.Response{}, status.Errorf(codes.ResourceExhausted, "workspace rate limit exceeded, retry after %ds", int(math.Ceil(retry.Seconds())))
+				}
+				if code, message := busy(pool.Priority(req.Priority)); code == http.StatusServiceUnavailable {
+					return idempotency.Response{}, status.Error(codes.Unavailable, message)
+				} else if code != 0 {
+					return idempotency.Response{}, status.Error(codes.ResourceExhausted, message)
+				}
+
+				var result checker.PingData
+				err := lanes.Do(ctx, pool.Priority(req.Priority), func(ctx context.Context) {
+					result = redactor.PingData(runner.Run(ctx, req))
+				})
+				if err != nil {
+					return idempotency.Response{}, err
+				}
+				body, err := json.Marshal(checkerResponse{Message: "ok", Result: result})
+				if err != nil {
+					return idempotency.Response{}, err
+				}
+				return idempotency.Response{Status: http.StatusOK, ContentType: "application/json; charset=utf-8", Body: body}, nil
+			}
+
+			var (
+				response idempotency.Response
+				err      error
+			)
+			key := rpc.IdempotencyKey(ctx)
+			if key == "" && req.CronTimestamp != 0 {
+				key = fmt.Sprintf("%s:%d:%d", req.MonitorID, req.CronTimestamp, req.Retry)
+			}
+			if key != "" {
+				response, err = idempotency.Run(ctx, idempotencyStore, fmt.Sprintf("%s:%s:%s", flyRegion, req.WorkspaceID, key), guarded)
+			} else {
+				response, err = guarded()
+			}
+			if errors.Is(err, idempotency.ErrInProgress) {
+				return checker.PingData{}, status.Error(codes.Aborted, err.Error())
+			}
+			if err != nil {
+				return checker.PingData{}, err
+			}
+
+			var res checkerResponse
+			if err := json.Unmarshal(response.Body, &res); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to decode the idempotent response")
+				return checker.PingData{}, status.Error(codes.Internal, "internal error")
+			}
+			return res.Result, nil
+		}
+		grpcServer = rpc.NewServer(guardedRun, getHealth, broker, authenticator, opts...)
+
+		go func() {
+			lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", grpcPort))
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to listen for grpc")
+				cancel()
+				return
+			}
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to start grpc server")
+				cancel()
+			}
+		}()
+	}
+
+	configInterval, err := time.ParseDuration(configRefresh)
+	if err != nil || configInterval <= 0 {
+		log.Ctx(ctx).Warn().Str("refresh", configRefresh).Msg("invalid config refresh, using 10s")
+		configInterval = 10 * time.Second
+	}
+	go settings.Run(ctx, *configFile, configInterval)
+
 	<-ctx.Done()
-	if err := httpServer.Shutdown(ctx); err != nil {
+
+	// The checker is no longer ready, and keeps serving until the
+	// orchestrators stop routing the traffic to it.
+	readiness.Drain()
+	if delay, err := time.ParseDuration(drainDelay); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid drain delay, ignoring")
+	} else if delay > 0 {
+		log.Ctx(ctx).Info().Dur("delay", delay).Msg("draining")
+		time.Sleep(delay)
+	}
+
+	// The checker stops accepting checks, stops its schedulers and consumers,
+	// and drains the in-flight checks before flushing the events, in that
+	// order, within the shutdown timeout. The context of the checker is
+	// already done, the shutdown has its own.
+	timeout, err := time.ParseDuration(shutdownTimeout)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("invalid shutdown timeout, using 30s")
+		timeout = 30 * time.Second
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), timeout)
+	defer shutdownCancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to shutdown http server")
-		return
 	}
+	if grpcServer != nil {
+		// The result streams never end by themselves, they are closed with
+		// the server after the timeout.
+		if err := wait(shutdownCtx, grpcServer.GracefulStop); err != nil {
+			grpcServer.Stop()
+		}
+	}
+	// No check can be submitted to the workers once closed, they are only
+	// closed when every producer is stopped.
+	if err := wait(shutdownCtx, producers.Wait); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to stop the schedulers")
+	} else if err := lanes.Drain(shutdownCtx); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to drain in-flight checks")
+	} else {
+		lanes.Close()
+	}
+	if err := wait(shutdownCtx, grouper.Wait); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send the pending notifications")
+	}
+	if incidentManager != nil {
+		if err := wait(shutdownCtx, incidentManager.Wait); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to open or resolve the pending incidents")
+		}
+	}
+	if err := updateQueue.Flush(shutdownCtx); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send the pending status updates")
+	}
+
+	// The aggregates of the last window are emitted before the rollups are
+	// flushed to the sinks.
+	if emitAggregates {
+		if err := aggregator.Emit(shutdownCtx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to emit aggregates")
+		}
+	}
+	if err := sampler.Flush(shutdownCtx, time.Time{}); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to flush rollups")
+	}
+	if natsConn != nil {
+		if err := natsConn.Drain(); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to drain nats connection")
+		}
+	}
+	// The metrics are served until the end of the shutdown.
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to shutdown metrics server")
+		}
+	}
+}
+
+// wait calls fn, returning the error of the context when it is done before
+// fn returns.
+func wait(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reloader is a configuration reloaded from a file.
+type reloader interface {
+	Load(path string) error
+	Run(ctx context.Context, path string, refresh time.Duration)
+}
+
+// syncConfig loads the configuration until it succeeds, calling synced, and
+// then reloads it every refresh until the context is done.
+func syncConfig(ctx context.Context, r reloader, path string, refresh time.Duration, synced func()) {
+	for {
+		err := r.Load(path)
+		if err == nil {
+			break
+		}
+		log.Ctx(ctx).Error().Err(err).Str("path", path).Msg("failed to load config")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(refresh):
+		}
+	}
+	synced()
+
+	r.Run(ctx, path, refresh)
 }
 
+// otherRegions parses a comma separated list of regions, without the local
+// one.
+func otherRegions(value, local string) []string {
+	var regions []string
+	for _, region := range strings.Split(value, ",") {
+		if region = strings.TrimSpace(region); region != "" && region != local {
+			regions = append(regions, region)
+		}
+	}
+
+	return regions
+}
+
+// parseTime parses a unix timestamp in milliseconds or a RFC 3339 time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// pooled runs the checks of the runner on the workers of their lane.
+func pooled(lanes *pool.Lanes, runner checker.Runner) func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+	return func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		var result checker.PingData
+		err := lanes.Do(ctx, pool.Priority(req.Priority), func(ctx context.Context) {
+			result = runner.Run(ctx, req)
+		})
+		// The checks skipped while the intake is paused are not failures.
+		if errors.Is(err, pool.ErrPaused) {
+			return checker.PingData{MonitorID: req.MonitorID, WorkspaceID: req.WorkspaceID, CronTimestamp: req.CronTimestamp, Paused: true}
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("monitor", req.MonitorID).Msg("failed to wait for a worker")
+		}
+
+		return result
+	}
+}
+
+// settings is the configuration read by env, the environment variables
+// only until the flags are parsed.
+var settings = config.New(nil)
+
 func env(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
+	if value, ok := settings.Lookup(key); ok {
 		return value
 	}
 
 	return fallback
 }
+
+// invalidRequest answers the request with its invalid fields, when known.
+func invalidRequest(c *gin.Context, err error) {
+	var (
+		validation *request.ValidationError
+		typeErr    *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &validation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "fields": validation.Fields})
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "fields": []request.FieldError{
+			{Field: typeErr.Field, Message: "must be a " + typeErr.Type.String()},
+		}})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	}
+}
+
+// keyValues parses a comma separated list of key=value pairs.
+func keyValues(value string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return values
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/admin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/api"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fanout"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/group"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/health"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/incident"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/openapi"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pause"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// The bodies of the requests and responses of the routes of the checker,
+// shared by the handlers and the document.
+type (
+	checkerResponse struct {
+		Message string           `json:"message"`
+		Result  checker.PingData `json:"result"`
+	}
+	batchOutcome struct {
+		MonitorID string            `json:"monitorId"`
+		Result    *checker.PingData `json:"result,omitempty"`
+		Error     string            `json:"error,omitempty"`
+	}
+	batchResponse struct {
+		Results []batchOutcome `json:"results"`
+	}
+	fanoutRequest struct {
+		Request request.CheckerRequest `json:"request"`
+		Regions []string               `json:"regions" binding:"required,min=1"`
+	}
+	fanoutResponse struct {
+		Results []fanout.RegionResult `json:"results"`
+	}
+	resultsResponse struct {
+		Results []checker.PingData `json:"results"`
+	}
+	incidentsResponse struct {
+		Incidents []incident.Incident `json:"incidents"`
+	}
+	healthResponse struct {
+		Status       health.Status            `json:"status"`
+		Region       string                   `json:"region"`
+		QueuedChecks int                      `json:"queuedChecks"`
+		Dependencies map[string]health.Result `json:"dependencies"`
+	}
+	groupsResponse struct {
+		Groups []group.Status `json:"groups"`
+	}
+	statusResponse struct {
+		Status string `json:"status"`
+	}
+	pingResponse struct {
+		Message   string `json:"message"`
+		FlyRegion string `json:"fly_region"`
+	}
+	// legacyError is the error of the legacy routes, along with other
+	// fields, e.g. the regions of a misrouted check.
+	legacyError struct {
+		Error string `json:"error"`
+	}
+)
+
+// operations are the documented endpoints, by method and route, without the
+// prefix of the version.
+var operations = map[string]openapi.Operation{
+	"POST /checker": {
+		Summary:  "Run the check of a monitor, updating its status",
+		Request:  request.CheckerRequest{},
+		Response: checkerResponse{},
+	},
+	"POST /check/run": {
+		Summary:  "Run a check on demand, returning its complete result",
+		Request:  request.CheckerRequest{},
+		Response: checker.Inspection{},
+	},
+	"POST /checker/batch": {
+		Summary:  "Run a batch of checks",
+		Request:  []request.CheckerRequest{},
+		Response: batchResponse{},
+	},
+	"POST /checker/fanout": {
+		Summary:  "Run a check from several regions",
+		Request:  fanoutRequest{},
+		Response: fanoutResponse{},
+	},
+	"GET /results": {
+		Summary:  "List the stored results",
+		Response: resultsResponse{},
+	},
+	"GET /results/export": {
+		Summary: "Export the stored results, as NDJSON or CSV",
+	},
+	"GET /incidents": {
+		Summary:  "List the incidents",
+		Response: incidentsResponse{},
+	},
+	"GET /monitors/paused": {
+		Summary:  "List the paused monitors",
+		Response: pause.List{},
+	},
+	"POST /monitors/:id/pause": {
+		Summary:  "Pause a monitor",
+		Request:  pause.Request{},
+		Response: pause.Monitor{},
+	},
+	"POST /monitors/:id/resume": {
+		Summary: "Resume a monitor",
+		Status:  http.StatusNoContent,
+	},
+	"POST /notifications/test": {
+		Summary:  "Send a test notification through a channel, by name or of its own type and config",
+		Request:  notify.TestRequest{},
+		Response: notify.Delivery{},
+	},
+	"GET /silences": {
+		Summary:  "List the active silences",
+		Response: notify.Silences{},
+	},
+	"POST /silences": {
+		Summary:  "Silence the notifications of monitors",
+		Request:  notify.SilenceRequest{},
+		Response: notify.Silence{},
+		Status:   http.StatusCreated,
+	},
+	"DELETE /silences/:id": {
+		Summary: "Expire a silence",
+		Status:  http.StatusNoContent,
+	},
+	"GET /escalations": {
+		Summary:  "List the escalating monitors",
+		Response: notify.Escalations{},
+	},
+	"POST /monitors/:id/acknowledge": {
+		Summary: "Acknowledge the escalation of a monitor",
+		Status:  http.StatusNoContent,
+	},
+	"POST /agents/:id/register": {
+		Summary:  "Register a private location agent",
+		Request:  agent.Registration{},
+		Response: api.Message{},
+	},
+	"GET /agents/:id/monitors": {
+		Summary:  "List the monitors of a private location agent",
+		Response: []scheduler.Monitor{},
+	},
+	"POST /agents/:id/results": {
+		Summary:  "Send the results of a private location agent",
+		Request:  []checker.PingData{},
+		Response: api.Message{},
+	},
+	"GET /stream": {
+		Summary: "Stream the results, as server-sent events",
+	},
+	"GET /health": {
+		Summary:  "Verify the dependencies of the checker",
+		Response: healthResponse{},
+	},
+	"GET /groups": {
+		Summary:  "List the statuses of the monitor groups",
+		Response: groupsResponse{},
+	},
+	"GET /heartbeat/:token": {
+		Summary:  "Record a heartbeat",
+		Response: api.Message{},
+		Public:   true,
+	},
+	"POST /heartbeat/:token": {
+		Summary:  "Record a heartbeat",
+		Response: api.Message{},
+		Public:   true,
+	},
+	"GET /healthz": {
+		Summary:  "Check the checker is live",
+		Response: statusResponse{},
+		Public:   true,
+	},
+	"GET /readyz": {
+		Summary:  "Check the checker is ready to receive traffic",
+		Response: statusResponse{},
+		Public:   true,
+	},
+	"GET /ping": {
+		Summary:  "Check the checker is up",
+		Response: pingResponse{},
+		Public:   true,
+	},
+	"GET /openapi.json": {
+		Summary: "Describe the API of the checker",
+		Public:  true,
+	},
+	"GET /metrics": {
+		Summary: "Serve the metrics in the Prometheus format, on METRICS_PORT",
+		Public:  true,
+	},
+	"GET /admin/log-level": {
+		Summary:  "Get the log level",
+		Response: admin.LogLevel{},
+		Admin:    true,
+	},
+	"PUT /admin/log-level": {
+		Summary:  "Change the log level",
+		Request:  admin.LogLevel{},
+		Response: admin.LogLevel{},
+		Admin:    true,
+	},
+	"GET /admin/intake": {
+		Summary:  "Get the intake of the checks",
+		Response: admin.Intake{},
+		Admin:    true,
+	},
+	"POST /admin/intake/pause": {
+		Summary:  "Pause the intake of the checks",
+		Response: admin.Intake{},
+		Admin:    true,
+	},
+	"POST /admin/intake/resume": {
+		Summary:  "Resume the intake of the checks",
+		Response: admin.Intake{},
+		Admin:    true,
+	},
+	"GET /admin/workers": {
+		Summary:  "Get the workers of the lanes",
+		Response: admin.Workers{},
+		Admin:    true,
+	},
+	"PUT /admin/workers": {
+		Summary:  "Resize the lanes",
+		Request:  admin.Workers{},
+		Response: admin.Workers{},
+		Admin:    true,
+	},
+	"POST /admin/flush": {
+		Summary: "Flush the buffered events to the sinks",
+		Status:  http.StatusNoContent,
+		Admin:   true,
+	},
+	"GET /debug/state": {
+		Summary:  "Dump the state of the checker",
+		Response: map[string]any{},
+		Admin:    true,
+	},
+	"GET /debug/pprof/": {
+		Summary: "List the profiles",
+		Admin:   true,
+	},
+	"GET /debug/pprof/:name": {
+		Summary: "Serve a profile, e.g. goroutine or heap",
+		Admin:   true,
+	},
+	"GET /debug/pprof/cmdline": {
+		Summary: "Serve the command line of the checker",
+		Admin:   true,
+	},
+	"GET /debug/pprof/profile": {
+		Summary: "Serve a CPU profile",
+		Admin:   true,
+	},
+	"GET /debug/pprof/symbol": {
+		Summary: "Look up the program counters",
+		Admin:   true,
+	},
+	"POST /debug/pprof/symbol": {
+		Summary: "Look up the program counters",
+		Admin:   true,
+	},
+	"GET /debug/pprof/trace": {
+		Summary: "Serve an execution trace",
+		Admin:   true,
+	},
+}
+
+// document returns the document of the routes, the versioned and the
+// legacy ones, once they are all registered, and of the metrics when served
+// on their own port.
+func document(routes gin.RoutesInfo, metrics bool) *openapi.Document {
+	doc := openapi.New("OpenStatus Checker", "1.0.0", struct {
+		Error api.Error `json:"error"`
+	}{})
+	for _, route := range routes {
+		path, versioned := strings.CutPrefix(route.Path, api.Version)
+		// The endpoints without documentation are still listed.
+		op := operations[route.Method+" "+path]
+		// The legacy routes keep their errors, out of the envelope.
+		if !versioned {
+			op.Error = legacyError{}
+		}
+		doc.Add(route.Method, route.Path, op)
+	}
+	doc.Add(http.MethodGet, "/openapi.json", operations["GET /openapi.json"])
+	if metrics {
+		doc.Add(http.MethodGet, "/metrics", operations["GET /metrics"])
+	}
+
+	return doc
+}
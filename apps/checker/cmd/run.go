@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// The exit codes of the run command.
+const (
+	exitPassed = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+// repeated is a flag which can be repeated.
+type repeated []string
+
+func (r *repeated) String() string {
+	return strings.Join(*r, ", ")
+}
+
+func (r *repeated) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+// runOnce runs a single check from the command line, printing its complete
+// result, and returns the exit code: 0 when the check passed, 1 when it
+// failed and 2 when it could not run.
+func runOnce(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	url := flags.String("url", "", "URL of the monitor")
+	method := flags.String("method", http.MethodGet, "HTTP method")
+	body := flags.String("body", "", "body of the request")
+	timeout := flags.Duration("timeout", 45*time.Second, "timeout of the request")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	var headers, assertions repeated
+	flags.Var(&headers, "header", `header of the request, e.g. "Authorization: Bearer token", repeatable`)
+	flags.Var(&assertions, "assert", `assertion, e.g. "status eq 200" or "header:Content-Type contains json", repeatable`)
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *url == "" {
+		fmt.Fprintln(stderr, "missing --url")
+		return exitError
+	}
+
+	req := request.CheckerRequest{
+		MonitorID: "cli",
+		URL:       *url,
+		Method:    strings.ToUpper(*method),
+		Body:      *body,
+	}
+	for _, header := range headers {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok {
+			fmt.Fprintf(stderr, "invalid header %q, expected \"Key: Value\"\n", header)
+			return exitError
+		}
+		req.Headers = append(req.Headers, struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	for _, value := range assertions {
+		a, err := parseAssertion(value)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		req.Assertions = append(req.Assertions, a)
+	}
+
+	inspection, err := checker.Inspect(ctx, &http.Client{Timeout: *timeout}, nil, req)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(inspection); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	} else {
+		printInspection(stdout, inspection)
+	}
+
+	if !inspection.Passed {
+		return exitFailed
+	}
+
+	return exitPassed
+}
+
+// parseAssertion parses an assertion written "type compare [target]", the
+// header assertions naming their header as "header:Name".
+func parseAssertion(value string) (request.Assertion, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return request.Assertion{}, fmt.Errorf("invalid assertion %q, expected \"type compare [target]\"", value)
+	}
+
+	a := request.Assertion{
+		Type:    fields[0],
+		Compare: fields[1],
+		Target:  strings.Join(fields[2:], " "),
+	}
+	if name, key, ok := strings.Cut(a.Type, ":"); ok {
+		a.Type, a.Key = name, key
+	}
+	if a.Type == "header" && a.Key == "" {
+		return request.Assertion{}, fmt.Errorf("invalid assertion %q, expected \"header:Name compare [target]\"", value)
+	}
+
+	return a, nil
+}
+
+func printInspection(w io.Writer, inspection checker.Inspection) {
+	fmt.Fprintf(w, "%s %d in %d ms\n", inspection.URL, inspection.StatusCode, inspection.Timing.Total)
+	fmt.Fprintf(w, "  dns %d ms, connect %d ms, tls %d ms, first byte %d ms, transfer %d ms\n",
+		inspection.Timing.DNS, inspection.Timing.Connect, inspection.Timing.TLS, inspection.Timing.FirstByte, inspection.Timing.Transfer)
+
+	for _, result := range inspection.Assertions {
+		outcome := "PASS"
+		if !result.Passed {
+			outcome = "FAIL"
+		}
+		subject := result.Type
+		if result.Key != "" {
+			subject += ":" + result.Key
+		}
+		fmt.Fprintf(w, "%s %s %s %s (actual %q)", outcome, subject, result.Compare, result.Target, excerpt(result.Actual))
+		if result.Error != "" {
+			fmt.Fprintf(w, ": %s", result.Error)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if inspection.Passed {
+		fmt.Fprintln(w, "passed")
+	} else {
+		fmt.Fprintln(w, "failed")
+	}
+}
+
+// excerpt shortens the bodies in the assertion outcomes.
+func excerpt(value string) string {
+	const size = 80
+	if len(value) <= size {
+		return value
+	}
+
+	runes := []rune(value)
+	if len(runes) <= size {
+		return value
+	}
+	return string(runes[:size]) + "..."
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnce(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"it should pass without assertions on a 2xx", []string{"--url", server.URL}, exitPassed},
+		{"it should pass the passing assertions", []string{"--url", server.URL, "--assert", "status eq 200", "--assert", "header:Content-Type contains json"}, exitPassed},
+		{"it should fail the failing assertions", []string{"--url", server.URL, "--assert", "body contains error"}, exitFailed},
+		{"it should reject a missing url", []string{"--assert", "status eq 200"}, exitError},
+		{"it should reject an invalid assertion", []string{"--url", server.URL, "--assert", "header contains json"}, exitError},
+		{"it should report unreachable monitors", []string{"--url", "http://127.0.0.1:0"}, exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runOnce(context.Background(), tt.args, &stdout, &stderr)
+			require.Equal(t, tt.code, code, "stdout: %s, stderr: %s", stdout.String(), stderr.String())
+		})
+	}
+}
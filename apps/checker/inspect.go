@@ -0,0 +1,162 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertion"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+const (
+	// maxBodySize is the part of the body read for the assertions.
+	maxBodySize = 1 << 20
+	// bodyExcerptSize is the part of the body returned in the inspection.
+	bodyExcerptSize = 1 << 10
+)
+
+// Timing is the duration of each phase of the request, in milliseconds.
+type Timing struct {
+	DNS       int64 `json:"dns"`
+	Connect   int64 `json:"connect"`
+	TLS       int64 `json:"tls"`
+	FirstByte int64 `json:"firstByte"`
+	Transfer  int64 `json:"transfer"`
+	Total     int64 `json:"total"`
+}
+
+// Inspection is the complete result of a check, for the users testing their
+// monitors.
+type Inspection struct {
+	PingData
+	Timing     Timing             `json:"timing"`
+	Headers    map[string]string  `json:"headers,omitempty"`
+	Body       string             `json:"body,omitempty"`
+	Truncated  bool               `json:"truncated,omitempty"`
+	Assertions []assertion.Result `json:"assertions,omitempty"`
+	Passed     bool               `json:"passed"`
+}
+
+// Inspect runs the check once, recording the timings of the request and
+// evaluating the assertions of the request against the response. The target
+// is verified against the policy, when given.
+func Inspect(ctx context.Context, client *http.Client, policy Policy, inputData request.CheckerRequest) (Inspection, error) {
+	ctx, timeout := withTimeout(ctx, inputData)
+	defer timeout.stop()
+
+	req, err := newRequest(ctx, inputData)
+	if err != nil {
+		return Inspection{}, err
+	}
+	client, req, err = enforce(ctx, client, policy, req, inputData.WorkspaceID)
+	if err != nil {
+		return Inspection{}, fmt.Errorf("error with monitorURL %s: %w", inputData.URL, err)
+	}
+
+	// The timings and the timeout start with the first connection, as for
+	// Ping.
+	var connecting bool
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, firstByte time.Time
+	start := time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GetConn: func(string) {
+			if !connecting {
+				connecting = true
+				start = time.Now()
+				timeout.start()
+			}
+		},
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}))
+
+	response, err := client.Do(req)
+	if err != nil {
+		return Inspection{}, fmt.Errorf("error with monitorURL %s: %w", inputData.URL, err)
+	}
+	defer response.Body.Close()
+	latency := time.Since(start).Milliseconds()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	if err != nil {
+		return Inspection{}, fmt.Errorf("unable to read body: %w", err)
+	}
+	end := time.Now()
+
+	inspection := Inspection{
+		PingData: PingData{
+			Latency:       latency,
+			StatusCode:    response.StatusCode,
+			MonitorID:     inputData.MonitorID,
+			Region:        os.Getenv("FLY_REGION"),
+			WorkspaceID:   inputData.WorkspaceID,
+			Timestamp:     time.Now().UTC().UnixMilli(),
+			CronTimestamp: inputData.CronTimestamp,
+			URL:           inputData.URL,
+		},
+		Timing: Timing{
+			DNS:       between(dnsStart, dnsDone),
+			Connect:   between(connectStart, connectDone),
+			TLS:       between(tlsStart, tlsDone),
+			FirstByte: between(start, firstByte),
+			Transfer:  between(firstByte, end),
+			Total:     between(start, end),
+		},
+		Headers: map[string]string{},
+		Body:    excerpt(body),
+	}
+	inspection.Truncated = len(inspection.Body) < len(body)
+	for key := range response.Header {
+		inspection.Headers[key] = response.Header.Get(key)
+	}
+
+	inspection.Assertions = assertion.Evaluate(inputData.Assertions, assertion.Response{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       string(body),
+		Latency:    latency,
+	})
+	// With assertions, the status code is only checked when asserted.
+	inspection.Passed = statusCode(response.StatusCode).IsSuccessful()
+	if len(inputData.Assertions) > 0 {
+		inspection.Passed = assertion.Passed(inspection.Assertions)
+	}
+
+	return inspection, nil
+}
+
+// between returns the milliseconds between two times, 0 when one is missing,
+// e.g. without DNS lookup on a reused connection.
+func between(from, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+
+	return to.Sub(from).Milliseconds()
+}
+
+// excerpt returns the beginning of the body, cut on a rune boundary.
+func excerpt(body []byte) string {
+	if len(body) <= bodyExcerptSize {
+		return string(body)
+	}
+
+	cut := bodyExcerptSize
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+
+	return string(body[:cut])
+}
@@ -0,0 +1,48 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("a", 2*bodyExcerptSize)))
+	}))
+	defer server.Close()
+
+	t.Run("it should return the complete result", func(t *testing.T) {
+		inspection, err := Inspect(context.Background(), server.Client(), nil, request.CheckerRequest{MonitorID: "1", URL: server.URL})
+		require.NoError(t, err)
+		require.Equal(t, 200, inspection.StatusCode)
+		require.Equal(t, "text/plain", inspection.Headers["Content-Type"])
+		require.Len(t, inspection.Body, bodyExcerptSize)
+		require.True(t, inspection.Truncated)
+		require.GreaterOrEqual(t, inspection.Timing.Total, inspection.Timing.FirstByte)
+		require.True(t, inspection.Passed)
+	})
+
+	t.Run("it should evaluate the assertions", func(t *testing.T) {
+		inspection, err := Inspect(context.Background(), server.Client(), nil, request.CheckerRequest{
+			URL: server.URL,
+			Assertions: []request.Assertion{
+				{Type: "status", Compare: "eq", Target: "200"},
+				{Type: "header", Key: "Content-Type", Compare: "contains", Target: "json"},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, inspection.Assertions, 2)
+		require.True(t, inspection.Assertions[0].Passed)
+		require.False(t, inspection.Assertions[1].Passed)
+		require.False(t, inspection.Passed)
+	})
+}
@@ -3,15 +3,35 @@ package checker
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/netip"
 	"net/url"
 	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertion"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/policy"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var pingLatency, _ = telemetry.Meter().Int64Histogram("checker.ping.latency",
+	metric.WithUnit("ms"),
+	metric.WithDescription("Latency of the pinged monitors."),
 )
 
 type PingData struct {
@@ -24,31 +44,231 @@ type PingData struct {
 	URL           string `json:"url"`
 	Region        string `json:"region"`
 	Message       string `json:"message,omitempty"`
+	// Maintenance is set when the check ran during a maintenance window.
+	Maintenance bool `json:"maintenance,omitempty"`
+	// Paused is set when the monitor is paused at the checker level.
+	Paused bool `json:"paused,omitempty"`
+	// Throttled is set when the check was not run, over the rate limit of
+	// its workspace.
+	Throttled bool `json:"throttled,omitempty"`
+	// Suppressed is set on the failures of a monitor whose dependency is
+	// down, which do not update its status.
+	Suppressed bool `json:"suppressed,omitempty"`
+	// Inactive is set when the check happened outside the active hours of
+	// the monitor.
+	Inactive bool `json:"inactive,omitempty"`
+	// Misrouted is set when the check was not run, not intended for the
+	// region of the checker.
+	Misrouted bool `json:"misrouted,omitempty"`
+	// Invalid is set when the check was not run, its request being invalid.
+	Invalid bool `json:"invalid,omitempty"`
+	// CertificateExpiry is the expiry of the certificate of the monitor,
+	// in milliseconds, for the HTTPS monitors.
+	CertificateExpiry int64 `json:"certificateExpiry,omitempty"`
+	// Degraded is set on the successful checks slower than the
+	// degradedAfter of the monitor or failing one of its degraded
+	// assertions, the message telling which.
+	Degraded bool `json:"degraded,omitempty"`
+	// EventID identifies the result, e.g. in the status update it
+	// triggered.
+	EventID string `json:"eventId,omitempty"`
+
+	// timing and failed, the degraded assertions failed, explain the status
+	// updates, without being sent to the sinks.
+	timing *Timing
+	failed []assertion.Result
+}
+
+func (PingData) EventType() string {
+	return "ping"
+}
+
+// ErrForbidden is the error of the checks of a target the policy does not
+// allow to the workspace.
+var ErrForbidden = errors.New("target forbidden")
+
+// Policy restricts the targets the workspaces may check.
+type Policy interface {
+	// Check verifies the target before dialing, wrapping policy.ErrDenied
+	// when denied.
+	Check(ctx context.Context, workspaceID string, target *url.URL) error
+	// CheckAddr verifies the address connected to for the host, once
+	// resolved.
+	CheckAddr(workspaceID, host string, addr netip.AddrPort) error
+}
+
+// forbidden wraps the errors of the targets denied by the policy with
+// ErrForbidden, the others, e.g. a resolve failure, failing the check.
+func forbidden(err error) error {
+	if errors.Is(err, policy.ErrDenied) {
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	}
+
+	return err
+}
+
+// enforcement is the policy of the connections of a check, and the host
+// they are made for, the one of the last redirect.
+type enforcement struct {
+	policy      Policy
+	workspaceID string
+
+	mu   sync.Mutex
+	host string
+}
+
+type enforcementKey struct{}
+
+// Control verifies the address of each connection of a check, once
+// resolved, against the policy of its workspace, as the SSRF guard does. It
+// is the control of the dialer of Transport.
+func Control(ctx context.Context, network, address string, _ syscall.RawConn) error {
+	e, ok := ctx.Value(enforcementKey{}).(*enforcement)
+	if !ok {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unable to parse address %q: %w", address, err)
+	}
+
+	e.mu.Lock()
+	host := e.host
+	e.mu.Unlock()
+	if err := e.policy.CheckAddr(e.workspaceID, host, addrPort); err != nil {
+		return forbidden(err)
+	}
+
+	return nil
 }
 
-func Ping(ctx context.Context, client *http.Client, inputData request.CheckerRequest) (PingData, error) {
+// Transport returns the transport of the checks, verifying their connections
+// with Control, then with the control, if any, e.g. the one of the SSRF
+// guard. It never goes through a proxy, which would connect in their place.
+func Transport(control func(network, address string, c syscall.RawConn) error) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		ControlContext: func(ctx context.Context, network, address string, c syscall.RawConn) error {
+			if err := Control(ctx, network, address, c); err != nil {
+				return err
+			}
+			if control != nil {
+				return control(network, address, c)
+			}
+			return nil
+		},
+	}).DialContext
+
+	return t
+}
+
+// enforce verifies the target of the request against the policy, and
+// returns the client of the check verifying its redirects, before dialing,
+// and the request, verifying its connections once dialed with Transport.
+// The connections are never reused, so that each check dials its own.
+func enforce(ctx context.Context, client *http.Client, policy Policy, req *http.Request, workspaceID string) (*http.Client, *http.Request, error) {
+	if policy == nil {
+		return client, req, nil
+	}
+	if err := policy.Check(ctx, workspaceID, req.URL); err != nil {
+		return nil, nil, forbidden(err)
+	}
+
+	e := &enforcement{policy: policy, workspaceID: workspaceID, host: req.URL.Hostname()}
+	req = req.WithContext(context.WithValue(req.Context(), enforcementKey{}, e))
+	req.Close = true
+
+	enforced := *client
+	enforced.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if err := policy.Check(next.Context(), workspaceID, next.URL); err != nil {
+			return forbidden(err)
+		}
+		e.mu.Lock()
+		e.host = next.URL.Hostname()
+		e.mu.Unlock()
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(next, via)
+		}
+		// The default policy of the clients.
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+
+	return &enforced, req, nil
+}
+
+// Ping checks the monitor of the request, if the policy, when given, allows
+// its workspace to check the target.
+func Ping(ctx context.Context, client *http.Client, policy Policy, inputData request.CheckerRequest) (PingData, error) {
 	logger := log.Ctx(ctx).With().Str("monitor", inputData.URL).Logger()
 
+	ctx, span := telemetry.Tracer().Start(ctx, "checker.Ping", trace.WithAttributes(
+		attribute.String("monitor.id", inputData.MonitorID),
+		attribute.String("workspace.id", inputData.WorkspaceID),
+		attribute.String("http.url", inputData.URL),
+		attribute.String("http.method", inputData.Method),
+	))
+	defer span.End()
+
+	ctx, timeout := withTimeout(ctx, inputData)
+	defer timeout.stop()
+
 	region := os.Getenv("FLY_REGION")
-	req, err := http.NewRequestWithContext(ctx, inputData.Method, inputData.URL, bytes.NewReader([]byte(inputData.Body)))
+	req, err := newRequest(ctx, inputData)
 	if err != nil {
 		logger.Error().Err(err).Msg("error while creating req")
-		return PingData{}, fmt.Errorf("unable to create req: %w", err)
+		span.SetStatus(codes.Error, err.Error())
+		return PingData{}, err
 	}
-
-	req.Header.Set("User-Agent", "OpenStatus/1.0")
-	for _, header := range inputData.Headers {
-		if header.Key != "" && header.Value != "" {
-			req.Header.Set(header.Key, header.Value)
-		}
+	client, req, err = enforce(ctx, client, policy, req, inputData.WorkspaceID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("target not checked")
+		span.SetStatus(codes.Error, err.Error())
+		return PingData{}, fmt.Errorf("error with monitorURL %s: %w", inputData.URL, err)
 	}
 
+	// The latency and the timeout start with the first connection, after the
+	// time spent waiting for the limits of the client.
 	start := time.Now()
+	var connecting bool
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, firstByte time.Time
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GetConn: func(string) {
+			if !connecting {
+				connecting = true
+				start = time.Now()
+				timeout.start()
+			}
+		},
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}))
 	response, err := client.Do(req)
-	latency := time.Since(start).Milliseconds()
+	end := time.Now()
+	latency := end.Sub(start).Milliseconds()
+	// The body is not read, without transfer phase.
+	timing := &Timing{
+		DNS:       between(dnsStart, dnsDone),
+		Connect:   between(connectStart, connectDone),
+		TLS:       between(tlsStart, tlsDone),
+		FirstByte: between(start, firstByte),
+		Total:     latency,
+	}
 	if err != nil {
 		var urlErr *url.Error
-		if errors.As(err, &urlErr) && urlErr.Timeout() {
+		if errors.As(err, &urlErr) && urlErr.Timeout() || timeout.expired.Load() {
+			span.SetStatus(codes.Error, "timeout")
+			pingLatency.Record(ctx, latency, metric.WithAttributes(attribute.Bool("timeout", true)))
 			return PingData{
 				Latency:     latency,
 				MonitorID:   inputData.MonitorID,
@@ -57,22 +277,150 @@ func Ping(ctx context.Context, client *http.Client, inputData request.CheckerReq
 				Timestamp:   time.Now().UTC().UnixMilli(),
 				URL:         inputData.URL,
 				Message:     fmt.Sprintf("Timeout after %d ms", latency),
+				timing:      timing,
 			}, nil
 		}
 
 		logger.Error().Err(err).Msg("error while pinging")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return PingData{}, fmt.Errorf("error with monitorURL %s: %w", inputData.URL, err)
 	}
 	defer response.Body.Close()
 
-	return PingData{
-		Latency:       latency,
-		StatusCode:    response.StatusCode,
-		MonitorID:     inputData.MonitorID,
-		Region:        region,
-		WorkspaceID:   inputData.WorkspaceID,
-		Timestamp:     time.Now().UTC().UnixMilli(),
-		CronTimestamp: inputData.CronTimestamp,
-		URL:           inputData.URL,
-	}, nil
+	span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
+	pingLatency.Record(ctx, latency, metric.WithAttributes(attribute.Int("status_code", response.StatusCode)))
+
+	var certificateExpiry int64
+	if response.TLS != nil && len(response.TLS.PeerCertificates) > 0 {
+		certificateExpiry = response.TLS.PeerCertificates[0].NotAfter.UnixMilli()
+	}
+
+	data := PingData{
+		CertificateExpiry: certificateExpiry,
+		Latency:           latency,
+		StatusCode:        response.StatusCode,
+		MonitorID:         inputData.MonitorID,
+		Region:            region,
+		WorkspaceID:       inputData.WorkspaceID,
+		Timestamp:         time.Now().UTC().UnixMilli(),
+		CronTimestamp:     inputData.CronTimestamp,
+		URL:               inputData.URL,
+		timing:            timing,
+	}
+	if statusCode(response.StatusCode).IsSuccessful() {
+		data.degrade(ctx, inputData, response)
+	}
+
+	return data, nil
+}
+
+// degrade marks the successful check as degraded, and why, when slower than
+// the degradedAfter of the monitor or failing one of its degraded
+// assertions.
+func (data *PingData) degrade(ctx context.Context, inputData request.CheckerRequest, response *http.Response) {
+	latency := data.Latency
+	if inputData.DegradedAfter > 0 && latency > inputData.DegradedAfter {
+		data.Degraded = true
+		data.Message = fmt.Sprintf("Latency of %d ms above %d ms", latency, inputData.DegradedAfter)
+		return
+	}
+
+	var assertions []request.Assertion
+	for _, a := range inputData.Assertions {
+		if a.Degraded {
+			assertions = append(assertions, a)
+		}
+	}
+	if len(assertions) == 0 {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("monitor", inputData.MonitorID).Msg("unable to read the body of the assertions")
+	}
+	for _, result := range assertion.Evaluate(assertions, assertion.Response{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       string(body),
+		Latency:    latency,
+	}) {
+		if !result.Passed {
+			// Only the beginning of the body explains the update.
+			result.Actual = excerpt([]byte(result.Actual))
+			data.failed = append(data.failed, result)
+		}
+	}
+	if len(data.failed) > 0 {
+		result := data.failed[0]
+		subject := result.Type
+		if result.Key != "" {
+			subject += " " + result.Key
+		}
+		message := fmt.Sprintf("Assertion failed: %s %s %q", subject, result.Compare, result.Target)
+		// The bodies are too long for a message.
+		if result.Type != "body" {
+			message += fmt.Sprintf(", got %q", result.Actual)
+		}
+		data.Degraded = true
+		data.Message = message
+	}
+}
+
+// timeout cancels the context of a check once its timeout elapsed since its
+// start, rather than since the check was created, not to count the time
+// spent waiting for the limits of the client.
+type timeout struct {
+	duration time.Duration
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	// expired tells whether the timeout canceled the check.
+	expired atomic.Bool
+}
+
+// withTimeout returns the context of the check, bounded by its timeout when
+// set, once started.
+func withTimeout(ctx context.Context, inputData request.CheckerRequest) (context.Context, *timeout) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, &timeout{
+		duration: time.Duration(inputData.Timeout) * time.Millisecond,
+		cancel:   cancel,
+	}
+}
+
+// start starts the timeout, when set and not started yet.
+func (t *timeout) start() {
+	if t.duration <= 0 || t.timer != nil {
+		return
+	}
+	t.timer = time.AfterFunc(t.duration, func() {
+		t.expired.Store(true)
+		t.cancel()
+	})
+}
+
+// stop stops the timeout and cancels the context of the check.
+func (t *timeout) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.cancel()
+}
+
+// newRequest returns the request of the check.
+func newRequest(ctx context.Context, inputData request.CheckerRequest) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, inputData.Method, inputData.URL, bytes.NewReader([]byte(inputData.Body)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create req: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "OpenStatus/1.0")
+	for _, header := range inputData.Headers {
+		if header.Key != "" && header.Value != "" {
+			req.Header.Set(header.Key, header.Value)
+		}
+	}
+
+	return req, nil
 }
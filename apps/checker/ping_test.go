@@ -34,7 +34,7 @@ func Test_ping(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := Ping(context.Background(), tt.args.client, tt.args.inputData)
+			got, err := Ping(context.Background(), tt.args.client, nil, tt.args.inputData)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Ping() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,76 @@
+package accesslog
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// The keys of the monitor of the request, set by the handlers.
+const (
+	workspaceKey = "accesslog.workspace"
+	monitorKey   = "accesslog.monitor"
+)
+
+// Config controls the requests logged.
+type Config struct {
+	// Sample is the ratio of the successful requests logged, from 0 to 1. The
+	// failed ones, with a 4xx or 5xx status, are always logged.
+	Sample float64
+	// Skip are the paths never logged, e.g. the probes of the orchestrators.
+	Skip []string
+}
+
+// Annotate adds the workspace and the monitor of the request to its access
+// log, once decoded by the handler.
+func Annotate(c *gin.Context, workspaceID, monitorID string) {
+	c.Set(workspaceKey, workspaceID)
+	c.Set(monitorKey, monitorID)
+}
+
+// Middleware logs the requests once answered, with the logger of their
+// context. The access logs have no level, they are emitted whatever the log
+// level is.
+func Middleware(config Config) gin.HandlerFunc {
+	skip := make(map[string]bool, len(config.Skip))
+	for _, path := range config.Skip {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && rand.Float64() >= config.Sample {
+			return
+		}
+
+		event := log.Ctx(c.Request.Context()).Log().
+			Str("type", "access").
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("route", c.FullPath()).
+			Int("status", status).
+			Dur("duration", time.Since(start)).
+			Str("ip", c.ClientIP())
+		if size := c.Writer.Size(); size > 0 {
+			event = event.Int("bytes", size)
+		}
+		if workspaceID := c.GetString(workspaceKey); workspaceID != "" {
+			event = event.Str("workspace_id", workspaceID)
+		}
+		if monitorID := c.GetString(monitorKey); monitorID != "" {
+			event = event.Str("monitor_id", monitorID)
+		}
+		event.Msg("request")
+	}
+}
@@ -0,0 +1,78 @@
+package accesslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/accesslog"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	router := func(config accesslog.Config) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+		}, accesslog.Middleware(config))
+		router.POST("/checker", func(c *gin.Context) {
+			accesslog.Annotate(c, "1", "42")
+			c.String(http.StatusOK, "ok")
+		})
+		router.GET("/ping", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.GET("/fail", func(c *gin.Context) {
+			c.Status(http.StatusInternalServerError)
+		})
+		return router
+	}
+	serve := func(router *gin.Engine, method, path string) []map[string]any {
+		buf.Reset()
+		r, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+
+		var lines []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			lines = append(lines, entry)
+		}
+		return lines
+	}
+
+	t.Run("it should log the request with its monitor", func(t *testing.T) {
+		lines := serve(router(accesslog.Config{Sample: 1}), http.MethodPost, "/checker")
+		require.Len(t, lines, 1)
+		require.Equal(t, "access", lines[0]["type"])
+		require.Equal(t, "POST", lines[0]["method"])
+		require.Equal(t, "/checker", lines[0]["path"])
+		require.Equal(t, float64(200), lines[0]["status"])
+		require.Equal(t, float64(2), lines[0]["bytes"])
+		require.Equal(t, "1", lines[0]["workspace_id"])
+		require.Equal(t, "42", lines[0]["monitor_id"])
+		require.Contains(t, lines[0], "duration")
+	})
+
+	t.Run("it should sample the successful requests only", func(t *testing.T) {
+		r := router(accesslog.Config{Sample: 0})
+		require.Empty(t, serve(r, http.MethodPost, "/checker"))
+		require.Len(t, serve(r, http.MethodGet, "/fail"), 1)
+	})
+
+	t.Run("it should skip the paths", func(t *testing.T) {
+		require.Empty(t, serve(router(accesslog.Config{Sample: 1, Skip: []string{"/ping"}}), http.MethodGet, "/ping"))
+	})
+}
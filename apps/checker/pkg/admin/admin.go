@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pool"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Flush sends the buffered events, e.g. the rollups, to the sinks.
+type Flush func(ctx context.Context) error
+
+// LogLevel is the body of the log level endpoints.
+type LogLevel struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// Workers is the body of the workers endpoints, by lane.
+type Workers struct {
+	High   int `json:"high"`
+	Normal int `json:"normal"`
+}
+
+// Intake is the body of the intake endpoints.
+type Intake struct {
+	Paused bool `json:"paused"`
+}
+
+// Register adds the endpoints changing the checker at runtime, under
+// /admin: the log level, the intake of the checks, the workers of the lanes
+// and the flush of the buffered events. The router must be authenticated.
+func Register(router gin.IRouter, lanes *pool.Lanes, flush Flush) {
+	admin := router.Group("/admin")
+
+	admin.GET("/log-level", func(c *gin.Context) {
+		c.JSON(http.StatusOK, LogLevel{Level: zerolog.GlobalLevel().String()})
+	})
+	admin.PUT("/log-level", func(c *gin.Context) {
+		var req LogLevel
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		level, err := zerolog.ParseLevel(req.Level)
+		if err != nil || level == zerolog.NoLevel {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log level"})
+			return
+		}
+
+		zerolog.SetGlobalLevel(level)
+		log.Ctx(c.Request.Context()).Warn().Str("level", level.String()).Msg("log level changed")
+		c.JSON(http.StatusOK, LogLevel{Level: level.String()})
+	})
+
+	// The paused checker rejects the new checks, the queued and running ones
+	// still being run.
+	admin.GET("/intake", func(c *gin.Context) {
+		c.JSON(http.StatusOK, Intake{Paused: lanes.Paused()})
+	})
+	admin.POST("/intake/pause", func(c *gin.Context) {
+		lanes.Pause()
+		log.Ctx(c.Request.Context()).Warn().Msg("intake paused")
+		c.JSON(http.StatusOK, Intake{Paused: true})
+	})
+	admin.POST("/intake/resume", func(c *gin.Context) {
+		lanes.Resume()
+		log.Ctx(c.Request.Context()).Warn().Msg("intake resumed")
+		c.JSON(http.StatusOK, Intake{Paused: false})
+	})
+
+	// The lanes missing from the request keep their workers.
+	admin.GET("/workers", func(c *gin.Context) {
+		c.JSON(http.StatusOK, Workers{High: lanes.Workers(pool.High), Normal: lanes.Workers(pool.Normal)})
+	})
+	admin.PUT("/workers", func(c *gin.Context) {
+		var req Workers
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.High < 0 || req.Normal < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid number of workers"})
+			return
+		}
+
+		if req.High > 0 {
+			lanes.Resize(pool.High, req.High)
+		}
+		if req.Normal > 0 {
+			lanes.Resize(pool.Normal, req.Normal)
+		}
+		res := Workers{High: lanes.Workers(pool.High), Normal: lanes.Workers(pool.Normal)}
+		log.Ctx(c.Request.Context()).Warn().Int("high", res.High).Int("normal", res.Normal).Msg("workers resized")
+		c.JSON(http.StatusOK, res)
+	})
+
+	admin.POST("/flush", func(c *gin.Context) {
+		if err := flush(c.Request.Context()); err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to flush events")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to flush events"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,73 @@
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/admin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	lanes := pool.NewLanes(1, 2)
+	defer lanes.Close()
+
+	var flushes int
+	var flushErr error
+	router := gin.New()
+	admin.Register(router, lanes, func(ctx context.Context) error {
+		flushes++
+		return flushErr
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("it should change the log level", func(t *testing.T) {
+		defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+		w := serve(http.MethodPut, "/admin/log-level", `{"level": "debug"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+		require.JSONEq(t, `{"level": "debug"}`, serve(http.MethodGet, "/admin/log-level", "").Body.String())
+
+		require.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/admin/log-level", `{"level": "loud"}`).Code)
+	})
+
+	t.Run("it should pause and resume the intake", func(t *testing.T) {
+		require.JSONEq(t, `{"paused": true}`, serve(http.MethodPost, "/admin/intake/pause", "").Body.String())
+		require.True(t, lanes.Paused())
+		require.JSONEq(t, `{"paused": true}`, serve(http.MethodGet, "/admin/intake", "").Body.String())
+
+		require.JSONEq(t, `{"paused": false}`, serve(http.MethodPost, "/admin/intake/resume", "").Body.String())
+		require.False(t, lanes.Paused())
+	})
+
+	t.Run("it should resize the workers of the lanes", func(t *testing.T) {
+		w := serve(http.MethodPut, "/admin/workers", `{"normal": 4}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"high": 1, "normal": 4}`, w.Body.String())
+
+		require.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/admin/workers", `{"high": -1}`).Code)
+	})
+
+	t.Run("it should flush the events", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/admin/flush", "").Code)
+		require.Equal(t, 1, flushes)
+
+		flushErr = errors.New("sink unavailable")
+		require.Equal(t, http.StatusInternalServerError, serve(http.MethodPost, "/admin/flush", "").Code)
+	})
+}
@@ -0,0 +1,86 @@
+package agent_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource []scheduler.Monitor
+
+func (s staticSource) Monitors(ctx context.Context) ([]scheduler.Monitor, error) {
+	return s, nil
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestAgent(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+
+	source := staticSource{
+		{CheckerRequest: request.CheckerRequest{MonitorID: "1"}, Location: "office"},
+		{CheckerRequest: request.CheckerRequest{MonitorID: "2"}, Location: "datacenter"},
+		{CheckerRequest: request.CheckerRequest{MonitorID: "3"}},
+	}
+	r := &recorder{}
+	coordinator := agent.NewCoordinator(source, r, map[string]string{"agent-1": "token"})
+
+	router := gin.New()
+	coordinator.Register(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("it should reject unknown agents", func(t *testing.T) {
+		client := agent.NewClient(server.Client(), server.URL, "agent-1", "wrong", "office")
+		require.Error(t, client.Register(ctx))
+	})
+
+	t.Run("it should return the monitors of the agent location", func(t *testing.T) {
+		client := agent.NewClient(server.Client(), server.URL, "agent-1", "token", "office")
+
+		// The agent registers itself on its first fetch.
+		monitors, err := client.Monitors(ctx)
+		require.NoError(t, err)
+		require.Len(t, monitors, 1)
+		require.Equal(t, "1", monitors[0].MonitorID)
+	})
+
+	t.Run("it should forward the results tagged with the location", func(t *testing.T) {
+		client := agent.NewClient(server.Client(), server.URL, "agent-1", "token", "office")
+
+		require.NoError(t, client.SendEvent(ctx, checker.PingData{MonitorID: "1", Region: "local"}))
+		require.Len(t, r.events, 1)
+		require.Equal(t, "office", r.events[0].(checker.PingData).Region)
+	})
+
+	t.Run("it should reject the results of the monitors of other locations", func(t *testing.T) {
+		client := agent.NewClient(server.Client(), server.URL, "agent-1", "token", "office")
+
+		require.Error(t, client.SendEvent(ctx, checker.PingData{MonitorID: "2", Region: "local"}))
+		require.Error(t, client.SendEvent(ctx, checker.PingData{MonitorID: "3", Region: "local"}))
+		require.Len(t, r.events, 1)
+	})
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/rs/zerolog/log"
+)
+
+// Client is the agent side of the coordinator: it is the source of the
+// monitors of the agent and the sink of their results.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	id         string
+	token      string
+	location   string
+}
+
+func NewClient(httpClient *http.Client, coordinatorURL, id, token, location string) Client {
+	return Client{
+		httpClient: httpClient,
+		baseURL:    fmt.Sprintf("%s/agents/%s", coordinatorURL, id),
+		id:         id,
+		token:      token,
+		location:   location,
+	}
+}
+
+// Register registers the agent and its location with the coordinator.
+func (c Client) Register(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/register", Registration{Location: c.location}, nil)
+}
+
+// Monitors returns the monitors assigned to the agent. The agent registers
+// again when the coordinator lost track of it, e.g. after a restart.
+func (c Client) Monitors(ctx context.Context) ([]scheduler.Monitor, error) {
+	var monitors []scheduler.Monitor
+	err := c.do(ctx, http.MethodGet, "/monitors", nil, &monitors)
+	if errors.Is(err, errNotRegistered) {
+		if err := c.Register(ctx); err != nil {
+			return nil, err
+		}
+		err = c.do(ctx, http.MethodGet, "/monitors", nil, &monitors)
+	}
+
+	return monitors, err
+}
+
+// SendEvent reports a check result to the coordinator.
+func (c Client) SendEvent(ctx context.Context, event any) error {
+	data, ok := event.(checker.PingData)
+	if !ok {
+		return nil
+	}
+
+	return c.do(ctx, http.MethodPost, "/results", []checker.PingData{data}, nil)
+}
+
+var errNotRegistered = errors.New("agent not registered")
+
+func (c Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return fmt.Errorf("unable to encode payload: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload.Bytes()))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to send request")
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotRegistered
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Ctx(ctx).Error().Str("status", resp.Status).Msg("unexpected status code")
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("unable to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/api"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/rs/zerolog/log"
+)
+
+// Registration is the body of the registration of an agent.
+type Registration struct {
+	Location string `json:"location" binding:"required"`
+}
+
+type agentState struct {
+	location string
+	lastSeen time.Time
+}
+
+// Coordinator assigns the monitors of private locations to the agents
+// running there, and forwards the results they report to the sink.
+type Coordinator struct {
+	source scheduler.Source
+	sink   sink.Sink
+	tokens map[string]string
+
+	mu     sync.Mutex
+	agents map[string]*agentState
+}
+
+// NewCoordinator returns a coordinator assigning the monitors of the source
+// to the agents, authenticated by their own token.
+func NewCoordinator(source scheduler.Source, eventSink sink.Sink, tokens map[string]string) *Coordinator {
+	return &Coordinator{
+		source: source,
+		sink:   eventSink,
+		tokens: tokens,
+		agents: map[string]*agentState{},
+	}
+}
+
+// Register adds the coordinator endpoints to the router.
+func (c *Coordinator) Register(router gin.IRouter) {
+	agents := router.Group("/agents/:id", c.authenticate)
+	agents.POST("/register", c.register)
+	agents.GET("/monitors", c.monitors)
+	agents.POST("/results", c.results)
+}
+
+func (c *Coordinator) authenticate(ctx *gin.Context) {
+	token, ok := c.tokens[ctx.Param("id")]
+	given, _ := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(given)) != 1 {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	ctx.Next()
+}
+
+func (c *Coordinator) register(ctx *gin.Context) {
+	var req Registration
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	c.mu.Lock()
+	c.agents[ctx.Param("id")] = &agentState{location: req.Location, lastSeen: time.Now()}
+	c.mu.Unlock()
+
+	log.Ctx(ctx.Request.Context()).Info().Str("agent", ctx.Param("id")).Str("location", req.Location).Msg("agent registered")
+	ctx.JSON(http.StatusOK, api.Message{Message: "ok"})
+}
+
+func (c *Coordinator) agent(id string) (*agentState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.agents[id]
+	if ok {
+		state.lastSeen = time.Now()
+	}
+
+	return state, ok
+}
+
+func (c *Coordinator) monitors(ctx *gin.Context) {
+	state, ok := c.agent(ctx.Param("id"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "agent not registered"})
+		return
+	}
+
+	assigned, err := c.assigned(ctx.Request.Context(), state.location)
+	if err != nil {
+		log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("failed to load monitors")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, assigned)
+}
+
+// assigned returns the monitors of the location.
+func (c *Coordinator) assigned(ctx context.Context, location string) ([]scheduler.Monitor, error) {
+	monitors, err := c.source.Monitors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := []scheduler.Monitor{}
+	for _, monitor := range monitors {
+		if monitor.Location == location {
+			assigned = append(assigned, monitor)
+		}
+	}
+
+	return assigned, nil
+}
+
+func (c *Coordinator) results(ctx *gin.Context) {
+	state, ok := c.agent(ctx.Param("id"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "agent not registered"})
+		return
+	}
+
+	var results []checker.PingData
+	if err := ctx.ShouldBindJSON(&results); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	// An agent only reports the results of the monitors of its location.
+	assigned, err := c.assigned(ctx.Request.Context(), state.location)
+	if err != nil {
+		log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("failed to load monitors")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	monitors := make(map[string]bool, len(assigned))
+	for _, monitor := range assigned {
+		monitors[monitor.MonitorID] = true
+	}
+	for _, result := range results {
+		if !monitors[result.MonitorID] {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "monitor not assigned to the agent"})
+			return
+		}
+	}
+
+	for _, result := range results {
+		// The region of a private location is its name.
+		result.Region = state.location
+		if err := c.sink.SendEvent(ctx.Request.Context(), result); err != nil {
+			log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("failed to send event")
+		}
+	}
+
+	ctx.JSON(http.StatusOK, api.Message{Message: "ok"})
+}
@@ -0,0 +1,185 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+)
+
+// Stats are the aggregates of the last checks of a monitor.
+type Stats struct {
+	WorkspaceID  string  `json:"workspaceId"`
+	MonitorID    string  `json:"monitorId"`
+	URL          string  `json:"url"`
+	Region       string  `json:"region"`
+	Timestamp    int64   `json:"timestamp"`
+	Count        int     `json:"count"`
+	SuccessRatio float64 `json:"successRatio"`
+	P50          int64   `json:"p50"`
+	P95          int64   `json:"p95"`
+	P99          int64   `json:"p99"`
+}
+
+func (Stats) EventType() string {
+	return "aggregate"
+}
+
+type sample struct {
+	latency int64
+	success bool
+}
+
+// window is a ring buffer of the last samples of a monitor.
+type window struct {
+	last    checker.PingData
+	samples []sample
+	next    int
+	full    bool
+}
+
+func (w *window) add(s sample) {
+	w.samples[w.next] = s
+	w.next = (w.next + 1) % len(w.samples)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+func (w *window) stats() Stats {
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+
+	latencies := make([]int64, 0, n)
+	successes := 0
+	for _, s := range w.samples[:n] {
+		latencies = append(latencies, s.latency)
+		if s.success {
+			successes++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	return Stats{
+		WorkspaceID:  w.last.WorkspaceID,
+		MonitorID:    w.last.MonitorID,
+		URL:          w.last.URL,
+		Region:       w.last.Region,
+		Timestamp:    time.Now().UTC().UnixMilli(),
+		Count:        n,
+		SuccessRatio: float64(successes) / float64(n),
+		P50:          percentile(latencies, 50),
+		P95:          percentile(latencies, 95),
+		P99:          percentile(latencies, 99),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// Aggregator is a sink keeping rolling aggregates over the last checks of
+// each monitor before forwarding the results to the next sink.
+type Aggregator struct {
+	next sink.Sink
+	size int
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// New returns an Aggregator keeping the last size checks of each monitor.
+func New(next sink.Sink, size int) *Aggregator {
+	return &Aggregator{
+		next:    next,
+		size:    size,
+		windows: map[string]*window{},
+	}
+}
+
+func (a *Aggregator) SendEvent(ctx context.Context, event any) error {
+	if data, ok := event.(checker.PingData); ok {
+		a.add(data)
+	}
+
+	return a.next.SendEvent(ctx, event)
+}
+
+func (a *Aggregator) add(data checker.PingData) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.windows[data.MonitorID]
+	if !ok {
+		w = &window{samples: make([]sample, a.size)}
+		a.windows[data.MonitorID] = w
+	}
+	w.last = data
+	w.add(sample{
+		latency: data.Latency,
+		success: data.StatusCode >= 200 && data.StatusCode < 300,
+	})
+}
+
+// Get returns the current aggregates of a monitor.
+func (a *Aggregator) Get(monitorID string) (Stats, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.windows[monitorID]
+	if !ok {
+		return Stats{}, false
+	}
+
+	return w.stats(), true
+}
+
+// Emit sends the aggregates of every monitor to the next sink.
+func (a *Aggregator) Emit(ctx context.Context) error {
+	a.mu.Lock()
+	stats := make([]Stats, 0, len(a.windows))
+	for _, w := range a.windows {
+		stats = append(stats, w.stats())
+	}
+	a.mu.Unlock()
+
+	var errs []error
+	for _, s := range stats {
+		if err := a.next.SendEvent(ctx, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run periodically emits the aggregates until the context is done.
+func (a *Aggregator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = a.Emit(ctx)
+		}
+	}
+}
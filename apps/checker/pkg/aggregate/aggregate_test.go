@@ -0,0 +1,64 @@
+package aggregate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/aggregate"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestAggregator(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("it should compute the aggregates over the last checks", func(t *testing.T) {
+		r := &recorder{}
+		a := aggregate.New(r, 100)
+
+		// 150 checks, only the last 100 (latency 51..150) are kept.
+		for i := 1; i <= 150; i++ {
+			statusCode := 200
+			if i%10 == 0 {
+				statusCode = 500
+			}
+			require.NoError(t, a.SendEvent(ctx, checker.PingData{MonitorID: "1", Latency: int64(i), StatusCode: statusCode}))
+		}
+		require.Len(t, r.events, 150)
+
+		stats, ok := a.Get("1")
+		require.True(t, ok)
+		require.Equal(t, 100, stats.Count)
+		require.Equal(t, 0.9, stats.SuccessRatio)
+		require.Equal(t, int64(100), stats.P50)
+		require.Equal(t, int64(145), stats.P95)
+		require.Equal(t, int64(149), stats.P99)
+	})
+
+	t.Run("it should emit the aggregates of every monitor", func(t *testing.T) {
+		r := &recorder{}
+		a := aggregate.New(r, 10)
+
+		require.NoError(t, a.SendEvent(ctx, checker.PingData{MonitorID: "1", StatusCode: 200}))
+		require.NoError(t, a.SendEvent(ctx, checker.PingData{MonitorID: "2", StatusCode: 200}))
+
+		require.NoError(t, a.Emit(ctx))
+		require.Len(t, r.events, 4)
+		require.IsType(t, aggregate.Stats{}, r.events[2])
+
+		_, ok := a.Get("3")
+		require.False(t, ok)
+	})
+}
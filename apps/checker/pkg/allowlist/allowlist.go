@@ -0,0 +1,132 @@
+package allowlist
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Allowlist is the source networks allowed to call the checker.
+type Allowlist struct {
+	networks []*net.IPNet
+}
+
+// Parse returns the allowlist of the CIDRs, e.g. "10.0.0.0/8", or addresses,
+// e.g. "203.0.113.7".
+func Parse(cidrs []string) (*Allowlist, error) {
+	a := &Allowlist{}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", cidr)
+			}
+			cidr = ip.String() + "/32"
+			if ip.To4() == nil {
+				cidr = ip.String() + "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse cidr: %w", err)
+		}
+		a.networks = append(a.networks, network)
+	}
+
+	return a, nil
+}
+
+// Allows tells whether the address is in one of the networks.
+func (a *Allowlist) Allows(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TrustProxies trusts the X-Forwarded-For header of the requests sent by the
+// proxies, CIDRs or addresses, only. Without proxies, the header is never
+// trusted, rather than always as by default.
+func TrustProxies(router *gin.Engine, proxies []string) error {
+	var trusted []string
+	for _, proxy := range proxies {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trusted); err != nil {
+		_ = router.SetTrustedProxies(nil)
+		return fmt.Errorf("unable to trust proxies: %w", err)
+	}
+
+	return nil
+}
+
+// Middleware forbids the requests of the other sources. The source is the
+// client IP of the request, read from X-Forwarded-For when sent by one of
+// the trusted proxies of the router.
+func Middleware(a *Allowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ip := c.ClientIP(); !a.Allows(ip) {
+			log.Ctx(c.Request.Context()).Warn().Str("ip", ip).Msg("source not allowed")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// allowed returns an error for the calls of the other sources, the source
+// being the address of the peer.
+func (a *Allowlist) allowed(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err == nil && a.Allows(host) {
+			return nil
+		}
+	}
+
+	return status.Error(codes.PermissionDenied, "forbidden")
+}
+
+// ServerOptions returns the interceptors forbidding the gRPC calls of the
+// other sources.
+func (a *Allowlist) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := a.allowed(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := a.allowed(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,142 @@
+package allowlist_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/allowlist"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should allow the networks and the addresses", func(t *testing.T) {
+		a, err := allowlist.Parse([]string{"10.0.0.0/8", " 203.0.113.7 ", "2001:db8::/32", ""})
+		require.NoError(t, err)
+
+		require.True(t, a.Allows("10.1.2.3"))
+		require.True(t, a.Allows("203.0.113.7"))
+		require.True(t, a.Allows("2001:db8::1"))
+		require.False(t, a.Allows("203.0.113.8"))
+		require.False(t, a.Allows("not an ip"))
+	})
+
+	t.Run("it should reject the invalid entries", func(t *testing.T) {
+		_, err := allowlist.Parse([]string{"10.0.0.0/33"})
+		require.Error(t, err)
+		_, err = allowlist.Parse([]string{"checker.openstatus.dev"})
+		require.Error(t, err)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	a, err := allowlist.Parse([]string{"203.0.113.0/24"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	require.NoError(t, allowlist.TrustProxies(router, []string{"10.0.0.0/8"}))
+	router.Use(allowlist.Middleware(a))
+	router.POST("/checker", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(remoteAddr, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Run("it should allow the sources of the allowlist", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve("203.0.113.7:1234", ""))
+	})
+
+	t.Run("it should read the source forwarded by the trusted proxies", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve("10.0.0.1:1234", "203.0.113.7"))
+		require.Equal(t, http.StatusForbidden, serve("10.0.0.1:1234", "198.51.100.1"))
+	})
+
+	t.Run("it should ignore the source forwarded by the other clients", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, serve("198.51.100.1:1234", "203.0.113.7"))
+	})
+
+	t.Run("it should ignore the forwarded source without trusted proxies", func(t *testing.T) {
+		router := gin.New()
+		require.NoError(t, allowlist.TrustProxies(router, nil))
+		router.Use(allowlist.Middleware(a))
+		router.POST("/checker", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.RemoteAddr = "198.51.100.1:1234"
+		r.Header.Set("X-Forwarded-For", "203.0.113.7")
+		router.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("it should trust no proxy when they are invalid", func(t *testing.T) {
+		router := gin.New()
+		require.Error(t, allowlist.TrustProxies(router, []string{"not a cidr"}))
+		router.Use(allowlist.Middleware(a))
+		router.POST("/checker", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", "203.0.113.7")
+		router.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestServerOptions(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, cidrs ...string) error {
+		a, err := allowlist.Parse(cidrs)
+		require.NoError(t, err)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := grpc.NewServer(a.ServerOptions()...)
+		healthpb.RegisterHealthServer(s, health.NewServer())
+		go s.Serve(lis)
+		defer s.Stop()
+
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	t.Run("it should allow the peers of the allowlist", func(t *testing.T) {
+		require.NoError(t, check(t, "127.0.0.1"))
+	})
+
+	t.Run("it should forbid the other peers", func(t *testing.T) {
+		require.Equal(t, codes.PermissionDenied, status.Code(check(t, "10.0.0.0/8")))
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the prefix of the routes of the current version of the API.
+const Version = "/v1"
+
+// Error is the error of the versioned routes, under the "error" key of the
+// response.
+type Error struct {
+	// Code identifies the error, e.g. "invalid_request", for the clients
+	// to branch on.
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Message is the body of the responses without content, e.g.
+// {"message": "ok"}.
+type Message struct {
+	Message string `json:"message"`
+}
+
+// codes are the codes of the errors, by status.
+var codes = map[int]string{
+	http.StatusBadRequest:          "invalid_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusMisdirectedRequest:  "wrong_region",
+	http.StatusTooManyRequests:     "rate_limited",
+	http.StatusInternalServerError: "internal",
+	http.StatusBadGateway:          "bad_gateway",
+	http.StatusServiceUnavailable:  "unavailable",
+}
+
+// Code returns the code of the errors of the status.
+func Code(status int) string {
+	if code, ok := codes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return "internal"
+	}
+
+	return "invalid_request"
+}
+
+// Envelope answers the errors of the handlers, {"error": "message"} along
+// with other fields, as an Error, the other fields being its details. The
+// handlers are shared by the legacy and the versioned routes.
+func Envelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &writer{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		w.flush()
+	}
+}
+
+// writer holds the bodies of the errors, to rewrite them once complete.
+type writer struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	buffered bool
+}
+
+func (w *writer) Write(data []byte) (int, error) {
+	if w.Status() < http.StatusBadRequest {
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffered = true
+	return w.body.Write(data)
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *writer) flush() {
+	if !w.buffered {
+		return
+	}
+
+	e := Error{Code: Code(w.Status()), Message: http.StatusText(w.Status())}
+	var body map[string]any
+	if err := json.Unmarshal(w.body.Bytes(), &body); err == nil {
+		if message, ok := body["error"].(string); ok {
+			e.Message = message
+			delete(body, "error")
+		}
+		if len(body) > 0 {
+			e.Details = body
+		}
+	} else if text := strings.TrimSpace(w.body.String()); text != "" {
+		e.Message = text
+	}
+
+	data, err := json.Marshal(gin.H{"error": e})
+	if err != nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.Write(data)
+}
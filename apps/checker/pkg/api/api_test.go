@@ -0,0 +1,62 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+
+	routes := func(router gin.IRouter) {
+		router.GET("/ping", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "pong"})
+		})
+		router.POST("/checker", func(c *gin.Context) {
+			c.JSON(http.StatusMisdirectedRequest, gin.H{"error": "wrong region", "region": "ams"})
+		})
+		router.GET("/unavailable", func(c *gin.Context) {
+			c.String(http.StatusServiceUnavailable, "draining")
+		})
+	}
+	router := gin.New()
+	routes(router)
+	routes(router.Group(api.Version, api.Envelope()))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("it should keep the errors of the legacy routes", func(t *testing.T) {
+		w := serve(http.MethodPost, "/checker")
+		require.Equal(t, http.StatusMisdirectedRequest, w.Code)
+		require.JSONEq(t, `{"error":"wrong region","region":"ams"}`, w.Body.String())
+	})
+
+	t.Run("it should answer the errors of the versioned routes in the envelope", func(t *testing.T) {
+		w := serve(http.MethodPost, "/v1/checker")
+		require.Equal(t, http.StatusMisdirectedRequest, w.Code)
+		require.JSONEq(t, `{"error":{"code":"wrong_region","message":"wrong region","details":{"region":"ams"}}}`, w.Body.String())
+	})
+
+	t.Run("it should wrap the errors in plain text", func(t *testing.T) {
+		w := serve(http.MethodGet, "/v1/unavailable")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"error":{"code":"unavailable","message":"draining"}}`, w.Body.String())
+	})
+
+	t.Run("it should leave the successes untouched", func(t *testing.T) {
+		w := serve(http.MethodGet, "/v1/ping")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"message":"pong"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,107 @@
+package assertion
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// Response is the part of a response the assertions apply to.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+	Latency    int64
+}
+
+// Result is the outcome of an assertion.
+type Result struct {
+	request.Assertion
+	Actual string `json:"actual"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Evaluate evaluates the assertions against the response.
+func Evaluate(assertions []request.Assertion, res Response) []Result {
+	results := make([]Result, 0, len(assertions))
+	for _, a := range assertions {
+		result := Result{Assertion: a}
+
+		switch a.Type {
+		case "status":
+			result.Actual = strconv.Itoa(res.StatusCode)
+		case "header":
+			result.Actual = res.Header.Get(a.Key)
+		case "body":
+			result.Actual = res.Body
+		case "latency":
+			result.Actual = strconv.FormatInt(res.Latency, 10)
+		default:
+			result.Error = fmt.Sprintf("unknown assertion type %q", a.Type)
+			results = append(results, result)
+			continue
+		}
+
+		passed, err := compare(a.Compare, result.Actual, a.Target)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Passed = passed
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Passed reports whether every assertion passed.
+func Passed(results []Result) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compare(comparator, actual, target string) (bool, error) {
+	switch comparator {
+	case "eq":
+		return actual == target, nil
+	case "not_eq":
+		return actual != target, nil
+	case "contains":
+		return strings.Contains(actual, target), nil
+	case "not_contains":
+		return !strings.Contains(actual, target), nil
+	case "empty":
+		return actual == "", nil
+	case "not_empty":
+		return actual != "", nil
+	case "gt", "gte", "lt", "lte":
+		a, err := strconv.ParseFloat(actual, 64)
+		if err != nil {
+			return false, fmt.Errorf("unable to compare %q as a number", actual)
+		}
+		t, err := strconv.ParseFloat(target, 64)
+		if err != nil {
+			return false, fmt.Errorf("unable to compare %q as a number", target)
+		}
+		switch comparator {
+		case "gt":
+			return a > t, nil
+		case "gte":
+			return a >= t, nil
+		case "lt":
+			return a < t, nil
+		default:
+			return a <= t, nil
+		}
+	default:
+		return false, fmt.Errorf("unknown comparator %q", comparator)
+	}
+}
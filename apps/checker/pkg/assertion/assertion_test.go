@@ -0,0 +1,47 @@
+package assertion_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertion"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	res := assertion.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       `{"status":"ok"}`,
+		Latency:    120,
+	}
+
+	tests := []struct {
+		name      string
+		assertion request.Assertion
+		passed    bool
+		err       bool
+	}{
+		{name: "status eq", assertion: request.Assertion{Type: "status", Compare: "eq", Target: "200"}, passed: true},
+		{name: "status not_eq", assertion: request.Assertion{Type: "status", Compare: "not_eq", Target: "200"}, passed: false},
+		{name: "header contains", assertion: request.Assertion{Type: "header", Key: "content-type", Compare: "contains", Target: "json"}, passed: true},
+		{name: "body not_contains", assertion: request.Assertion{Type: "body", Compare: "not_contains", Target: "error"}, passed: true},
+		{name: "latency lt", assertion: request.Assertion{Type: "latency", Compare: "lt", Target: "100"}, passed: false},
+		{name: "latency gte", assertion: request.Assertion{Type: "latency", Compare: "gte", Target: "120"}, passed: true},
+		{name: "not a number", assertion: request.Assertion{Type: "body", Compare: "gt", Target: "1"}, err: true},
+		{name: "unknown type", assertion: request.Assertion{Type: "cookie", Compare: "eq", Target: "1"}, err: true},
+		{name: "unknown comparator", assertion: request.Assertion{Type: "status", Compare: "like", Target: "2"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := assertion.Evaluate([]request.Assertion{tt.assertion}, res)
+			require.Len(t, results, 1)
+			require.Equal(t, tt.passed, results[0].Passed)
+			require.Equal(t, tt.err, results[0].Error != "")
+			require.Equal(t, tt.passed, assertion.Passed(results))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+)
+
+// The actions recorded by the audit log.
+const (
+	// CheckRun is recorded for every check run by the checker.
+	CheckRun = "check.run"
+	// CheckInspected is recorded for the checks run on demand.
+	CheckInspected = "check.inspected"
+	// StatusChanged is recorded for every status transition of a monitor.
+	StatusChanged = "status.changed"
+)
+
+// Actor is who, or what, triggered an action: the principal of an inbound
+// request, or the checker itself, e.g. its scheduler.
+type Actor struct {
+	// Source is "http", "grpc", "scheduler" or "queue".
+	Source     string `json:"source"`
+	Principal  string `json:"principal,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns the context of the actions triggered by the actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor of the context, along with the principal its
+// request was authenticated for.
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	if principal := auth.Principal(ctx); principal != "" {
+		actor.Principal = principal
+	}
+
+	return actor
+}
+
+// Middleware sets the actor of the inbound requests, their principal being
+// added once authenticated. The ID of the request is the one set by the
+// telemetry middleware, if any.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := telemetry.RequestID(c.Request.Context())
+		if id == "" {
+			id = c.GetHeader(telemetry.RequestIDHeader)
+		}
+		c.Request = c.Request.WithContext(WithActor(c.Request.Context(), Actor{
+			Source:     "http",
+			RequestID:  id,
+			RemoteAddr: c.ClientIP(),
+		}))
+		c.Next()
+	}
+}
+
+// Event is an entry of the audit log.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Actor
+	WorkspaceID string `json:"workspaceId,omitempty"`
+	MonitorID   string `json:"monitorId,omitempty"`
+	Region      string `json:"region,omitempty"`
+	// Status and Previous are the statuses of a transition.
+	Status   string `json:"status,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+func (Event) EventType() string {
+	return "audit"
+}
+
+// Log records the events to a dedicated sink.
+type Log struct {
+	sink sink.Sink
+}
+
+func New(s sink.Sink) *Log {
+	return &Log{sink: s}
+}
+
+// Record records the event, triggered by the actor of the context. The
+// failures are only logged, they never fail the action.
+func (l *Log) Record(ctx context.Context, event Event) {
+	event.Time = time.Now().UTC()
+	event.Actor = ActorFrom(ctx)
+
+	if err := l.sink.SendEvent(ctx, event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", event.Action).Msg("failed to record audit event")
+	}
+}
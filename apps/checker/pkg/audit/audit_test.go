@@ -0,0 +1,61 @@
+package audit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	sink := &recorder{}
+	log := audit.New(sink)
+	authenticator := auth.NewBasic(auth.NewKeyring(auth.Key{Name: "cron-secret", Secret: "secret"}))
+
+	router := gin.New()
+	router.Use(audit.Middleware())
+	router.POST("/checker", auth.Middleware(authenticator), func(c *gin.Context) {
+		log.Record(c.Request.Context(), audit.Event{Action: audit.CheckRun, MonitorID: "1"})
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("it should record the principal and the request of the actor", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.Header.Set("Authorization", "Basic secret")
+		r.Header.Set("X-Request-ID", "req-1")
+		r.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		require.Len(t, sink.events, 1)
+		event := sink.events[0].(audit.Event)
+		require.Equal(t, audit.CheckRun, event.Action)
+		require.Equal(t, "1", event.MonitorID)
+		require.Equal(t, audit.Actor{Source: "http", Principal: "cron-secret", RequestID: "req-1", RemoteAddr: "10.0.0.1"}, event.Actor)
+		require.False(t, event.Time.IsZero())
+	})
+
+	t.Run("it should record the actions of the checker itself", func(t *testing.T) {
+		log.Record(audit.WithActor(context.Background(), audit.Actor{Source: "scheduler"}), audit.Event{Action: audit.CheckRun})
+
+		event := sink.events[len(sink.events)-1].(audit.Event)
+		require.Equal(t, audit.Actor{Source: "scheduler"}, event.Actor)
+	})
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Authenticator verifies the credentials of an inbound request and returns
+// the principal it was issued to.
+type Authenticator interface {
+	Authenticate(r *http.Request) (string, error)
+}
+
+type basic struct {
+	keys *Keyring
+}
+
+// NewBasic returns an authenticator accepting the "Basic <secret>"
+// authorization header, for the secret of any key. The principal is the name
+// of the key.
+func NewBasic(keys *Keyring) Authenticator {
+	return basic{keys: keys}
+}
+
+func (b basic) Authenticate(r *http.Request) (string, error) {
+	given := []byte(r.Header.Get("Authorization"))
+	for _, key := range b.keys.Keys() {
+		if subtle.ConstantTimeCompare(given, []byte("Basic "+key.Secret)) == 1 {
+			used(r.Context(), key, "basic")
+			return key.Name, nil
+		}
+	}
+
+	return "", ErrUnauthorized
+}
+
+type anyOf []Authenticator
+
+// Any returns an authenticator accepting the requests accepted by any of the
+// given authenticators.
+func Any(authenticators ...Authenticator) Authenticator {
+	return anyOf(authenticators)
+}
+
+func (a anyOf) Authenticate(r *http.Request) (string, error) {
+	for _, authenticator := range a {
+		if principal, err := authenticator.Authenticate(r); err == nil {
+			return principal, nil
+		}
+	}
+
+	return "", ErrUnauthorized
+}
+
+// PrincipalKey is the gin context key of the authenticated principal.
+const PrincipalKey = "principal"
+
+type principalKey struct{}
+
+// WithPrincipal returns the context of a request authenticated for the
+// principal.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the principal the request of the context was
+// authenticated for, if any.
+func Principal(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// Middleware rejects the requests the authenticator does not accept.
+func Middleware(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
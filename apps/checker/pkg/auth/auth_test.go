@@ -0,0 +1,464 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/stretchr/testify/require"
+)
+
+// keyring returns a keyring of the secret, as the cron secret.
+func keyring(secret string) *auth.Keyring {
+	return auth.NewKeyring(auth.Key{Name: "cron-secret", Secret: secret})
+}
+
+func TestBasic(t *testing.T) {
+	t.Parallel()
+
+	request := func(authorization string) *http.Request {
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.Header.Set("Authorization", authorization)
+		return r
+	}
+
+	t.Run("it should accept the secret", func(t *testing.T) {
+		principal, err := auth.NewBasic(keyring("secret")).Authenticate(request("Basic secret"))
+		require.NoError(t, err)
+		require.Equal(t, "cron-secret", principal)
+	})
+
+	t.Run("it should reject another secret", func(t *testing.T) {
+		_, err := auth.NewBasic(keyring("secret")).Authenticate(request("Basic other"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject everything without a secret", func(t *testing.T) {
+		_, err := auth.NewBasic(keyring("")).Authenticate(request("Basic "))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should accept the requests accepted by any authenticator", func(t *testing.T) {
+		a := auth.Any(auth.NewBasic(keyring("one")), auth.NewBasic(keyring("two")))
+
+		_, err := a.Authenticate(request("Basic two"))
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(request("Basic three"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+}
+
+func TestHMAC(t *testing.T) {
+	t.Parallel()
+
+	request := func(secret string, sent time.Time, body string) *http.Request {
+		r, _ := http.NewRequest(http.MethodPost, "/checker", strings.NewReader(body))
+		r.Header.Set(auth.TimestampHeader, strconv.FormatInt(sent.Unix(), 10))
+		r.Header.Set(auth.SignatureHeader, auth.Sign(secret, sent.Unix(), "", http.MethodPost, "/checker", []byte(body)))
+		return r
+	}
+
+	t.Run("it should accept a signed request and restore its body", func(t *testing.T) {
+		r := request("secret", time.Now(), `{"monitorId":"1"}`)
+		principal, err := auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(r)
+		require.NoError(t, err)
+		require.Equal(t, "cron-secret", principal)
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"monitorId":"1"}`, string(body))
+	})
+
+	t.Run("it should reject another secret", func(t *testing.T) {
+		_, err := auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(request("other", time.Now(), "{}"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject a tampered body", func(t *testing.T) {
+		r := request("secret", time.Now(), `{"monitorId":"1"}`)
+		r.Body = io.NopCloser(strings.NewReader(`{"monitorId":"2"}`))
+		_, err := auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(r)
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject another method or path", func(t *testing.T) {
+		r := request("secret", time.Now(), "{}")
+		r.URL.Path = "/checker/batch"
+		_, err := auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(r)
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+
+		r = request("secret", time.Now(), "{}")
+		r.Method = http.MethodPut
+		_, err = auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(r)
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject a too large body", func(t *testing.T) {
+		body := `{"monitorId":"` + strings.Repeat("1", 5<<20) + `"}`
+		_, err := auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(request("secret", time.Now(), body))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject an old request", func(t *testing.T) {
+		_, err := auth.NewHMAC(keyring("secret"), time.Minute).Authenticate(request("secret", time.Now().Add(-2*time.Minute), "{}"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject a replayed request", func(t *testing.T) {
+		a := auth.NewHMAC(keyring("secret"), time.Minute)
+		sent := time.Now()
+
+		_, err := a.Authenticate(request("secret", sent, "{}"))
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(request("secret", sent, "{}"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should accept the identical requests with distinct nonces", func(t *testing.T) {
+		a := auth.NewHMAC(keyring("secret"), time.Minute)
+		sent := time.Now()
+
+		for _, nonce := range []string{"1", "2"} {
+			r, _ := http.NewRequest(http.MethodPost, "/checker", strings.NewReader("{}"))
+			r.Header.Set(auth.TimestampHeader, strconv.FormatInt(sent.Unix(), 10))
+			r.Header.Set(auth.NonceHeader, nonce)
+			r.Header.Set(auth.SignatureHeader, auth.Sign("secret", sent.Unix(), nonce, http.MethodPost, "/checker", []byte("{}")))
+			_, err := a.Authenticate(r)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("it should reject everything without a secret", func(t *testing.T) {
+		_, err := auth.NewHMAC(keyring(""), time.Minute).Authenticate(request("", time.Now(), "{}"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should sign the requests of the signer", func(t *testing.T) {
+		a := auth.NewHMAC(keyring("secret"), time.Minute)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := a.Authenticate(r); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: auth.NewSigner(http.DefaultTransport, keyring("secret"))}
+		for i := 0; i < 2; i++ {
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode, "the identical requests should be accepted")
+		}
+	})
+}
+
+func TestKeyring(t *testing.T) {
+	t.Parallel()
+
+	basic := func(secret string) *http.Request {
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.Header.Set("Authorization", "Basic "+secret)
+		return r
+	}
+
+	t.Run("it should accept the old and the new key during a rotation", func(t *testing.T) {
+		keys := auth.NewKeyring(auth.Key{Name: "new", Secret: "new-secret"}, auth.Key{Name: "old", Secret: "old-secret"})
+		a := auth.NewBasic(keys)
+
+		principal, err := a.Authenticate(basic("new-secret"))
+		require.NoError(t, err)
+		require.Equal(t, "new", principal)
+
+		principal, err = a.Authenticate(basic("old-secret"))
+		require.NoError(t, err)
+		require.Equal(t, "old", principal)
+
+		current, ok := keys.Current()
+		require.True(t, ok)
+		require.Equal(t, "new", current.Name)
+	})
+
+	t.Run("it should load the keys of a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"name":"loaded","secret":"loaded-secret"}]`), 0o600))
+
+		keys := keyring("secret")
+		require.NoError(t, keys.Load(path))
+		a := auth.NewBasic(keys)
+
+		principal, err := a.Authenticate(basic("loaded-secret"))
+		require.NoError(t, err)
+		require.Equal(t, "loaded", principal)
+
+		_, err = a.Authenticate(basic("secret"))
+		require.NoError(t, err)
+
+		// The removed keys are rejected once reloaded.
+		require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+		require.NoError(t, keys.Load(path))
+		_, err = a.Authenticate(basic("loaded-secret"))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should verify the signatures of any key", func(t *testing.T) {
+		a := auth.NewHMAC(auth.NewKeyring(auth.Key{Name: "new", Secret: "new-secret"}, auth.Key{Name: "old", Secret: "old-secret"}), time.Minute)
+
+		sent := time.Now()
+		r, _ := http.NewRequest(http.MethodPost, "/checker", strings.NewReader("{}"))
+		r.Header.Set(auth.TimestampHeader, strconv.FormatInt(sent.Unix(), 10))
+		r.Header.Set(auth.SignatureHeader, auth.Sign("old-secret", sent.Unix(), "", http.MethodPost, "/checker", []byte("{}")))
+
+		principal, err := a.Authenticate(r)
+		require.NoError(t, err)
+		require.Equal(t, "old", principal)
+	})
+}
+
+func TestOIDC(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", "test"))
+	require.NoError(t, err)
+
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]any{
+				"issuer":                                issuer,
+				"jwks_uri":                              issuer + "/keys",
+				"id_token_signing_alg_values_supported": []string{"RS256"},
+			})
+		case "/keys":
+			json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	issuer = server.URL
+
+	token := func(claims map[string]any) *http.Request {
+		payload, _ := json.Marshal(claims)
+		signed, err := signer.Sign(payload)
+		require.NoError(t, err)
+		raw, err := signed.CompactSerialize()
+		require.NoError(t, err)
+
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.Header.Set("Authorization", "Bearer "+raw)
+		return r
+	}
+	claims := func(iss, aud, email string) map[string]any {
+		return map[string]any{
+			"iss":            iss,
+			"aud":            aud,
+			"sub":            "1234",
+			"email":          email,
+			"email_verified": true,
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	ctx := context.Background()
+	a, err := auth.NewOIDC(ctx, issuer, []string{"https://checker.openstatus.dev", "checker"}, []string{"scheduler@openstatus.iam.gserviceaccount.com"})
+	require.NoError(t, err)
+
+	t.Run("it should accept a token of the issuer for an audience", func(t *testing.T) {
+		principal, err := a.Authenticate(token(claims(issuer, "checker", "scheduler@openstatus.iam.gserviceaccount.com")))
+		require.NoError(t, err)
+		require.Equal(t, "scheduler@openstatus.iam.gserviceaccount.com", principal)
+	})
+
+	t.Run("it should reject a token of another issuer", func(t *testing.T) {
+		_, err := a.Authenticate(token(claims("https://accounts.example.com", "checker", "scheduler@openstatus.iam.gserviceaccount.com")))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject a token for another audience", func(t *testing.T) {
+		_, err := a.Authenticate(token(claims(issuer, "other", "scheduler@openstatus.iam.gserviceaccount.com")))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should reject a token of another service account", func(t *testing.T) {
+		_, err := a.Authenticate(token(claims(issuer, "checker", "other@openstatus.iam.gserviceaccount.com")))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should accept a token of an allowed subject", func(t *testing.T) {
+		a, err := auth.NewOIDC(ctx, issuer, []string{"checker"}, []string{"1234"})
+		require.NoError(t, err)
+
+		principal, err := a.Authenticate(token(claims(issuer, "checker", "")))
+		require.NoError(t, err)
+		require.Equal(t, "1234", principal)
+	})
+
+	t.Run("it should reject an unverified email", func(t *testing.T) {
+		unverified := claims(issuer, "checker", "scheduler@openstatus.iam.gserviceaccount.com")
+		unverified["email_verified"] = false
+		_, err := a.Authenticate(token(unverified))
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+	})
+
+	t.Run("it should require an audience", func(t *testing.T) {
+		_, err := auth.NewOIDC(ctx, issuer, []string{""}, []string{"scheduler@openstatus.iam.gserviceaccount.com"})
+		require.Error(t, err)
+	})
+
+	t.Run("it should require the principals", func(t *testing.T) {
+		_, err := auth.NewOIDC(ctx, issuer, []string{"checker"}, []string{""})
+		require.Error(t, err)
+	})
+}
+
+// issue writes a certificate of the name and its key to the directory,
+// signed by the parent, or self-signed as a CA without parent.
+func issue(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return cert, key
+}
+
+func TestMTLS(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	ca, caKey := issue(t, dir, "ca", nil, nil)
+	issue(t, dir, "checker", ca, caKey)
+	issue(t, dir, "control-plane", ca, caKey)
+	issue(t, dir, "coordinator", ca, caKey)
+	rogue, rogueKey := issue(t, dir, "rogue-ca", nil, nil)
+	issue(t, dir, "rogue", rogue, rogueKey)
+
+	config, err := auth.ServerTLS(path("checker.pem"), path("checker.key"), path("ca.pem"))
+	require.NoError(t, err)
+
+	a := auth.NewClientCert([]string{"control-plane"})
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, principal)
+	}))
+	server.TLS = config
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(t *testing.T, cert string) (int, string) {
+		t.Helper()
+
+		transport := &http.Transport{}
+		if cert != "" {
+			config, err := auth.ClientTLS(path(cert+".pem"), path(cert+".key"), path("ca.pem"))
+			require.NoError(t, err)
+			transport.TLSClientConfig = config
+		} else {
+			pool := x509.NewCertPool()
+			pool.AddCert(ca)
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		}
+		defer transport.CloseIdleConnections()
+
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("it should accept the certificate of the client ca", func(t *testing.T) {
+		status, principal := get(t, "control-plane")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "control-plane", principal)
+	})
+
+	t.Run("it should reject another name of the client ca", func(t *testing.T) {
+		status, _ := get(t, "coordinator")
+		require.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("it should reject a request without certificate", func(t *testing.T) {
+		status, _ := get(t, "")
+		require.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("it should reject the certificate of another ca", func(t *testing.T) {
+		status, _ := get(t, "rogue")
+		require.NotEqual(t, http.StatusOK, status)
+	})
+
+	t.Run("it should require all the authenticators", func(t *testing.T) {
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.Header.Set("Authorization", "Basic secret")
+
+		_, err := auth.All(auth.NewClientCert(nil), auth.NewBasic(keyring("secret"))).Authenticate(r)
+		require.ErrorIs(t, err, auth.ErrUnauthorized)
+
+		principal, err := auth.All(auth.NewBasic(keyring("secret"))).Authenticate(r)
+		require.NoError(t, err)
+		require.Equal(t, "cron-secret", principal)
+	})
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The headers of the signed requests.
+const (
+	TimestampHeader = "X-OpenStatus-Timestamp"
+	NonceHeader     = "X-OpenStatus-Nonce"
+	SignatureHeader = "X-OpenStatus-Signature"
+)
+
+// maxSignedBody is the size of the largest body read for a signature.
+const maxSignedBody = 4 << 20
+
+// Sign returns the signature of the request to the uri, its path and query,
+// sent at the unix timestamp with the nonce, the hex encoded HMAC-SHA256 of
+// "<timestamp>.<nonce>.<method>.<uri>.<body>" with the secret, or of
+// "<timestamp>.<method>.<uri>.<body>" without nonce.
+func Sign(secret string, timestamp int64, nonce, method, uri string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	if nonce != "" {
+		fmt.Fprintf(mac, "%d.%s.%s.%s.", timestamp, nonce, method, uri)
+	} else {
+		fmt.Fprintf(mac, "%d.%s.%s.", timestamp, method, uri)
+	}
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type signed struct {
+	keys      *Keyring
+	tolerance time.Duration
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+	// used are the used signatures in the order they were seen, forgotten
+	// once they can no longer be within the tolerance.
+	used []usedSignature
+}
+
+type usedSignature struct {
+	signature string
+	expiresAt time.Time
+}
+
+// NewHMAC returns an authenticator accepting the requests signed with the
+// secret of any key, sent less than tolerance ago. A signature is only
+// accepted once by the instance, preventing the replays to it: the used
+// signatures are not shared, a request may still be replayed once to each
+// of the other instances of the region. The identical requests sent within
+// the same second need a distinct nonce. The principal is the name of the
+// key.
+func NewHMAC(keys *Keyring, tolerance time.Duration) Authenticator {
+	return &signed{keys: keys, tolerance: tolerance, seen: map[string]struct{}{}}
+}
+
+func (s *signed) Authenticate(r *http.Request) (string, error) {
+	signature := r.Header.Get(SignatureHeader)
+	if signature == "" {
+		return "", ErrUnauthorized
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return "", ErrUnauthorized
+	}
+	sent := time.Unix(timestamp, 0)
+	if age := time.Since(sent); age > s.tolerance || age < -s.tolerance {
+		return "", ErrUnauthorized
+	}
+
+	// The body is read for the signature, and restored for the handlers.
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(http.MaxBytesReader(nil, r.Body, maxSignedBody))
+		r.Body.Close()
+		if err != nil {
+			return "", ErrUnauthorized
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var (
+		key   Key
+		valid bool
+	)
+	for _, k := range s.keys.Keys() {
+		if hmac.Equal([]byte(signature), []byte(Sign(k.Secret, timestamp, r.Header.Get(NonceHeader), r.Method, r.URL.RequestURI(), body))) {
+			key, valid = k, true
+			break
+		}
+	}
+	if !valid {
+		return "", ErrUnauthorized
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A signature is valid until its timestamp is older than the tolerance,
+	// at most twice the tolerance after it was seen: the signatures are
+	// forgotten in the order they were seen.
+	now := time.Now()
+	for len(s.used) > 0 && now.After(s.used[0].expiresAt) {
+		delete(s.seen, s.used[0].signature)
+		s.used = s.used[1:]
+	}
+	if _, ok := s.seen[signature]; ok {
+		return "", ErrUnauthorized
+	}
+	s.seen[signature] = struct{}{}
+	s.used = append(s.used, usedSignature{signature: signature, expiresAt: now.Add(2 * s.tolerance)})
+
+	used(r.Context(), key, "hmac")
+	return key.Name, nil
+}
+
+type signer struct {
+	next http.RoundTripper
+	keys *Keyring
+}
+
+// NewSigner returns a transport signing the requests with the current key
+// of the keyring, each with a random nonce. Without keys, the requests are
+// sent unsigned.
+func NewSigner(next http.RoundTripper, keys *Keyring) http.RoundTripper {
+	return signer{next: next, keys: keys}
+}
+
+func (s signer) RoundTrip(r *http.Request) (*http.Response, error) {
+	key, ok := s.keys.Current()
+	if !ok {
+		return s.next.RoundTrip(r)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read body: %w", err)
+		}
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	signed := r.Clone(r.Context())
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	signed.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	signed.Header.Set(NonceHeader, hex.EncodeToString(nonce))
+	signed.Header.Set(SignatureHeader, Sign(key.Secret, timestamp, signed.Header.Get(NonceHeader), signed.Method, signed.URL.RequestURI(), body))
+
+	return s.next.RoundTrip(signed)
+}
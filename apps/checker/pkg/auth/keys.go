@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var keysUsed, _ = telemetry.Meter().Int64Counter("checker.auth.keys.used",
+	metric.WithDescription("Requests authenticated with the keys."),
+)
+
+// Key is a named secret accepted from the callers. The name is the principal
+// of the requests authenticated with the key.
+type Key struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+// Keyring holds the keys accepted at once, so a secret can be rotated
+// without downtime: the new key is added, the callers move to it, and the
+// old key is removed once unused.
+type Keyring struct {
+	// static keys are the ones of the configuration, loaded the ones of
+	// the keys file.
+	static []Key
+
+	mu     sync.RWMutex
+	loaded []Key
+}
+
+// NewKeyring returns a keyring of the keys. The keys with an empty secret
+// are ignored.
+func NewKeyring(keys ...Key) *Keyring {
+	return &Keyring{static: valid(keys)}
+}
+
+func valid(keys []Key) []Key {
+	var valid []Key
+	for _, key := range keys {
+		if key.Secret != "" {
+			valid = append(valid, key)
+		}
+	}
+
+	return valid
+}
+
+// Set replaces the keys loaded in addition to the ones of the keyring.
+func (k *Keyring) Set(keys []Key) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.loaded = valid(keys)
+}
+
+// Keys returns the keys of the keyring, then the loaded ones.
+func (k *Keyring) Keys() []Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]Key, 0, len(k.static)+len(k.loaded))
+	keys = append(keys, k.static...)
+	return append(keys, k.loaded...)
+}
+
+// Current returns the key signing the outbound requests, the first one.
+func (k *Keyring) Current() (Key, bool) {
+	keys := k.Keys()
+	if len(keys) == 0 {
+		return Key{}, false
+	}
+
+	return keys[0], true
+}
+
+// used records a request authenticated with the key.
+func used(ctx context.Context, key Key, method string) {
+	keysUsed.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("key", key.Name),
+		attribute.String("method", method),
+	))
+}
+
+// Load replaces the loaded keys with the ones of a JSON file containing an
+// array of keys, e.g. mounted from a secret manager.
+func (k *Keyring) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read keys: %w", err)
+	}
+
+	var keys []Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("unable to decode keys: %w", err)
+	}
+
+	k.Set(keys)
+	return nil
+}
+
+// Run reloads the keys from the file every refresh until the context is
+// done.
+func (k *Keyring) Run(ctx context.Context, path string, refresh time.Duration) {
+	if err := k.Load(path); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load keys")
+	}
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload keys")
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// ServerTLS returns the TLS config of a server presenting the certificate.
+// With a client CA, the certificates of the clients are verified against
+// it, and required by the client certificate authenticator.
+func ServerTLS(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pool, err := certPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		// The certificates are only verified when given, so the public
+		// endpoints, such as the heartbeats, stay reachable.
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// ClientTLS returns the TLS config of a client presenting the certificate,
+// and verifying the servers against the CA when given, or else the system
+// roots.
+func ClientTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if caFile != "" {
+		if config.RootCAs, err = certPool(caFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+func certPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ca: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("unable to parse ca: no certificate found")
+	}
+
+	return pool, nil
+}
+
+type clientCert struct {
+	names map[string]bool
+}
+
+// NewClientCert returns an authenticator accepting the requests with a
+// client certificate verified by the server. When names are given, the
+// certificate must have one of them as common name or DNS name. The
+// principal is the common name of the certificate.
+func NewClientCert(names []string) Authenticator {
+	return clientCert{names: set(names)}
+}
+
+func (c clientCert) Authenticate(r *http.Request) (string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", ErrUnauthorized
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	if len(c.names) == 0 || c.names[cert.Subject.CommonName] {
+		return cert.Subject.CommonName, nil
+	}
+	for _, name := range cert.DNSNames {
+		if c.names[name] {
+			return cert.Subject.CommonName, nil
+		}
+	}
+
+	return "", ErrUnauthorized
+}
+
+type allOf []Authenticator
+
+// All returns an authenticator accepting the requests accepted by all of
+// the given authenticators, with the principal of the last one.
+func All(authenticators ...Authenticator) Authenticator {
+	return allOf(authenticators)
+}
+
+func (a allOf) Authenticate(r *http.Request) (string, error) {
+	var principal string
+	for _, authenticator := range a {
+		var err error
+		if principal, err = authenticator.Authenticate(r); err != nil {
+			return "", ErrUnauthorized
+		}
+	}
+
+	return principal, nil
+}
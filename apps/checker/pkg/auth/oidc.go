@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+type oidcAuth struct {
+	verifier   *oidc.IDTokenVerifier
+	audiences  map[string]bool
+	principals map[string]bool
+}
+
+// NewOIDC returns an authenticator accepting the "Bearer <id token>"
+// authorization header, for ID tokens issued by the issuer for one of the
+// audiences, such as the ones Cloud Scheduler and Cloud Tasks attach to
+// their requests. The token must belong to one of the principals, by its
+// verified email or its subject: any account of a public issuer, e.g.
+// Google, can get a token for the audiences.
+func NewOIDC(ctx context.Context, issuer string, audiences, principals []string) (Authenticator, error) {
+	if len(set(audiences)) == 0 {
+		return nil, fmt.Errorf("missing oidc audience")
+	}
+	if len(set(principals)) == 0 {
+		return nil, fmt.Errorf("missing oidc principals")
+	}
+
+	provider, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover oidc provider: %w", err)
+	}
+
+	// The audience is verified against all the audiences below.
+	return oidcAuth{
+		verifier:   provider.Verifier(&oidc.Config{SkipClientIDCheck: true}),
+		audiences:  set(audiences),
+		principals: set(principals),
+	}, nil
+}
+
+// set returns the set of the non empty values.
+func set(values []string) map[string]bool {
+	s := map[string]bool{}
+	for _, value := range values {
+		if value != "" {
+			s[value] = true
+		}
+	}
+
+	return s
+}
+
+func (a oidcAuth) Authenticate(r *http.Request) (string, error) {
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", ErrUnauthorized
+	}
+
+	token, err := a.verifier.Verify(r.Context(), raw)
+	if err != nil {
+		return "", ErrUnauthorized
+	}
+
+	audience := false
+	for _, aud := range token.Audience {
+		audience = audience || a.audiences[aud]
+	}
+	if !audience {
+		return "", ErrUnauthorized
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		return "", ErrUnauthorized
+	}
+
+	if claims.Email != "" && claims.EmailVerified && a.principals[claims.Email] {
+		return claims.Email, nil
+	}
+	if a.principals[token.Subject] {
+		return token.Subject, nil
+	}
+
+	return "", ErrUnauthorized
+}
@@ -0,0 +1,113 @@
+package cloudtasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+const baseURL = "https://cloudtasks.googleapis.com/v2"
+
+type Client interface {
+	// CreateTask schedules a check, delivered by Cloud Tasks to the target
+	// with an OIDC token at the schedule time.
+	CreateTask(ctx context.Context, req request.CheckerRequest, scheduleTime time.Time) error
+}
+
+type client struct {
+	httpClient     *http.Client
+	queue          string
+	targetURL      string
+	serviceAccount string
+	audience       string
+}
+
+// NewClient returns a client creating the tasks in the queue, formatted as
+// projects/<project>/locations/<location>/queues/<queue>. The http client
+// must be authorized to call the Cloud Tasks API.
+func NewClient(httpClient *http.Client, queue, targetURL, serviceAccount, audience string) Client {
+	return client{
+		httpClient:     httpClient,
+		queue:          queue,
+		targetURL:      targetURL,
+		serviceAccount: serviceAccount,
+		audience:       audience,
+	}
+}
+
+type oidcToken struct {
+	ServiceAccountEmail string `json:"serviceAccountEmail"`
+	Audience            string `json:"audience,omitempty"`
+}
+
+type httpRequest struct {
+	URL        string            `json:"url"`
+	HTTPMethod string            `json:"httpMethod"`
+	Headers    map[string]string `json:"headers"`
+	Body       []byte            `json:"body"`
+	OIDCToken  oidcToken         `json:"oidcToken"`
+}
+
+type task struct {
+	ScheduleTime string      `json:"scheduleTime"`
+	HTTPRequest  httpRequest `json:"httpRequest"`
+}
+
+type createTaskRequest struct {
+	Task task `json:"task"`
+}
+
+func (c client) CreateTask(ctx context.Context, req request.CheckerRequest, scheduleTime time.Time) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("unable to encode checker request: %w", err)
+	}
+
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(createTaskRequest{
+		Task: task{
+			ScheduleTime: scheduleTime.UTC().Format(time.RFC3339Nano),
+			HTTPRequest: httpRequest{
+				URL:        c.targetURL,
+				HTTPMethod: http.MethodPost,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       body,
+				OIDCToken: oidcToken{
+					ServiceAccountEmail: c.serviceAccount,
+					Audience:            c.audience,
+				},
+			},
+		},
+	}); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to encode payload")
+		return fmt.Errorf("unable to encode payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s/tasks", baseURL, c.queue)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload.Bytes()))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to create request")
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to send request")
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Ctx(ctx).Error().Str("status", resp.Status).Msg("unexpected status code")
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
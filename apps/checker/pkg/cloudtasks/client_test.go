@@ -0,0 +1,94 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/cloudtasks"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+type interceptorHTTPClient struct {
+	f func(req *http.Request) (*http.Response, error)
+}
+
+func (i *interceptorHTTPClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	return i.f(req)
+}
+
+func (i *interceptorHTTPClient) GetHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: i,
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := "projects/openstatus/locations/europe-west1/queues/checker"
+	scheduleTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("it should return an error if it can not create the task", func(t *testing.T) {
+		interceptor := &interceptorHTTPClient{
+			f: func(req *http.Request) (*http.Response, error) {
+				return nil, fmt.Errorf("unable to send request")
+			},
+		}
+
+		client := cloudtasks.NewClient(interceptor.GetHTTPClient(), queue, "https://checker/checker", "checker@openstatus.iam.gserviceaccount.com", "")
+
+		err := client.CreateTask(ctx, request.CheckerRequest{MonitorID: "1"}, scheduleTime)
+		require.Error(t, err)
+	})
+
+	t.Run("it should create an http task with an oidc token", func(t *testing.T) {
+		var (
+			url     string
+			payload struct {
+				Task struct {
+					ScheduleTime string `json:"scheduleTime"`
+					HTTPRequest  struct {
+						URL       string `json:"url"`
+						Body      []byte `json:"body"`
+						OIDCToken struct {
+							ServiceAccountEmail string `json:"serviceAccountEmail"`
+						} `json:"oidcToken"`
+					} `json:"httpRequest"`
+				} `json:"task"`
+			}
+		)
+		interceptor := &interceptorHTTPClient{
+			f: func(req *http.Request) (*http.Response, error) {
+				url = req.URL.String()
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       http.NoBody,
+				}, nil
+			},
+		}
+
+		client := cloudtasks.NewClient(interceptor.GetHTTPClient(), queue, "https://checker/checker", "checker@openstatus.iam.gserviceaccount.com", "")
+
+		err := client.CreateTask(ctx, request.CheckerRequest{MonitorID: "1"}, scheduleTime)
+		require.NoError(t, err)
+		require.Equal(t, "https://cloudtasks.googleapis.com/v2/projects/openstatus/locations/europe-west1/queues/checker/tasks", url)
+		require.Equal(t, "2024-01-01T10:00:00Z", payload.Task.ScheduleTime)
+		require.Equal(t, "https://checker/checker", payload.Task.HTTPRequest.URL)
+		require.Equal(t, "checker@openstatus.iam.gserviceaccount.com", payload.Task.HTTPRequest.OIDCToken.ServiceAccountEmail)
+
+		var req request.CheckerRequest
+		require.NoError(t, json.Unmarshal(payload.Task.HTTPRequest.Body, &req))
+		require.Equal(t, "1", req.MonitorID)
+	})
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the checker, by key, e.g. "WORKERS". The
+// values of the flags take precedence over the environment variables, which
+// take precedence over the config file.
+type Config struct {
+	flags map[string]string
+
+	mu      sync.RWMutex
+	file    map[string]string
+	modTime time.Time
+	hooks   []func()
+}
+
+// New returns a config overridden by the values of the flags, by key.
+func New(flags map[string]string) *Config {
+	overrides := make(map[string]string, len(flags))
+	for key, value := range flags {
+		overrides[normalize(key)] = value
+	}
+
+	return &Config{flags: overrides, file: map[string]string{}}
+}
+
+// Lookup returns the value of the key, from the flags, the environment or the
+// config file.
+func (c *Config) Lookup(key string) (string, bool) {
+	if value, ok := c.flags[key]; ok {
+		return value, true
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.file[key]
+	return value, ok
+}
+
+// Load replaces the values of the config file, a YAML, TOML or JSON one
+// depending on its extension. The nested keys are joined with "_", e.g.
+// "tinybird.token" is TINYBIRD_TOKEN, and the lists with ",".
+func (c *Config) Load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config: %w", err)
+	}
+
+	var values map[string]any
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("unsupported config format %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to decode config: %w", err)
+	}
+
+	file := map[string]string{}
+	flatten("", values, file)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file = file
+	c.modTime = info.ModTime()
+	return nil
+}
+
+// flatten adds the values to the config file, by key.
+func flatten(prefix string, value any, file map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+			flatten(key, value, file)
+		}
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		file[normalize(prefix)] = strings.Join(items, ",")
+	case nil:
+		file[normalize(prefix)] = ""
+	default:
+		file[normalize(prefix)] = fmt.Sprint(v)
+	}
+}
+
+// normalize returns the key of the environment variable, e.g. WORKERS for
+// "workers".
+func normalize(key string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// OnReload registers fn to be called after every reload of the config, to
+// apply the values which can change at runtime.
+func (c *Config) OnReload(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hooks = append(c.hooks, fn)
+}
+
+// Reload reloads the config file, if any, and calls the hooks.
+func (c *Config) Reload(ctx context.Context, path string) {
+	if path != "" {
+		if err := c.Load(path); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("path", path).Msg("failed to reload config")
+			return
+		}
+	}
+
+	c.mu.RLock()
+	hooks := c.hooks
+	c.mu.RUnlock()
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// modified tells whether the config file was modified since its last load.
+func (c *Config) modified(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !info.ModTime().Equal(c.modTime)
+}
+
+// Run reloads the config on SIGHUP, and when the config file is modified,
+// checked every refresh, until the context is done.
+func (c *Config) Run(ctx context.Context, path string, refresh time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Ctx(ctx).Info().Msg("reloading config")
+			c.Reload(ctx, path)
+		case <-ticker.C:
+			if path != "" && c.modified(path) {
+				log.Ctx(ctx).Info().Str("path", path).Msg("config modified, reloading it")
+				c.Reload(ctx, path)
+			}
+		}
+	}
+}
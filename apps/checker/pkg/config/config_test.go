@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"checker.yaml": "workers: 32\nsinks: [tinybird, sqlite]\ntinybird:\n  token: secret\nlog-level: info\n",
+		"checker.toml": "workers = 32\nsinks = [\"tinybird\", \"sqlite\"]\nlog-level = \"info\"\n\n[tinybird]\ntoken = \"secret\"\n",
+		"checker.json": `{"workers": 32, "sinks": ["tinybird", "sqlite"], "tinybird": {"token": "secret"}, "log-level": "info"}`,
+	}
+
+	for name, content := range files {
+		name, content := name, content
+		t.Run("it should flatten the keys of "+filepath.Ext(name), func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			c := config.New(nil)
+			require.NoError(t, c.Load(path))
+			for key, want := range map[string]string{
+				"WORKERS":        "32",
+				"SINKS":          "tinybird,sqlite",
+				"TINYBIRD_TOKEN": "secret",
+				"LOG_LEVEL":      "info",
+			} {
+				value, ok := c.Lookup(key)
+				require.True(t, ok, key)
+				require.Equal(t, want, value, key)
+			}
+		})
+	}
+
+	t.Run("it should reject the unknown formats", func(t *testing.T) {
+		path := filepath.Join(dir, "checker.ini")
+		require.NoError(t, os.WriteFile(path, []byte("workers=32"), 0o600))
+		require.Error(t, config.New(nil).Load(path))
+	})
+}
+
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checker.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("checker_test_flag: file\nchecker_test_env: file\nchecker_test_file: file\n"), 0o600))
+	t.Setenv("CHECKER_TEST_FLAG", "env")
+	t.Setenv("CHECKER_TEST_ENV", "env")
+
+	c := config.New(map[string]string{"checker-test-flag": "flag"})
+	require.NoError(t, c.Load(path))
+
+	t.Run("it should prefer the flags, then the environment, then the file", func(t *testing.T) {
+		for key, want := range map[string]string{
+			"CHECKER_TEST_FLAG": "flag",
+			"CHECKER_TEST_ENV":  "env",
+			"CHECKER_TEST_FILE": "file",
+		} {
+			value, _ := c.Lookup(key)
+			require.Equal(t, want, value, key)
+		}
+		_, ok := c.Lookup("CHECKER_TEST_MISSING")
+		require.False(t, ok)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "checker.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("checker_test_level: warn\n"), 0o600))
+	c := config.New(nil)
+	require.NoError(t, c.Load(path))
+
+	reloaded := make(chan string, 1)
+	c.OnReload(func() {
+		value, _ := c.Lookup("CHECKER_TEST_LEVEL")
+		reloaded <- value
+	})
+	go c.Run(ctx, path, 10*time.Millisecond)
+
+	t.Run("it should reload the modified file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("checker_test_level: debug\n"), 0o600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+		select {
+		case value := <-reloaded:
+			require.Equal(t, "debug", value)
+		case <-time.After(time.Second):
+			t.Fatal("the config was not reloaded")
+		}
+	})
+}
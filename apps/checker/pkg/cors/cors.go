@@ -0,0 +1,85 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The headers of the requests made by the browsers, and the ones of the
+// responses they can read.
+const (
+	allowedMethods = "GET, POST, OPTIONS"
+	allowedHeaders = "Authorization, Content-Type, X-Request-ID, traceparent"
+	exposedHeaders = "X-Request-ID, Retry-After, X-Queue-Depth"
+)
+
+// Config is the origins allowed to call the checker from a browser, e.g. the
+// dashboard of a self-hosted OpenStatus.
+type Config struct {
+	// Origins are exact origins, e.g. "https://openstatus.dev", origins of
+	// any subdomain, e.g. "https://*.openstatus.dev", or "*" for any.
+	Origins []string
+	// MaxAge is how long the browsers cache the preflight responses.
+	MaxAge time.Duration
+}
+
+// allowed tells whether the origin is allowed.
+func (c Config) allowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.Origins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		// The wildcard only matches the subdomains, e.g. not the origin itself.
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			if rest, ok := strings.CutPrefix(origin, scheme+"://"); ok && strings.HasSuffix(rest, "."+domain) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Middleware answers the preflight requests of the allowed origins, and
+// allows them to read the responses of the other ones. The preflight
+// requests of the other origins are forbidden.
+func Middleware(config Config) gin.HandlerFunc {
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !config.allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", allowedMethods)
+			c.Header("Access-Control-Allow-Headers", allowedHeaders)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Header("Access-Control-Expose-Headers", exposedHeaders)
+		c.Next()
+	}
+}
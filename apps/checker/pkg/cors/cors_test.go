@@ -0,0 +1,75 @@
+package cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/cors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	router := gin.New()
+	router.Use(cors.Middleware(cors.Config{
+		Origins: []string{"https://openstatus.dev", "https://*.example.com"},
+		MaxAge:  10 * time.Minute,
+	}))
+	router.POST("/check/run", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodOptions, "/check/run", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		r.Header.Set("Access-Control-Request-Headers", "authorization,content-type")
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("it should answer the preflight of the allowed origins", func(t *testing.T) {
+		for _, origin := range []string{"https://openstatus.dev", "https://status.example.com"} {
+			w := preflight(origin)
+			require.Equal(t, http.StatusNoContent, w.Code, origin)
+			require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
+			require.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "POST")
+			require.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+			require.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+		}
+	})
+
+	t.Run("it should forbid the preflight of the other origins", func(t *testing.T) {
+		for _, origin := range []string{"https://evil.dev", "https://example.com", "http://status.example.com"} {
+			w := preflight(origin)
+			require.Equal(t, http.StatusForbidden, w.Code, origin)
+			require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		}
+	})
+
+	t.Run("it should allow the allowed origins to read the responses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/check/run", nil)
+		r.Header.Set("Origin", "https://openstatus.dev")
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "https://openstatus.dev", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+		require.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("it should not change the requests of the other clients", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/check/run", nil)
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
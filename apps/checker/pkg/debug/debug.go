@@ -0,0 +1,51 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// State returns the internal state of the checker, e.g. its queues, served
+// along with the runtime state.
+type State func() map[string]any
+
+// Register adds the pprof endpoints under /debug/pprof, and the dump of the
+// state of the checker at /debug/state. The router must be authenticated,
+// the profiles exposing the internals of the checker.
+func Register(router gin.IRouter, state State) {
+	profiles := router.Group("/debug/pprof")
+	profiles.GET("/", gin.WrapF(pprof.Index))
+	profiles.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	profiles.GET("/profile", gin.WrapF(pprof.Profile))
+	profiles.GET("/symbol", gin.WrapF(pprof.Symbol))
+	profiles.POST("/symbol", gin.WrapF(pprof.Symbol))
+	profiles.GET("/trace", gin.WrapF(pprof.Trace))
+	// The other profiles, e.g. goroutine or heap, are served by name.
+	profiles.GET("/:name", func(c *gin.Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+
+	router.GET("/debug/state", func(c *gin.Context) {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+
+		dump := gin.H{
+			"goroutines": runtime.NumGoroutine(),
+			"memory": gin.H{
+				"alloc":      m.Alloc,
+				"heapInuse":  m.HeapInuse,
+				"sys":        m.Sys,
+				"numGC":      m.NumGC,
+				"pauseTotal": m.PauseTotalNs,
+			},
+		}
+		for key, value := range state() {
+			dump[key] = value
+		}
+
+		c.JSON(http.StatusOK, dump)
+	})
+}
@@ -0,0 +1,53 @@
+package debug_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/debug"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	router := gin.New()
+	debug.Register(router, func() map[string]any {
+		return map[string]any{"queues": map[string]int{"normal": 3}}
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("it should serve the profiles", func(t *testing.T) {
+		w := serve("/debug/pprof/")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = serve("/debug/pprof/goroutine?debug=2")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "goroutine")
+
+		w = serve("/debug/pprof/unknown")
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("it should dump the state of the checker", func(t *testing.T) {
+		w := serve("/debug/state")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var state struct {
+			Goroutines int            `json:"goroutines"`
+			Queues     map[string]int `json:"queues"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+		require.Positive(t, state.Goroutines)
+		require.Equal(t, 3, state.Queues["normal"])
+	})
+}
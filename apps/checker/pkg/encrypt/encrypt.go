@@ -0,0 +1,87 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+)
+
+// Envelope is the event sent in place of the encrypted one, of its type,
+// if any, for the sinks to route it as the event.
+type Envelope struct {
+	Encryption string `json:"encryption"`
+	Type       string `json:"type,omitempty"`
+	Payload    string `json:"payload"`
+}
+
+func (e Envelope) EventType() string {
+	return e.Type
+}
+
+type encrypter struct {
+	next       sink.Sink
+	recipients []age.Recipient
+}
+
+// NewSink returns a sink encrypting the JSON encoding of every event with
+// age for the given recipients before forwarding it, as an armored Envelope,
+// to the next sink.
+func NewSink(next sink.Sink, recipients []age.Recipient) sink.Sink {
+	return encrypter{
+		next:       next,
+		recipients: recipients,
+	}
+}
+
+// ParseRecipients parses age X25519 recipients, e.g. "age1...".
+func ParseRecipients(values []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(values))
+	for _, value := range values {
+		recipient, err := age.ParseX25519Recipient(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", value, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+func (e encrypter) SendEvent(ctx context.Context, event any) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode event: %w", err)
+	}
+
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, e.recipients...)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt event: %w", err)
+	}
+	if _, err := io.Copy(w, bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("unable to encrypt event: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("unable to encrypt event: %w", err)
+	}
+	if err := armored.Close(); err != nil {
+		return fmt.Errorf("unable to encrypt event: %w", err)
+	}
+
+	envelope := Envelope{
+		Encryption: "age",
+		Payload:    buf.String(),
+	}
+	if typed, ok := event.(interface{ EventType() string }); ok {
+		envelope.Type = typed.EventType()
+	}
+
+	return e.next.SendEvent(ctx, envelope)
+}
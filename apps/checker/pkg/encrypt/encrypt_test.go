@@ -0,0 +1,74 @@
+package encrypt_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/encrypt"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestSendEvent(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	recipients, err := encrypt.ParseRecipients([]string{identity.Recipient().String()})
+	require.NoError(t, err)
+
+	r := &recorder{}
+	s := encrypt.NewSink(r, recipients)
+
+	require.NoError(t, s.SendEvent(context.Background(), map[string]string{"monitorId": "1"}))
+	require.Len(t, r.events, 1)
+
+	envelope := r.events[0].(encrypt.Envelope)
+	require.Equal(t, "age", envelope.Encryption)
+	require.NotContains(t, envelope.Payload, "monitorId")
+
+	plaintext, err := age.Decrypt(armor.NewReader(strings.NewReader(envelope.Payload)), identity)
+	require.NoError(t, err)
+	payload, err := io.ReadAll(plaintext)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"monitorId":"1"}`, string(payload))
+}
+
+type typedEvent struct{}
+
+func (typedEvent) EventType() string {
+	return "rollup"
+}
+
+func TestSendEventType(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	r := &recorder{}
+	s := encrypt.NewSink(r, []age.Recipient{identity.Recipient()})
+
+	require.NoError(t, s.SendEvent(context.Background(), typedEvent{}))
+	require.Equal(t, "rollup", r.events[0].(encrypt.Envelope).EventType())
+}
+
+func TestParseRecipients(t *testing.T) {
+	t.Parallel()
+
+	_, err := encrypt.ParseRecipients([]string{"not a recipient"})
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv"
+	}
+
+	return "application/x-ndjson"
+}
+
+type Encoder interface {
+	Encode(data checker.PingData) error
+	Flush() error
+}
+
+// NewEncoder returns an encoder writing the results to w in the format.
+func NewEncoder(format Format, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+var csvHeader = []string{"workspaceId", "monitorId", "timestamp", "cronTimestamp", "url", "region", "statusCode", "latency", "message"}
+
+type csvEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvEncoder) Encode(data checker.PingData) error {
+	if !e.headerWritten {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	return e.w.Write([]string{
+		data.WorkspaceID,
+		data.MonitorID,
+		strconv.FormatInt(data.Timestamp, 10),
+		strconv.FormatInt(data.CronTimestamp, 10),
+		data.URL,
+		data.Region,
+		strconv.Itoa(data.StatusCode),
+		strconv.FormatInt(data.Latency, 10),
+		data.Message,
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	// An empty export still gets its header.
+	if !e.headerWritten {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e ndjsonEncoder) Encode(data checker.PingData) error {
+	return e.enc.Encode(data)
+}
+
+func (e ndjsonEncoder) Flush() error {
+	return nil
+}
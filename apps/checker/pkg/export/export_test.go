@@ -0,0 +1,42 @@
+package export_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/export"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	t.Parallel()
+
+	data := checker.PingData{WorkspaceID: "1", MonitorID: "2", Timestamp: 3, URL: "https://openstat.us", Region: "ams", StatusCode: 200, Latency: 42, Message: "a, b"}
+
+	t.Run("it should encode the results as csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc, err := export.NewEncoder(export.FormatCSV, &buf)
+		require.NoError(t, err)
+
+		require.NoError(t, enc.Encode(data))
+		require.NoError(t, enc.Flush())
+		require.Equal(t, "workspaceId,monitorId,timestamp,cronTimestamp,url,region,statusCode,latency,message\n1,2,3,0,https://openstat.us,ams,200,42,\"a, b\"\n", buf.String())
+	})
+
+	t.Run("it should encode the results as ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc, err := export.NewEncoder(export.FormatNDJSON, &buf)
+		require.NoError(t, err)
+
+		require.NoError(t, enc.Encode(data))
+		require.NoError(t, enc.Encode(data))
+		require.NoError(t, enc.Flush())
+		require.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+	})
+
+	t.Run("it should reject unknown formats", func(t *testing.T) {
+		_, err := export.NewEncoder("xml", &bytes.Buffer{})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,101 @@
+package fanout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// RegionResult is the outcome of a check dispatched to a region.
+type RegionResult struct {
+	Region string            `json:"region"`
+	Result *checker.PingData `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+type Dispatcher interface {
+	Dispatch(ctx context.Context, req request.CheckerRequest, regions []string, authorization string) []RegionResult
+}
+
+type dispatcher struct {
+	httpClient *http.Client
+	checkerURL string
+}
+
+// NewDispatcher returns a dispatcher running the checks on the regional
+// checkers behind checkerURL, routed to each region with the fly-prefer-region
+// header.
+func NewDispatcher(httpClient *http.Client, checkerURL string) Dispatcher {
+	return dispatcher{
+		httpClient: httpClient,
+		checkerURL: checkerURL,
+	}
+}
+
+// Dispatch runs the check in every region in parallel and returns their
+// results in the order of the regions.
+func (d dispatcher) Dispatch(ctx context.Context, req request.CheckerRequest, regions []string, authorization string) []RegionResult {
+	results := make([]RegionResult, len(regions))
+
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+
+			results[i] = RegionResult{Region: region}
+			result, err := d.dispatch(ctx, req, region, authorization)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Result = &result
+		}(i, region)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (d dispatcher) dispatch(ctx context.Context, req request.CheckerRequest, region, authorization string) (checker.PingData, error) {
+	// The regions are picked by the caller, over the ones of the monitor.
+	req.Regions = nil
+
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(req); err != nil {
+		return checker.PingData{}, fmt.Errorf("unable to encode payload: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.checkerURL+"/checker", bytes.NewReader(payload.Bytes()))
+	if err != nil {
+		return checker.PingData{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", authorization)
+	httpReq.Header.Set("fly-prefer-region", region)
+
+	resp, err := d.httpClient.Do(httpReq)
+	if err != nil {
+		return checker.PingData{}, fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return checker.PingData{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Result checker.PingData `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return checker.PingData{}, fmt.Errorf("unable to decode response: %w", err)
+	}
+
+	return body.Result, nil
+}
@@ -0,0 +1,40 @@
+package fanout_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fanout"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		region := r.Header.Get("fly-prefer-region")
+		if region == "gru" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"message": "ok",
+			"result":  checker.PingData{Region: region, StatusCode: 200},
+		})
+	}))
+	defer server.Close()
+
+	d := fanout.NewDispatcher(server.Client(), server.URL)
+	results := d.Dispatch(context.Background(), request.CheckerRequest{MonitorID: "1"}, []string{"ams", "gru", "iad"}, "Basic secret")
+
+	require.Len(t, results, 3)
+	require.Equal(t, "ams", results[0].Result.Region)
+	require.Nil(t, results[1].Result)
+	require.Equal(t, "unexpected status code: 500", results[1].Error)
+	require.Equal(t, "iad", results[2].Result.Region)
+}
@@ -0,0 +1,85 @@
+package flap
+
+import "sync"
+
+// Streaks are the numbers of consecutive checks of a monitor at or above,
+// and at or below, each level.
+type Streaks struct {
+	above []int
+	below []int
+}
+
+// AtLeast returns the number of consecutive checks at or above the level.
+func (s Streaks) AtLeast(level int) int {
+	if level < 0 || level >= len(s.above) {
+		return 0
+	}
+	return s.above[level]
+}
+
+// AtMost returns the number of consecutive checks at or below the level.
+func (s Streaks) AtMost(level int) int {
+	if level < 0 || level >= len(s.below) {
+		return 0
+	}
+	return s.below[level]
+}
+
+// Detector tracks the consecutive results of the monitors, so their status
+// only changes once a result is confirmed by the following ones.
+type Detector struct {
+	mu       sync.Mutex
+	streaks  map[string]*Streaks
+	statuses map[string]string
+}
+
+func NewDetector() *Detector {
+	return &Detector{
+		streaks:  map[string]*Streaks{},
+		statuses: map[string]string{},
+	}
+}
+
+// ObserveLevel records the level of a check, e.g. 0 for active, 1 for
+// degraded and 2 for error out of the levels, and returns the streaks of the
+// monitor, this check included.
+func (d *Detector) ObserveLevel(monitorID string, level, levels int) Streaks {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.streaks[monitorID]
+	if !ok || len(s.above) != levels {
+		s = &Streaks{above: make([]int, levels), below: make([]int, levels)}
+		d.streaks[monitorID] = s
+	}
+	for l := 0; l < levels; l++ {
+		if level >= l {
+			s.above[l]++
+		} else {
+			s.above[l] = 0
+		}
+		if level <= l {
+			s.below[l]++
+		} else {
+			s.below[l] = 0
+		}
+	}
+
+	return Streaks{above: append([]int(nil), s.above...), below: append([]int(nil), s.below...)}
+}
+
+// SetStatus records the status the monitor transitioned to.
+func (d *Detector) SetStatus(monitorID, status string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.statuses[monitorID] = status
+}
+
+// Status returns the last status the monitor transitioned to, if known.
+func (d *Detector) Status(monitorID string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.statuses[monitorID]
+}
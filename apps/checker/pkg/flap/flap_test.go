@@ -0,0 +1,39 @@
+package flap_test
+
+import (
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/flap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	d := flap.NewDetector()
+
+	require.Empty(t, d.Status("1"))
+	d.SetStatus("1", "error")
+	require.Equal(t, "error", d.Status("1"))
+	require.Empty(t, d.Status("2"))
+}
+
+func TestObserveLevel(t *testing.T) {
+	t.Parallel()
+
+	d := flap.NewDetector()
+	d.ObserveLevel("1", 2, 3)
+	s := d.ObserveLevel("1", 1, 3)
+	require.Equal(t, 2, s.AtLeast(1), "the worse levels count toward the better ones")
+	require.Equal(t, 0, s.AtLeast(2))
+	require.Equal(t, 1, s.AtMost(1))
+
+	s = d.ObserveLevel("1", 0, 3)
+	require.Equal(t, 0, s.AtLeast(1))
+	require.Equal(t, 2, s.AtMost(1))
+	require.Equal(t, 1, s.AtMost(0))
+	require.Equal(t, 0, s.AtLeast(3), "the unknown levels have no streak")
+
+	s = d.ObserveLevel("2", 2, 3)
+	require.Equal(t, 1, s.AtLeast(2), "the monitors are tracked separately")
+}
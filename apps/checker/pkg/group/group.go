@@ -0,0 +1,153 @@
+package group
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// StatusUp is the status of a group whose monitors are all up.
+	StatusUp = "up"
+	// StatusPartial is the status of a group with monitors up and down.
+	StatusPartial = "partial"
+	// StatusDown is the status of a group whose monitors are all down.
+	StatusDown = "down"
+)
+
+// Status is the status of a group, computed from the last result of each of
+// its monitors. The monitors without result yet are not counted.
+type Status struct {
+	Group     string `json:"group"`
+	Status    string `json:"status"`
+	Up        int    `json:"up"`
+	Down      int    `json:"down"`
+	Total     int    `json:"total"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func (Status) EventType() string {
+	return "group"
+}
+
+// Tracker is a sink computing the status of the monitor groups. A group
+// status is sent to the next sink, after the result changing it.
+type Tracker struct {
+	next   sink.Sink
+	groups map[string][]string
+	// memberships are the groups of each monitor.
+	memberships map[string][]string
+
+	mu       sync.Mutex
+	up       map[string]bool
+	statuses map[string]Status
+}
+
+// New returns a tracker of the groups, each holding its monitor ids.
+func New(next sink.Sink, groups map[string][]string) *Tracker {
+	memberships := map[string][]string{}
+	for name, monitorIDs := range groups {
+		for _, monitorID := range monitorIDs {
+			memberships[monitorID] = append(memberships[monitorID], name)
+		}
+	}
+
+	return &Tracker{
+		next:        next,
+		groups:      groups,
+		memberships: memberships,
+		up:          map[string]bool{},
+		statuses:    map[string]Status{},
+	}
+}
+
+func (t *Tracker) SendEvent(ctx context.Context, event any) error {
+	err := t.next.SendEvent(ctx, event)
+
+	data, ok := event.(checker.PingData)
+	if !ok || data.Paused || data.Throttled {
+		return err
+	}
+
+	for _, status := range t.update(data) {
+		if err := t.next.SendEvent(ctx, status); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("group", status.Group).Msg("failed to send group status")
+		}
+	}
+
+	return err
+}
+
+// update records the result and returns the statuses of the groups it
+// changed.
+func (t *Tracker) update(data checker.PingData) []Status {
+	groups, ok := t.memberships[data.MonitorID]
+	if !ok {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.up[data.MonitorID] = data.StatusCode >= 200 && data.StatusCode < 300
+
+	var changed []Status
+	for _, name := range groups {
+		status := t.compute(name)
+		if previous, ok := t.statuses[name]; ok && previous.Status == status.Status && previous.Up == status.Up && previous.Down == status.Down {
+			continue
+		}
+		t.statuses[name] = status
+		changed = append(changed, status)
+	}
+
+	return changed
+}
+
+func (t *Tracker) compute(name string) Status {
+	status := Status{Group: name, Timestamp: time.Now().UTC().UnixMilli()}
+	for _, monitorID := range t.groups[name] {
+		up, ok := t.up[monitorID]
+		if !ok {
+			continue
+		}
+		status.Total++
+		if up {
+			status.Up++
+		} else {
+			status.Down++
+		}
+	}
+
+	switch {
+	case status.Down == 0:
+		status.Status = StatusUp
+	case status.Up == 0:
+		status.Status = StatusDown
+	default:
+		status.Status = StatusPartial
+	}
+
+	return status
+}
+
+// Statuses returns the status of the groups with results, sorted by name.
+func (t *Tracker) Statuses() []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	statuses := make([]Status, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Group < statuses[j].Group
+	})
+
+	return statuses
+}
@@ -0,0 +1,67 @@
+package group_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/group"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) statuses() []group.Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var statuses []group.Status
+	for _, event := range r.events {
+		if status, ok := event.(group.Status); ok {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := &recorder{}
+	tracker := group.New(r, map[string][]string{"api": {"1", "2"}})
+
+	send := func(monitorID string, statusCode int) {
+		require.NoError(t, tracker.SendEvent(ctx, checker.PingData{MonitorID: monitorID, StatusCode: statusCode}))
+	}
+
+	send("1", 200)
+	send("2", 200)
+	send("2", 200)
+	send("2", 500)
+	send("1", 500)
+	send("3", 500)
+
+	statuses := r.statuses()
+	require.Len(t, statuses, 4, "the statuses should only be sent when they change")
+	require.Equal(t, group.StatusUp, statuses[0].Status)
+	require.Equal(t, 1, statuses[0].Total)
+	require.Equal(t, group.StatusUp, statuses[1].Status)
+	require.Equal(t, 2, statuses[1].Total)
+	require.Equal(t, group.StatusPartial, statuses[2].Status)
+	require.Equal(t, group.StatusDown, statuses[3].Status)
+
+	require.Len(t, r.events, 10, "the results should be forwarded")
+	require.Equal(t, []group.Status{statuses[3]}, tracker.Statuses())
+}
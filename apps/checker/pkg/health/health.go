@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status is the health of the checker, or of one of its dependencies.
+type Status string
+
+const (
+	OK       Status = "ok"
+	Degraded Status = "degraded"
+	Down     Status = "down"
+)
+
+// Dependency is a dependency of the checker, verified by its check.
+type Dependency struct {
+	Name string
+	// Critical dependencies take the checker down when failing, the others
+	// only degrade it.
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// Result is the outcome of the check of a dependency.
+type Result struct {
+	Status Status `json:"status"`
+	// Latency of the check, in milliseconds.
+	Latency int64  `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the health of the checker, along with the results of its
+// dependencies, by name.
+type Report struct {
+	Status       Status            `json:"status"`
+	Dependencies map[string]Result `json:"dependencies"`
+}
+
+// Checker verifies the dependencies of the checker.
+type Checker struct {
+	timeout      time.Duration
+	dependencies []Dependency
+}
+
+// New returns a checker verifying the dependencies concurrently, each within
+// the timeout.
+func New(timeout time.Duration, dependencies ...Dependency) *Checker {
+	return &Checker{
+		timeout:      timeout,
+		dependencies: dependencies,
+	}
+}
+
+// Check verifies the dependencies and returns the report of their health.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: OK, Dependencies: make(map[string]Result, len(c.dependencies))}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, d := range c.dependencies {
+		wg.Add(1)
+		go func(d Dependency) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := d.Check(ctx)
+			result := Result{Status: OK, Latency: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status, result.Error = Down, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Dependencies[d.Name] = result
+			switch {
+			case err == nil:
+			case d.Critical:
+				report.Status = Down
+			case report.Status == OK:
+				report.Status = Degraded
+			}
+		}(d)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// HTTP returns the check of a dependency reachable at the url: any response
+// but a server error means it is up, e.g. a method not allowed by an API.
+func HTTP(client *http.Client, url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return fmt.Errorf("unable to create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("unable to reach %s: %w", req.URL.Host, err)
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// Backlog returns the check of a queue, failing once the queued items reach
+// the maximum.
+func Backlog(queued func() int, max int) func(ctx context.Context) error {
+	return func(context.Context) error {
+		if n := queued(); max > 0 && n >= max {
+			return fmt.Errorf("%d items queued, over %d", n, max)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/health"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	up := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("unreachable") }
+
+	t.Run("it should be ok when every dependency is", func(t *testing.T) {
+		report := health.New(time.Second, health.Dependency{Name: "tinybird", Check: up}).Check(context.Background())
+		require.Equal(t, health.OK, report.Status)
+		require.Equal(t, health.OK, report.Dependencies["tinybird"].Status)
+	})
+
+	t.Run("it should be degraded when a dependency fails", func(t *testing.T) {
+		report := health.New(time.Second,
+			health.Dependency{Name: "tinybird", Check: failing},
+			health.Dependency{Name: "queue", Critical: true, Check: up},
+		).Check(context.Background())
+		require.Equal(t, health.Degraded, report.Status)
+		require.Equal(t, health.Down, report.Dependencies["tinybird"].Status)
+		require.Equal(t, "unreachable", report.Dependencies["tinybird"].Error)
+	})
+
+	t.Run("it should be down when a critical dependency fails", func(t *testing.T) {
+		report := health.New(time.Second,
+			health.Dependency{Name: "tinybird", Check: failing},
+			health.Dependency{Name: "queue", Critical: true, Check: failing},
+		).Check(context.Background())
+		require.Equal(t, health.Down, report.Status)
+	})
+
+	t.Run("it should time out the checks", func(t *testing.T) {
+		hanging := func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		report := health.New(10*time.Millisecond, health.Dependency{Name: "status_api", Check: hanging}).Check(context.Background())
+		require.Equal(t, health.Degraded, report.Status)
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusMethodNotAllowed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	t.Run("it should be up when the dependency answers", func(t *testing.T) {
+		require.NoError(t, health.HTTP(server.Client(), server.URL)(context.Background()))
+	})
+
+	t.Run("it should be down on the server errors", func(t *testing.T) {
+		status = http.StatusBadGateway
+		require.Error(t, health.HTTP(server.Client(), server.URL)(context.Background()))
+	})
+}
+
+func TestBacklog(t *testing.T) {
+	t.Parallel()
+
+	queued := 3
+	check := health.Backlog(func() int { return queued }, 4)
+	require.NoError(t, check(context.Background()))
+
+	queued = 4
+	require.Error(t, check(context.Background()))
+
+	require.NoError(t, health.Backlog(func() int { return 100 }, 0)(context.Background()))
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	r := health.NewReadiness()
+	require.NoError(t, r.Ready())
+
+	secrets := r.Pending("cron_secrets")
+	policy := r.Pending("policy")
+	require.EqualError(t, r.Ready(), "waiting for cron_secrets, policy")
+
+	secrets()
+	policy()
+	require.NoError(t, r.Ready())
+
+	r.Drain()
+	require.ErrorIs(t, r.Ready(), health.ErrDraining)
+	require.True(t, r.Draining())
+}
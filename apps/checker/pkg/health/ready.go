@@ -0,0 +1,76 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrDraining is the error of the readiness of a checker shutting down.
+var ErrDraining = errors.New("draining")
+
+// Readiness tracks the conditions a checker waits for before receiving
+// traffic, e.g. its configuration being loaded, and stops it once draining.
+type Readiness struct {
+	mu       sync.Mutex
+	pending  map[string]struct{}
+	draining bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{pending: map[string]struct{}{}}
+}
+
+// Pending adds a condition of the readiness, met once done is called.
+func (r *Readiness) Pending(name string) (done func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending[name] = struct{}{}
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		delete(r.pending, name)
+	}
+}
+
+// Drain makes the checker not ready for good.
+func (r *Readiness) Drain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.draining = true
+}
+
+// Draining reports whether the checker is draining.
+func (r *Readiness) Draining() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.draining
+}
+
+// Ready returns ErrDraining when the checker is draining, or an error
+// listing the conditions not met yet.
+func (r *Readiness) Ready() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.draining {
+		return ErrDraining
+	}
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.pending))
+	for name := range r.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("waiting for %s", strings.Join(names, ", "))
+}
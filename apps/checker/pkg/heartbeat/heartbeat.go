@@ -0,0 +1,245 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/rs/zerolog/log"
+)
+
+// Monitor expects a heartbeat, a request to /heartbeat/:token, every period.
+// It is late once the period and the grace period passed without heartbeat.
+type Monitor struct {
+	WorkspaceID string `json:"workspaceId"`
+	MonitorID   string `json:"monitorId"`
+	Token       string `json:"token"`
+	Period      string `json:"period"`
+	Grace       string `json:"grace,omitempty"`
+
+	deadline time.Duration
+}
+
+// Event is sent when a heartbeat is late, and when it recovers.
+type Event struct {
+	WorkspaceID string `json:"workspaceId"`
+	MonitorID   string `json:"monitorId"`
+	Status      string `json:"status"`
+	LastSeen    int64  `json:"lastSeen"`
+	Timestamp   int64  `json:"timestamp"`
+	Region      string `json:"region"`
+}
+
+func (Event) EventType() string {
+	return "heartbeat"
+}
+
+type state struct {
+	monitor  Monitor
+	lastSeen time.Time
+	late     bool
+}
+
+// Tracker tracks the heartbeats of the monitors, flipping them to error when
+// late and back to active with their next heartbeat.
+type Tracker struct {
+	sink     sink.Sink
+	updater  checker.StatusUpdater
+	notifier notify.Notifier
+	region   string
+
+	mu     sync.Mutex
+	states map[string]*state
+	tokens map[string]string
+}
+
+// NewTracker returns a tracker sending its changes to the sink, updating the
+// status of the monitors with the updater and notifying them to the notifier, if
+// any.
+func NewTracker(eventSink sink.Sink, updater checker.StatusUpdater, notifier notify.Notifier, region string) *Tracker {
+	return &Tracker{
+		sink:     eventSink,
+		updater:  updater,
+		notifier: notifier,
+		region:   region,
+		states:   map[string]*state{},
+		tokens:   map[string]string{},
+	}
+}
+
+// Set replaces the monitors. The new ones are given a full period from now.
+func (t *Tracker) Set(monitors []Monitor) error {
+	for i, m := range monitors {
+		period, err := time.ParseDuration(m.Period)
+		if err != nil || period <= 0 {
+			return fmt.Errorf("invalid period %q of monitor %s", m.Period, m.MonitorID)
+		}
+		var grace time.Duration
+		if m.Grace != "" {
+			if grace, err = time.ParseDuration(m.Grace); err != nil {
+				return fmt.Errorf("invalid grace %q of monitor %s: %w", m.Grace, m.MonitorID, err)
+			}
+		}
+		if m.Token == "" {
+			return fmt.Errorf("missing token of monitor %s", m.MonitorID)
+		}
+		monitors[i].deadline = period + grace
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	states := map[string]*state{}
+	tokens := map[string]string{}
+	for _, m := range monitors {
+		s, ok := t.states[m.MonitorID]
+		if !ok {
+			s = &state{lastSeen: time.Now()}
+		}
+		s.monitor = m
+		states[m.MonitorID] = s
+		tokens[m.Token] = m.MonitorID
+	}
+	t.states = states
+	t.tokens = tokens
+
+	return nil
+}
+
+// Load replaces the monitors with the ones of a JSON file containing an
+// array of monitors.
+func (t *Tracker) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read heartbeat monitors: %w", err)
+	}
+
+	var monitors []Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		return fmt.Errorf("unable to decode heartbeat monitors: %w", err)
+	}
+
+	return t.Set(monitors)
+}
+
+// Beat records the heartbeat of the monitor of the token. It returns false
+// for the unknown tokens.
+func (t *Tracker) Beat(ctx context.Context, token string) bool {
+	t.mu.Lock()
+	monitorID, ok := t.tokens[token]
+	if !ok {
+		t.mu.Unlock()
+		return false
+	}
+	s := t.states[monitorID]
+	s.lastSeen = time.Now()
+	recovered := s.late
+	s.late = false
+	event := t.event(s, "active")
+	t.mu.Unlock()
+
+	if recovered {
+		t.notify(ctx, event)
+	}
+
+	return true
+}
+
+// Check flips the late monitors to error.
+func (t *Tracker) Check(ctx context.Context, now time.Time) {
+	t.mu.Lock()
+	var events []Event
+	for _, s := range t.states {
+		if s.late || now.Sub(s.lastSeen) <= s.monitor.deadline {
+			continue
+		}
+		s.late = true
+		events = append(events, t.event(s, "error"))
+	}
+	t.mu.Unlock()
+
+	for _, event := range events {
+		t.notify(ctx, event)
+	}
+}
+
+func (t *Tracker) event(s *state, status string) Event {
+	return Event{
+		WorkspaceID: s.monitor.WorkspaceID,
+		MonitorID:   s.monitor.MonitorID,
+		Status:      status,
+		LastSeen:    s.lastSeen.UTC().UnixMilli(),
+		Timestamp:   time.Now().UTC().UnixMilli(),
+		Region:      t.region,
+	}
+}
+
+func (t *Tracker) notify(ctx context.Context, event Event) {
+	if err := t.sink.SendEvent(ctx, event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send heartbeat event")
+	}
+
+	data := checker.UpdateData{
+		MonitorId: event.MonitorID,
+		Status:    event.Status,
+		Region:    event.Region,
+	}
+	if event.Status == "error" {
+		data.Message = fmt.Sprintf("No heartbeat since %s", time.UnixMilli(event.LastSeen).UTC().Format(time.RFC3339))
+	}
+	if err := t.updater.UpdateStatus(ctx, data); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("monitor", data.MonitorId).Msg("error while updating status")
+	}
+
+	if t.notifier == nil {
+		return
+	}
+	previous := "active"
+	if event.Status == "active" {
+		previous = "error"
+	}
+	err := t.notifier.Notify(ctx, notify.Notification{
+		WorkspaceID: event.WorkspaceID,
+		MonitorID:   event.MonitorID,
+		Region:      event.Region,
+		Status:      event.Status,
+		Previous:    previous,
+		Message:     data.Message,
+		Timestamp:   event.Timestamp,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("monitor", event.MonitorID).Msg("failed to notify the heartbeat")
+	}
+}
+
+// Run reloads the monitors from the file every refresh, and checks the late
+// ones every interval, until the context is done.
+func (t *Tracker) Run(ctx context.Context, path string, refresh, interval time.Duration) {
+	if err := t.Load(path); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load heartbeat monitors")
+	}
+
+	reload := time.NewTicker(refresh)
+	defer reload.Stop()
+	check := time.NewTicker(interval)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload.C:
+			if err := t.Load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload heartbeat monitors")
+			}
+		case now := <-check.C:
+			t.Check(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package heartbeat_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/heartbeat"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	events  []any
+	updates []checker.UpdateData
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) UpdateStatus(ctx context.Context, data checker.UpdateData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, data)
+	return nil
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := &recorder{}
+	tracker := heartbeat.NewTracker(r, r, nil, "ams")
+	require.NoError(t, tracker.Set([]heartbeat.Monitor{{MonitorID: "1", Token: "secret", Period: "1m", Grace: "30s"}}))
+
+	t.Run("it should reject the unknown tokens", func(t *testing.T) {
+		require.False(t, tracker.Beat(ctx, "unknown"))
+	})
+
+	t.Run("it should wait for the grace period", func(t *testing.T) {
+		tracker.Check(ctx, time.Now().Add(80*time.Second))
+		require.Empty(t, r.updates)
+	})
+
+	t.Run("it should flip the late monitors to error once", func(t *testing.T) {
+		tracker.Check(ctx, time.Now().Add(2*time.Minute))
+		tracker.Check(ctx, time.Now().Add(3*time.Minute))
+
+		require.Len(t, r.updates, 1)
+		require.Equal(t, "error", r.updates[0].Status)
+		require.Equal(t, "ams", r.updates[0].Region)
+		require.Len(t, r.events, 1)
+		require.Equal(t, "error", r.events[0].(heartbeat.Event).Status)
+	})
+
+	t.Run("it should recover with the next heartbeat", func(t *testing.T) {
+		require.True(t, tracker.Beat(ctx, "secret"))
+		require.True(t, tracker.Beat(ctx, "secret"))
+
+		require.Len(t, r.updates, 2)
+		require.Equal(t, "active", r.updates[1].Status)
+	})
+
+	t.Run("it should reject invalid monitors", func(t *testing.T) {
+		require.Error(t, tracker.Set([]heartbeat.Monitor{{MonitorID: "2", Token: "t", Period: "soon"}}))
+		require.Error(t, tracker.Set([]heartbeat.Monitor{{MonitorID: "2", Period: "1m"}}))
+	})
+}
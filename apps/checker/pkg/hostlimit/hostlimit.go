@@ -0,0 +1,159 @@
+package hostlimit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the delay between two evictions of the idle hosts.
+const sweepInterval = time.Minute
+
+type host struct {
+	slots chan struct{}
+	// next is the earliest time of the next request to the host.
+	next time.Time
+	// requests is the number of requests to the host, waiting or running.
+	requests int
+}
+
+type transport struct {
+	next        http.RoundTripper
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*host
+	swept time.Time
+}
+
+// NewTransport returns a transport running at most concurrency requests, and
+// rps requests per second, against the same host, whatever the monitor. The
+// requests over the limits wait for their turn. A limit of 0 disables it.
+func NewTransport(next http.RoundTripper, concurrency int, rps float64) http.RoundTripper {
+	t := &transport{
+		next:        next,
+		concurrency: concurrency,
+		hosts:       map[string]*host{},
+	}
+	if rps > 0 {
+		t.interval = time.Duration(float64(time.Second) / rps)
+	}
+
+	return t
+}
+
+// acquire returns the host of a new request, evicting the idle hosts once in
+// a while: the checker targets many hosts over its life.
+func (t *transport) acquire(name string) *host {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.swept) >= sweepInterval {
+		t.swept = now
+		for name, h := range t.hosts {
+			if h.requests == 0 && !h.next.After(now) {
+				delete(t.hosts, name)
+			}
+		}
+	}
+
+	h, ok := t.hosts[name]
+	if !ok {
+		h = &host{}
+		if t.concurrency > 0 {
+			h.slots = make(chan struct{}, t.concurrency)
+		}
+		t.hosts[name] = h
+	}
+	h.requests++
+
+	return h
+}
+
+// done records the end of a request to the host.
+func (t *transport) done(h *host) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h.requests--
+}
+
+// delay reserves the next request slot of the host and returns the time to
+// wait for it.
+func (t *transport) delay(h *host) time.Duration {
+	if t.interval == 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if h.next.Before(now) {
+		h.next = now
+	}
+	wait := h.next.Sub(now)
+	h.next = h.next.Add(t.interval)
+
+	return wait
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	h := t.acquire(req.URL.Host)
+
+	slot := false
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			if slot {
+				<-h.slots
+			}
+			t.done(h)
+		})
+	}
+	if h.slots != nil {
+		select {
+		case <-ctx.Done():
+			release()
+			return nil, fmt.Errorf("unable to wait for %s: %w", req.URL.Host, ctx.Err())
+		case h.slots <- struct{}{}:
+			slot = true
+		}
+	}
+
+	if wait := t.delay(h); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, fmt.Errorf("unable to wait for %s: %w", req.URL.Host, ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The request holds its slot until its body is closed.
+	res.Body = body{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+type body struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b body) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
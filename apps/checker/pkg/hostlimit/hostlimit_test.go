@@ -0,0 +1,91 @@
+package hostlimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/hostlimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should limit the concurrent requests to a host", func(t *testing.T) {
+		var current, peak atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := current.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			current.Add(-1)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: hostlimit.NewTransport(http.DefaultTransport, 2, 0)}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := client.Get(server.URL)
+				require.NoError(t, err)
+				res.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("it should limit the rate of requests to a host", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		client := &http.Client{Transport: hostlimit.NewTransport(http.DefaultTransport, 0, 50)}
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			res, err := client.Get(server.URL)
+			require.NoError(t, err)
+			res.Body.Close()
+		}
+
+		// The first request is immediate, the next ones 20ms apart.
+		require.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	})
+
+	t.Run("it should give up when the context is done", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client := &http.Client{Transport: hostlimit.NewTransport(http.DefaultTransport, 1, 0)}
+		go func() {
+			if res, err := client.Get(server.URL); err == nil {
+				res.Body.Close()
+			}
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		_, err = client.Do(req)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
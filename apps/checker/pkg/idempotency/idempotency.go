@@ -0,0 +1,198 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Header is the header of the key given by the callers.
+const Header = "Idempotency-Key"
+
+// ErrInProgress is returned to the duplicates of a request not answered yet.
+var ErrInProgress = errors.New("request already in progress")
+
+// Response is the response recorded for a key, replayed to the duplicates.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// Store records the keys of the requests, and their response once answered.
+type Store interface {
+	// Claim claims the key. It reports false when the key was already
+	// claimed, along with its response once recorded.
+	Claim(ctx context.Context, key string) (*Response, bool, error)
+	// Complete records the response of a claimed key.
+	Complete(ctx context.Context, key string, r Response) error
+	// Release releases a claimed key, e.g. for a request which failed to be
+	// retried.
+	Release(ctx context.Context, key string) error
+}
+
+type entry struct {
+	response  *Response
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]entry
+	pruned  time.Time
+}
+
+// NewMemoryStore returns the store of a single checker, the keys expiring
+// after the ttl.
+func NewMemoryStore(ttl time.Duration) Store {
+	return &memoryStore{ttl: ttl, entries: map[string]entry{}, pruned: time.Now()}
+}
+
+func (m *memoryStore) Claim(ctx context.Context, key string) (*Response, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.pruned) > m.ttl {
+		for k, e := range m.entries {
+			if now.After(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.pruned = now
+	}
+
+	if e, ok := m.entries[key]; ok && now.Before(e.expiresAt) {
+		return e.response, false, nil
+	}
+	m.entries[key] = entry{expiresAt: now.Add(m.ttl)}
+
+	return nil, true, nil
+}
+
+func (m *memoryStore) Complete(ctx context.Context, key string, r Response) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = entry{response: &r, expiresAt: time.Now().Add(m.ttl)}
+	return nil
+}
+
+func (m *memoryStore) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+// recorder records the response written to the client.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Begin claims the key of the request. It answers the duplicates, replaying
+// the response of the completed ones, and returns false for them. Otherwise,
+// the returned function, called once the request is answered, records its
+// response for the key, or releases the key of a failed one for it to be
+// retried.
+func Begin(c *gin.Context, store Store, key string) (func(), bool) {
+	ctx := c.Request.Context()
+	response, claimed, err := store.Claim(ctx, key)
+	if err != nil {
+		// Rather a duplicate than a missed check.
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("unable to claim the idempotency key, running the request")
+		return func() {}, true
+	}
+
+	if !claimed {
+		if response == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": ErrInProgress.Error()})
+			return nil, false
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(response.Status, response.ContentType, response.Body)
+		return nil, false
+	}
+
+	rec := &recorder{ResponseWriter: c.Writer}
+	c.Writer = rec
+	return func() {
+		// The key is recorded even when the caller is gone.
+		ctx := context.WithoutCancel(ctx)
+		// Nothing is written when the handler panicked.
+		status := rec.Status()
+		if !rec.Written() || status < 200 || status >= 300 {
+			if err := store.Release(ctx, key); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("unable to release the idempotency key")
+			}
+			return
+		}
+
+		if err := store.Complete(ctx, key, Response{
+			Status:      status,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		}); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("unable to record the idempotent response")
+		}
+	}, true
+}
+
+// Run runs fn once for the key, e.g. for the gRPC calls, and returns its
+// response, the duplicates getting the response of the completed one, or
+// ErrInProgress. The key of a failed fn is released for it to be retried.
+func Run(ctx context.Context, store Store, key string, fn func() (Response, error)) (Response, error) {
+	response, claimed, err := store.Claim(ctx, key)
+	if err != nil {
+		// Rather a duplicate than a missed check.
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("unable to claim the idempotency key, running the request")
+		return fn()
+	}
+	if !claimed {
+		if response == nil {
+			return Response{}, ErrInProgress
+		}
+		return *response, nil
+	}
+
+	// The key is recorded even when the caller is gone, and released when fn
+	// panicked.
+	done := false
+	defer func() {
+		if !done {
+			if err := store.Release(context.WithoutCancel(ctx), key); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("unable to release the idempotency key")
+			}
+		}
+	}()
+	r, err := fn()
+	if err != nil {
+		return r, err
+	}
+	done = true
+	if err := store.Complete(context.WithoutCancel(ctx), key, r); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("unable to record the idempotent response")
+	}
+
+	return r, nil
+}
@@ -0,0 +1,144 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/idempotency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("it should claim a key once", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(time.Minute)
+
+		_, claimed, err := store.Claim(ctx, "1")
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		response, claimed, err := store.Claim(ctx, "1")
+		require.NoError(t, err)
+		require.False(t, claimed)
+		require.Nil(t, response, "the key should be in progress")
+
+		require.NoError(t, store.Complete(ctx, "1", idempotency.Response{Status: http.StatusOK}))
+		response, claimed, _ = store.Claim(ctx, "1")
+		require.False(t, claimed)
+		require.Equal(t, http.StatusOK, response.Status)
+	})
+
+	t.Run("it should claim the released and expired keys again", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(10 * time.Millisecond)
+
+		_, _, _ = store.Claim(ctx, "1")
+		require.NoError(t, store.Release(ctx, "1"))
+		_, claimed, _ := store.Claim(ctx, "1")
+		require.True(t, claimed)
+
+		time.Sleep(20 * time.Millisecond)
+		_, claimed, _ = store.Claim(ctx, "1")
+		require.True(t, claimed)
+	})
+}
+
+func TestBegin(t *testing.T) {
+	t.Parallel()
+
+	store := idempotency.NewMemoryStore(time.Minute)
+	var runs int
+	status := http.StatusOK
+	router := gin.New()
+	router.POST("/checker", func(c *gin.Context) {
+		done, ok := idempotency.Begin(c, store, c.GetHeader(idempotency.Header))
+		if !ok {
+			return
+		}
+		defer done()
+
+		runs++
+		c.JSON(status, gin.H{"run": runs})
+	})
+
+	serve := func(key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/checker", nil)
+		r.Header.Set(idempotency.Header, key)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("it should replay the response of the duplicates", func(t *testing.T) {
+		first := serve("a")
+		require.Equal(t, http.StatusOK, first.Code)
+
+		second := serve("a")
+		require.Equal(t, http.StatusOK, second.Code)
+		require.Equal(t, first.Body.String(), second.Body.String())
+		require.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+		require.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+		require.Equal(t, 1, runs)
+	})
+
+	t.Run("it should run the failed requests again", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		require.Equal(t, http.StatusServiceUnavailable, serve("b").Code)
+
+		status = http.StatusOK
+		require.Equal(t, http.StatusOK, serve("b").Code)
+		require.Equal(t, 3, runs)
+	})
+
+	t.Run("it should reject the duplicates in progress", func(t *testing.T) {
+		_, _, _ = store.Claim(context.Background(), "c")
+		require.Equal(t, http.StatusConflict, serve("c").Code)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore(time.Minute)
+
+	t.Run("it should replay the response of the duplicates", func(t *testing.T) {
+		calls := 0
+		fn := func() (idempotency.Response, error) {
+			calls++
+			return idempotency.Response{Status: http.StatusOK, Body: []byte("ok")}, nil
+		}
+
+		_, err := idempotency.Run(ctx, store, "a", fn)
+		require.NoError(t, err)
+		response, err := idempotency.Run(ctx, store, "a", fn)
+		require.NoError(t, err)
+		require.Equal(t, []byte("ok"), response.Body)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("it should run the failed requests again", func(t *testing.T) {
+		_, err := idempotency.Run(ctx, store, "b", func() (idempotency.Response, error) {
+			return idempotency.Response{}, errors.New("overloaded")
+		})
+		require.Error(t, err)
+
+		_, claimed, _ := store.Claim(ctx, "b")
+		require.True(t, claimed)
+	})
+
+	t.Run("it should reject the duplicates in progress", func(t *testing.T) {
+		_, _, _ = store.Claim(ctx, "c")
+		_, err := idempotency.Run(ctx, store, "c", func() (idempotency.Response, error) {
+			return idempotency.Response{}, nil
+		})
+		require.ErrorIs(t, err, idempotency.ErrInProgress)
+	})
+}
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsStore struct {
+	kv           nats.KeyValue
+	claimTimeout time.Duration
+}
+
+// NewNATSStore returns a store shared by the checkers through a NATS
+// JetStream key-value bucket on the connection, created when missing. The
+// keys expire after the ttl, the one of an existing bucket being updated
+// when it differs. A key claimed for longer than the claim timeout, e.g. by
+// a checker which crashed during the request, is claimed again.
+func NewNATSStore(nc *nats.Conn, bucket string, ttl, claimTimeout time.Duration) (Store, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create jetstream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, TTL: ttl})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bucket %s: %w", bucket, err)
+	}
+
+	status, err := kv.Status()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get bucket %s: %w", bucket, err)
+	}
+	if status.TTL() != ttl {
+		// The bucket is the stream KV_<bucket>, its max age being the ttl.
+		info, err := js.StreamInfo("KV_" + bucket)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get bucket %s: %w", bucket, err)
+		}
+		config := info.Config
+		config.MaxAge = ttl
+		if _, err := js.UpdateStream(&config); err != nil {
+			return nil, fmt.Errorf("unable to update ttl of bucket %s: %w", bucket, err)
+		}
+	}
+
+	return natsStore{kv: kv, claimTimeout: claimTimeout}, nil
+}
+
+// hash returns the key of the bucket, the keys given by the callers possibly
+// holding characters not allowed by NATS.
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Claim creates the key, which fails when another checker already did. The
+// claim of a key left without response for longer than the claim timeout is
+// replaced, only one of the checkers replacing the same revision.
+func (n natsStore) Claim(ctx context.Context, key string) (*Response, bool, error) {
+	_, err := n.kv.Create(hash(key), nil)
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, nats.ErrKeyExists) {
+		return nil, false, fmt.Errorf("unable to claim key: %w", err)
+	}
+
+	entry, err := n.kv.Get(hash(key))
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to get key: %w", err)
+	}
+	if len(entry.Value()) == 0 {
+		if n.claimTimeout <= 0 || time.Since(entry.Created()) < n.claimTimeout {
+			return nil, false, nil
+		}
+		_, err := n.kv.Update(hash(key), nil, entry.Revision())
+		if errors.Is(err, nats.ErrKeyExists) {
+			// Claimed again by another checker in between.
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to claim key: %w", err)
+		}
+		return nil, true, nil
+	}
+
+	var r Response
+	if err := json.Unmarshal(entry.Value(), &r); err != nil {
+		return nil, false, fmt.Errorf("unable to decode response: %w", err)
+	}
+
+	return &r, false, nil
+}
+
+func (n natsStore) Complete(ctx context.Context, key string, r Response) error {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("unable to encode response: %w", err)
+	}
+	if _, err := n.kv.Put(hash(key), value); err != nil {
+		return fmt.Errorf("unable to record response: %w", err)
+	}
+
+	return nil
+}
+
+func (n natsStore) Release(ctx context.Context, key string) error {
+	if err := n.kv.Delete(hash(key)); err != nil {
+		return fmt.Errorf("unable to release key: %w", err)
+	}
+
+	return nil
+}
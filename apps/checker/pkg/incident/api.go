@@ -0,0 +1,79 @@
+package incident
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type api struct {
+	client        *http.Client
+	url           string
+	authorization string
+}
+
+// NewAPIStore returns a store opening the incidents with a POST of the
+// incident to the url, answering with its id, and resolving them with a POST
+// to <url>/<id>/resolve.
+func NewAPIStore(client *http.Client, url, authorization string) Store {
+	return api{client: client, url: url, authorization: authorization}
+}
+
+func (a api) OpenIncident(ctx context.Context, incident Incident) (string, error) {
+	var response struct {
+		ID string `json:"id"`
+	}
+	if err := a.post(ctx, a.url, incident, &response); err != nil {
+		return "", fmt.Errorf("unable to open incident: %w", err)
+	}
+	if response.ID == "" {
+		return "", fmt.Errorf("unable to open incident: missing id")
+	}
+
+	return response.ID, nil
+}
+
+func (a api) ResolveIncident(ctx context.Context, id string, resolvedAt int64) error {
+	body := map[string]int64{"resolvedAt": resolvedAt}
+	if err := a.post(ctx, fmt.Sprintf("%s/%s/resolve", a.url, url.PathEscape(id)), body, nil); err != nil {
+		return fmt.Errorf("unable to resolve incident: %w", err)
+	}
+
+	return nil
+}
+
+func (a api) post(ctx context.Context, url string, body any, response any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("unable to encode body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.authorization != "" {
+		req.Header.Set("Authorization", a.authorization)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if response != nil {
+		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+			return fmt.Errorf("unable to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
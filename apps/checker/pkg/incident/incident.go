@@ -0,0 +1,180 @@
+package incident
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/rs/zerolog/log"
+)
+
+// maxResults is the number of failed results attached to an incident.
+const maxResults = 10
+
+// opening is the id of the incidents being opened.
+const opening = "opening"
+
+// storeTimeout bounds each call to the store.
+const storeTimeout = 30 * time.Second
+
+// Incident is a sustained failure of a monitor.
+type Incident struct {
+	ID          string `json:"id,omitempty"`
+	WorkspaceID string `json:"workspaceId"`
+	MonitorID   string `json:"monitorId"`
+	Region      string `json:"region"`
+	// StartedAt is the time of the first failure, ResolvedAt of the first
+	// success after the incident, in milliseconds.
+	StartedAt  int64 `json:"startedAt"`
+	ResolvedAt int64 `json:"resolvedAt,omitempty"`
+	// Results are the last failed results triggering the incident.
+	Results []checker.PingData `json:"results"`
+}
+
+// Store opens and resolves the incidents.
+type Store interface {
+	// OpenIncident opens the incident and returns its id.
+	OpenIncident(ctx context.Context, incident Incident) (string, error)
+	ResolveIncident(ctx context.Context, id string, resolvedAt int64) error
+}
+
+// failure is the ongoing failure of a monitor.
+type failure struct {
+	since    int64
+	results  []checker.PingData
+	incident string
+	// resolved is the time of the success resolving the incident while
+	// it was being opened.
+	resolved int64
+}
+
+// Manager is a sink opening an incident when a monitor keeps failing in a
+// region for a duration, and resolving it with the first success there. The
+// incidents are opened and resolved in the background, not to delay the
+// results.
+type Manager struct {
+	next  sink.Sink
+	store Store
+	after time.Duration
+	wg    sync.WaitGroup
+
+	mu sync.Mutex
+	// failures are the ongoing failures of each monitor in a region.
+	failures map[string]*failure
+}
+
+func NewManager(next sink.Sink, store Store, after time.Duration) *Manager {
+	return &Manager{next: next, store: store, after: after, failures: map[string]*failure{}}
+}
+
+// failed reports whether the result is a failure. The results not updating
+// the status of the monitors are ignored.
+func failed(data checker.PingData) (failed bool, ignored bool) {
+	if data.Maintenance || data.Paused || data.Throttled || data.Inactive || data.Suppressed {
+		return false, true
+	}
+
+	return data.StatusCode < 200 || data.StatusCode >= 300, false
+}
+
+func key(data checker.PingData) string {
+	return data.MonitorID + ":" + data.Region
+}
+
+// Wait waits for the incidents being opened or resolved.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// async calls fn in the background, within the timeout of the store, even
+// when the context of the result is done.
+func (m *Manager) async(ctx context.Context, fn func(ctx context.Context)) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// resolve resolves the incident in the background.
+func (m *Manager) resolve(ctx context.Context, data checker.PingData, id string, resolvedAt int64) {
+	m.async(ctx, func(ctx context.Context) {
+		if err := m.store.ResolveIncident(ctx, id, resolvedAt); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("monitor", data.MonitorID).Str("region", data.Region).Str("incident", id).Msg("failed to resolve incident")
+		}
+	})
+}
+
+func (m *Manager) SendEvent(ctx context.Context, event any) error {
+	if data, ok := event.(checker.PingData); ok {
+		m.observe(ctx, data)
+	}
+
+	return m.next.SendEvent(ctx, event)
+}
+
+func (m *Manager) observe(ctx context.Context, data checker.PingData) {
+	failed, ignored := failed(data)
+	if ignored {
+		return
+	}
+
+	k := key(data)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, failing := m.failures[k]
+	if !failed {
+		delete(m.failures, k)
+		if failing && f.incident == opening {
+			f.resolved = data.Timestamp
+		}
+		if failing && f.incident != "" && f.incident != opening {
+			m.resolve(ctx, data, f.incident, data.Timestamp)
+		}
+		return
+	}
+
+	if !failing {
+		f = &failure{since: data.Timestamp}
+		m.failures[k] = f
+	}
+	f.results = append(f.results, data)
+	if len(f.results) > maxResults {
+		f.results = f.results[len(f.results)-maxResults:]
+	}
+
+	if f.incident != "" || time.Duration(data.Timestamp-f.since)*time.Millisecond < m.after {
+		return
+	}
+	// Reserve the incident, so that concurrent results do not open another.
+	f.incident = opening
+	incident := Incident{
+		WorkspaceID: data.WorkspaceID,
+		MonitorID:   data.MonitorID,
+		Region:      data.Region,
+		StartedAt:   f.since,
+		Results:     append([]checker.PingData(nil), f.results...),
+	}
+	m.async(ctx, func(ctx context.Context) {
+		id, err := m.store.OpenIncident(ctx, incident)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("monitor", data.MonitorID).Str("region", data.Region).Msg("failed to open incident")
+			f.incident = ""
+			return
+		}
+		f.incident = id
+
+		log.Ctx(ctx).Info().Str("monitor", data.MonitorID).Str("region", data.Region).Str("incident", id).Msg("incident opened")
+		if f.resolved != 0 {
+			m.resolve(ctx, data, id, f.resolved)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package incident_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/incident"
+	"github.com/stretchr/testify/require"
+)
+
+type discard struct{}
+
+func (discard) SendEvent(ctx context.Context, event any) error {
+	return nil
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	incidents map[string]*incident.Incident
+}
+
+func (m *memoryStore) OpenIncident(ctx context.Context, i incident.Incident) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i.ID = i.MonitorID + ":" + i.Region
+	m.incidents[i.ID] = &i
+	return i.ID, nil
+}
+
+func (m *memoryStore) ResolveIncident(ctx context.Context, id string, resolvedAt int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.incidents[id].ResolvedAt = resolvedAt
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &memoryStore{incidents: map[string]*incident.Incident{}}
+	manager := incident.NewManager(discard{}, store, 5*time.Minute)
+	start := time.Now()
+	result := func(after time.Duration, statusCode int) checker.PingData {
+		return checker.PingData{MonitorID: "1", Region: "ams", StatusCode: statusCode, Timestamp: start.Add(after).UnixMilli()}
+	}
+
+	t.Run("it should not open an incident before the duration", func(t *testing.T) {
+		require.NoError(t, manager.SendEvent(ctx, result(0, http.StatusInternalServerError)))
+		require.NoError(t, manager.SendEvent(ctx, result(4*time.Minute, http.StatusBadGateway)))
+		manager.Wait()
+		require.Empty(t, store.incidents)
+	})
+
+	t.Run("it should ignore the results not updating the status", func(t *testing.T) {
+		paused := result(10*time.Minute, http.StatusOK)
+		paused.Maintenance = true
+		require.NoError(t, manager.SendEvent(ctx, paused))
+	})
+
+	t.Run("it should open an incident with the failed results", func(t *testing.T) {
+		require.NoError(t, manager.SendEvent(ctx, result(5*time.Minute, http.StatusBadGateway)))
+		require.NoError(t, manager.SendEvent(ctx, result(6*time.Minute, http.StatusBadGateway)))
+		manager.Wait()
+
+		require.Len(t, store.incidents, 1)
+		require.Equal(t, start.UnixMilli(), store.incidents["1:ams"].StartedAt)
+		require.Len(t, store.incidents["1:ams"].Results, 3)
+	})
+
+	t.Run("it should not resolve the incident with the success of another region", func(t *testing.T) {
+		success := result(7*time.Minute, http.StatusOK)
+		success.Region = "iad"
+		require.NoError(t, manager.SendEvent(ctx, success))
+		manager.Wait()
+		require.Zero(t, store.incidents["1:ams"].ResolvedAt)
+	})
+
+	t.Run("it should resolve the incident with the first success", func(t *testing.T) {
+		require.NoError(t, manager.SendEvent(ctx, result(7*time.Minute, http.StatusOK)))
+		manager.Wait()
+		require.Equal(t, start.Add(7*time.Minute).UnixMilli(), store.incidents["1:ams"].ResolvedAt)
+	})
+}
@@ -0,0 +1,41 @@
+package jsonlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type Writer interface {
+	SendEvent(ctx context.Context, event any) error
+}
+
+type writer struct {
+	mu  *sync.Mutex
+	out io.Writer
+}
+
+// NewWriter returns a sink writing every event as a JSON line to out.
+func NewWriter(out io.Writer) Writer {
+	return writer{
+		mu:  &sync.Mutex{},
+		out: out,
+	}
+}
+
+func (w writer) SendEvent(ctx context.Context, event any) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode event: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("unable to write event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KeyValue is a store with atomic creates and updates, holding the lock of
+// the leader until it expires.
+type KeyValue interface {
+	// Create creates the key, failing when it already exists.
+	Create(key string, value []byte) (uint64, error)
+	// Update updates the key, failing when it changed since the revision.
+	Update(key string, value []byte, last uint64) (uint64, error)
+}
+
+// Elector elects a leader among the checkers sharing a key, e.g. the
+// standalone checkers of a region. The leader refreshes its lock before it
+// expires; when it stops, another checker takes over once it expired.
+type Elector struct {
+	kv      KeyValue
+	key     string
+	id      string
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	leader   bool
+	revision uint64
+}
+
+// NewElector returns an elector campaigning for the key as id, every
+// refresh. The refresh must be shorter than the expiry of the locks.
+func NewElector(kv KeyValue, key, id string, refresh time.Duration) *Elector {
+	return &Elector{
+		kv:      kv,
+		key:     key,
+		id:      id,
+		refresh: refresh,
+	}
+}
+
+// Leader reports whether the checker is the leader.
+func (e *Elector) Leader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.leader
+}
+
+// Run campaigns until the context is done.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.refresh)
+	defer ticker.Stop()
+
+	for {
+		e.Campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.mu.Lock()
+			e.leader = false
+			e.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Campaign takes the lock when it is free, or refreshes it when the checker
+// already holds it.
+func (e *Elector) Campaign(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.leader {
+		revision, err := e.kv.Update(e.key, []byte(e.id), e.revision)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("id", e.id).Msg("lost the leadership")
+			e.leader = false
+			return
+		}
+		e.revision = revision
+		return
+	}
+
+	revision, err := e.kv.Create(e.key, []byte(e.id))
+	if err != nil {
+		return
+	}
+	log.Ctx(ctx).Info().Str("id", e.id).Msg("elected leader")
+	e.leader = true
+	e.revision = revision
+}
@@ -0,0 +1,91 @@
+package leader_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/leader"
+	"github.com/stretchr/testify/require"
+)
+
+// memory is a key-value store whose keys can be expired by the tests.
+type memory struct {
+	mu        sync.Mutex
+	values    map[string][]byte
+	revisions map[string]uint64
+	revision  uint64
+}
+
+func newMemory() *memory {
+	return &memory{values: map[string][]byte{}, revisions: map[string]uint64{}}
+}
+
+func (m *memory) Create(key string, value []byte) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[key]; ok {
+		return 0, errors.New("key exists")
+	}
+	m.revision++
+	m.values[key] = value
+	m.revisions[key] = m.revision
+	return m.revision, nil
+}
+
+func (m *memory) Update(key string, value []byte, last uint64) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.revisions[key] != last {
+		return 0, errors.New("wrong last sequence")
+	}
+	m.revision++
+	m.values[key] = value
+	m.revisions[key] = m.revision
+	return m.revision, nil
+}
+
+func (m *memory) expire(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.values, key)
+	delete(m.revisions, key)
+}
+
+func TestElector(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	kv := newMemory()
+	a := leader.NewElector(kv, "ams", "a", 0)
+	b := leader.NewElector(kv, "ams", "b", 0)
+
+	t.Run("it should elect a single leader", func(t *testing.T) {
+		a.Campaign(ctx)
+		b.Campaign(ctx)
+
+		require.True(t, a.Leader())
+		require.False(t, b.Leader())
+	})
+
+	t.Run("it should keep the leadership while refreshing it", func(t *testing.T) {
+		a.Campaign(ctx)
+		b.Campaign(ctx)
+
+		require.True(t, a.Leader())
+		require.False(t, b.Leader())
+	})
+
+	t.Run("it should fail over once the lock expired", func(t *testing.T) {
+		kv.expire("ams")
+		b.Campaign(ctx)
+		a.Campaign(ctx)
+
+		require.True(t, b.Leader())
+		require.False(t, a.Leader(), "the former leader should step down")
+	})
+}
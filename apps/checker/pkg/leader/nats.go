@@ -0,0 +1,29 @@
+package leader
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NewNATSKeyValue returns the NATS JetStream key-value bucket on the
+// connection holding the locks, created when missing. The locks expire after
+// ttl without refresh.
+func NewNATSKeyValue(nc *nats.Conn, bucket string, ttl time.Duration) (KeyValue, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create jetstream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, TTL: ttl})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bucket %s: %w", bucket, err)
+	}
+
+	return kv, nil
+}
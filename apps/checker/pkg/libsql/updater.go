@@ -0,0 +1,152 @@
+package libsql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/rs/zerolog/log"
+)
+
+// upsertStatus upserts the status of the monitor in a region, as the API
+// does.
+const upsertStatus = `INSERT INTO monitor_status (monitor_id, region, status) VALUES (?, ?, ?)
+ON CONFLICT (monitor_id, region) DO UPDATE SET status = excluded.status, updated_at = strftime('%s', 'now')`
+
+// Updater updates the status of the monitors directly in the Turso/libSQL
+// database of the main app, through the HTTP protocol of libSQL. It only
+// upserts the status: unlike the API, it neither records the recoveries and
+// failures to the audit log of the main app nor triggers its alerts.
+type Updater struct {
+	httpClient *http.Client
+	url        string
+	token      string
+}
+
+var _ checker.BatchStatusUpdater = (*Updater)(nil)
+
+// NewUpdater returns the updater of the database at url, e.g.
+// "libsql://openstatus.turso.io", authenticated with the token, if any.
+func NewUpdater(httpClient *http.Client, url, token string) *Updater {
+	if rest, ok := strings.CutPrefix(url, "libsql://"); ok {
+		url = "https://" + rest
+	}
+
+	return &Updater{
+		httpClient: httpClient,
+		url:        strings.TrimSuffix(url, "/") + "/v2/pipeline",
+		token:      token,
+	}
+}
+
+type value struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type statement struct {
+	SQL  string  `json:"sql"`
+	Args []value `json:"args"`
+}
+
+type pipelineRequest struct {
+	Type string     `json:"type"`
+	Stmt *statement `json:"stmt,omitempty"`
+}
+
+type pipelineResult struct {
+	Type  string `json:"type"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// valid tells whether the monitor id is one of the main app, an integer.
+func valid(data checker.UpdateData) bool {
+	_, err := strconv.ParseInt(data.MonitorId, 10, 64)
+	return err == nil
+}
+
+func (u *Updater) UpdateStatus(ctx context.Context, data checker.UpdateData) error {
+	if !valid(data) {
+		return backoff.Permanent(fmt.Errorf("invalid monitor id %q", data.MonitorId))
+	}
+
+	return u.UpdateStatuses(ctx, []checker.UpdateData{data})
+}
+
+// UpdateStatuses upserts the statuses in a single pipeline, skipping the
+// monitors of an invalid id.
+func (u *Updater) UpdateStatuses(ctx context.Context, data []checker.UpdateData) error {
+	requests := make([]pipelineRequest, 0, len(data)+1)
+	for _, d := range data {
+		if !valid(d) {
+			log.Ctx(ctx).Warn().Str("monitor", d.MonitorId).Msg("invalid monitor id, skipping its status update")
+			continue
+		}
+		requests = append(requests, pipelineRequest{Type: "execute", Stmt: &statement{
+			SQL: upsertStatus,
+			Args: []value{
+				{Type: "integer", Value: d.MonitorId},
+				{Type: "text", Value: d.Region},
+				{Type: "text", Value: d.Status},
+			},
+		}})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	requests = append(requests, pipelineRequest{Type: "close"})
+
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(map[string][]pipelineRequest{"requests": requests}); err != nil {
+		return fmt.Errorf("unable to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url, &payload)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if u.token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.token)
+	}
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Results []pipelineResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+	statements := len(requests) - 1
+	if len(response.Results) < statements {
+		return fmt.Errorf("no result for the statements")
+	}
+	for _, result := range response.Results[:statements] {
+		if result.Type == "ok" {
+			continue
+		}
+		if result.Error != nil {
+			return fmt.Errorf("unable to update status: %s", result.Error.Message)
+		}
+		return fmt.Errorf("unable to update status: %s result", result.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package libsql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/libsql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("it should upsert the status of the monitor", func(t *testing.T) {
+		var (
+			path, authorization string
+			payload             struct {
+				Requests []struct {
+					Type string `json:"type"`
+					Stmt struct {
+						SQL  string `json:"sql"`
+						Args []struct {
+							Type  string `json:"type"`
+							Value string `json:"value"`
+						} `json:"args"`
+					} `json:"stmt"`
+				} `json:"requests"`
+			}
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, authorization = r.URL.Path, r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			w.Write([]byte(`{"results":[{"type":"ok","response":{"type":"execute"}},{"type":"ok","response":{"type":"close"}}]}`))
+		}))
+		defer server.Close()
+
+		updater := libsql.NewUpdater(server.Client(), server.URL, "token")
+		require.NoError(t, updater.UpdateStatus(ctx, checker.UpdateData{MonitorId: "1", Status: "error", Region: "ams"}))
+
+		require.Equal(t, "/v2/pipeline", path)
+		require.Equal(t, "Bearer token", authorization)
+		require.Len(t, payload.Requests, 2)
+		require.True(t, strings.HasPrefix(payload.Requests[0].Stmt.SQL, "INSERT INTO monitor_status"))
+		require.Len(t, payload.Requests[0].Stmt.Args, 3)
+		require.Equal(t, "integer", payload.Requests[0].Stmt.Args[0].Type)
+		require.Equal(t, "1", payload.Requests[0].Stmt.Args[0].Value)
+		require.Equal(t, "ams", payload.Requests[0].Stmt.Args[1].Value)
+		require.Equal(t, "error", payload.Requests[0].Stmt.Args[2].Value)
+		require.Equal(t, "close", payload.Requests[1].Type)
+	})
+
+	t.Run("it should return the error of the statement", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"results":[{"type":"error","error":{"message":"no such table: monitor_status"}},{"type":"ok"}]}`))
+		}))
+		defer server.Close()
+
+		updater := libsql.NewUpdater(server.Client(), server.URL, "")
+		err := updater.UpdateStatus(ctx, checker.UpdateData{MonitorId: "1", Status: "error", Region: "ams"})
+		require.ErrorContains(t, err, "no such table")
+	})
+
+	t.Run("it should upsert the statuses in a single pipeline", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload struct {
+				Requests []any `json:"requests"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			requests = len(payload.Requests)
+			w.Write([]byte(`{"results":[{"type":"ok"},{"type":"ok"},{"type":"ok"}]}`))
+		}))
+		defer server.Close()
+
+		updater := libsql.NewUpdater(server.Client(), server.URL, "")
+		require.NoError(t, updater.UpdateStatuses(ctx, []checker.UpdateData{
+			{MonitorId: "1", Status: "error", Region: "ams"},
+			{MonitorId: "abc", Status: "error", Region: "ams"},
+			{MonitorId: "2", Status: "error", Region: "ams"},
+		}))
+		require.Equal(t, 3, requests, "the invalid monitor ids should be skipped")
+	})
+
+	t.Run("it should reject the invalid monitor ids", func(t *testing.T) {
+		updater := libsql.NewUpdater(http.DefaultClient, "libsql://openstatus.turso.io", "")
+		require.Error(t, updater.UpdateStatus(ctx, checker.UpdateData{MonitorId: "abc", Status: "error", Region: "ams"}))
+	})
+}
@@ -0,0 +1,89 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Client interface {
+	SendEvent(ctx context.Context, event any) error
+}
+
+type client struct {
+	httpClient *http.Client
+	url        string
+	username   string
+	password   string
+	labels     map[string]string
+}
+
+// NewClient returns a client pushing every event as a JSON log line to the
+// Loki push API of the given base url, in a stream with the given labels.
+func NewClient(httpClient *http.Client, url, username, password string, labels map[string]string) Client {
+	return client{
+		httpClient: httpClient,
+		url:        url + "/loki/api/v1/push",
+		username:   username,
+		password:   password,
+		labels:     labels,
+	}
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+func (c client) SendEvent(ctx context.Context, event any) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to encode event")
+		return fmt.Errorf("unable to encode event: %w", err)
+	}
+
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(pushRequest{
+		Streams: []stream{{
+			Stream: c.labels,
+			Values: [][2]string{{strconv.FormatInt(time.Now().UnixNano(), 10), string(line)}},
+		}},
+	}); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to encode payload")
+		return fmt.Errorf("unable to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload.Bytes()))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to create request")
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to send request")
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		log.Ctx(ctx).Error().Str("status", resp.Status).Msg("unexpected status code")
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package loki_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/loki"
+	"github.com/stretchr/testify/require"
+)
+
+type interceptorHTTPClient struct {
+	f func(req *http.Request) (*http.Response, error)
+}
+
+func (i *interceptorHTTPClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	return i.f(req)
+}
+
+func (i *interceptorHTTPClient) GetHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: i,
+	}
+}
+
+func TestSendEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("it should return an error if it can not send the event", func(t *testing.T) {
+		interceptor := &interceptorHTTPClient{
+			f: func(req *http.Request) (*http.Response, error) {
+				return nil, fmt.Errorf("unable to send request")
+			},
+		}
+
+		client := loki.NewClient(interceptor.GetHTTPClient(), "http://loki:3100", "", "", nil)
+
+		err := client.SendEvent(ctx, "event")
+		require.Error(t, err)
+	})
+
+	t.Run("it should push the event as a json line", func(t *testing.T) {
+		var (
+			url     string
+			payload struct {
+				Streams []struct {
+					Stream map[string]string `json:"stream"`
+					Values [][2]string       `json:"values"`
+				} `json:"streams"`
+			}
+		)
+		interceptor := &interceptorHTTPClient{
+			f: func(req *http.Request) (*http.Response, error) {
+				url = req.URL.String()
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: http.StatusNoContent,
+					Body:       http.NoBody,
+				}, nil
+			},
+		}
+
+		client := loki.NewClient(interceptor.GetHTTPClient(), "http://loki:3100", "", "", map[string]string{"region": "ams"})
+
+		err := client.SendEvent(ctx, map[string]string{"monitorId": "1"})
+		require.NoError(t, err)
+		require.Equal(t, "http://loki:3100/loki/api/v1/push", url)
+		require.Len(t, payload.Streams, 1)
+		require.Equal(t, map[string]string{"region": "ams"}, payload.Streams[0].Stream)
+		require.Equal(t, `{"monitorId":"1"}`, payload.Streams[0].Values[0][1])
+	})
+}
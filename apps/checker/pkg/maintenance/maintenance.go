@@ -0,0 +1,104 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+// Window is a maintenance window of a monitor, or of every monitor of a
+// workspace when the monitor is empty, or of every monitor when both are.
+type Window struct {
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	MonitorID   string    `json:"monitorId,omitempty"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+}
+
+func (w Window) matches(req request.CheckerRequest, t time.Time) bool {
+	if w.WorkspaceID != "" && w.WorkspaceID != req.WorkspaceID {
+		return false
+	}
+	if w.MonitorID != "" && w.MonitorID != req.MonitorID {
+		return false
+	}
+
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+// Calendar holds the maintenance windows.
+type Calendar struct {
+	mu      sync.RWMutex
+	windows []Window
+}
+
+func NewCalendar(windows ...Window) *Calendar {
+	return &Calendar{windows: windows}
+}
+
+// Set replaces the maintenance windows.
+func (c *Calendar) Set(windows []Window) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.windows = windows
+}
+
+// Active reports whether the monitor of the request is in maintenance at t.
+func (c *Calendar) Active(req request.CheckerRequest, t time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, w := range c.windows {
+		if w.matches(req, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Load replaces the maintenance windows with the ones of a JSON file
+// containing an array of windows.
+func (c *Calendar) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read maintenance windows: %w", err)
+	}
+
+	var windows []Window
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return fmt.Errorf("unable to decode maintenance windows: %w", err)
+	}
+
+	c.Set(windows)
+	return nil
+}
+
+// Run reloads the maintenance windows from the file every refresh until the
+// context is done.
+func (c *Calendar) Run(ctx context.Context, path string, refresh time.Duration) {
+	if err := c.Load(path); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load maintenance windows")
+	}
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload maintenance windows")
+			}
+		}
+	}
+}
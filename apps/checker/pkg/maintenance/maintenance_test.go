@@ -0,0 +1,52 @@
+package maintenance_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/maintenance"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActive(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2023, 12, 1, 10, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	calendar := maintenance.NewCalendar(
+		maintenance.Window{MonitorID: "1", From: from, To: to},
+		maintenance.Window{WorkspaceID: "2", From: from, To: to},
+	)
+
+	t.Run("it should match the monitor during the window", func(t *testing.T) {
+		require.True(t, calendar.Active(request.CheckerRequest{MonitorID: "1"}, from))
+		require.True(t, calendar.Active(request.CheckerRequest{MonitorID: "1"}, from.Add(30*time.Minute)))
+	})
+
+	t.Run("it should not match outside of the window", func(t *testing.T) {
+		require.False(t, calendar.Active(request.CheckerRequest{MonitorID: "1"}, from.Add(-time.Second)))
+		require.False(t, calendar.Active(request.CheckerRequest{MonitorID: "1"}, to))
+	})
+
+	t.Run("it should match every monitor of the workspace", func(t *testing.T) {
+		require.True(t, calendar.Active(request.CheckerRequest{MonitorID: "3", WorkspaceID: "2"}, from))
+		require.False(t, calendar.Active(request.CheckerRequest{MonitorID: "3", WorkspaceID: "4"}, from))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"monitorId":"1","from":"2023-12-01T10:00:00Z","to":"2023-12-01T11:00:00Z"}]`), 0o600))
+
+	calendar := maintenance.NewCalendar()
+	require.NoError(t, calendar.Load(path))
+	require.True(t, calendar.Active(request.CheckerRequest{MonitorID: "1"}, time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC)))
+
+	require.Error(t, calendar.Load(filepath.Join(t.TempDir(), "missing.json")))
+}
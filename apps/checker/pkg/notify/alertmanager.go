@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// alertmanagerMessage is the payload of the Prometheus Alertmanager
+// webhooks, version 4.
+type alertmanagerMessage struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []alertmanagerAlert `json:"alerts"`
+}
+
+type alertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// alertname is the name of the alert of the notification, the recoveries
+// resolving the alert of the status they left.
+func alertname(n Notification) string {
+	if n.Certificate() {
+		return "CertificateExpiring"
+	}
+
+	status := n.Status
+	if status == "active" {
+		status = n.Previous
+	}
+	if status == "degraded" {
+		return "MonitorDegraded"
+	}
+
+	return "MonitorDown"
+}
+
+// replaced returns the name of the alert the notification replaces, e.g.
+// the MonitorDegraded alert of a monitor going down, if any.
+func replaced(n Notification) string {
+	switch {
+	case n.Status == "error" && n.Previous == "degraded":
+		return "MonitorDegraded"
+	case n.Status == "degraded" && n.Previous == "error":
+		return "MonitorDown"
+	default:
+		return ""
+	}
+}
+
+// fingerprint identifies the alert of the labels, the same for the firing
+// and the resolved alert.
+func fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\xff", key, labels[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// alertmanager encodes the notification as an Alertmanager webhook of the
+// receiver, with a single alert, and the resolution of the alert it
+// replaces, if any. The labels identify the monitor, the regions being
+// annotations as the regions of an alert and of its resolution may differ.
+func alertmanager(ctx context.Context, t templates, receiver string, n Notification) ([]byte, error) {
+	labels := map[string]string{
+		"alertname":   alertname(n),
+		"monitorId":   n.MonitorID,
+		"workspaceId": n.WorkspaceID,
+		"severity":    n.severity(),
+		"source":      "openstatus",
+	}
+
+	regions := n.Regions
+	if len(regions) == 0 && n.Region != "" {
+		regions = []string{n.Region}
+	}
+	annotations := map[string]string{"summary": t.Title(ctx, n)}
+	if description, ok := t.Body(ctx, n); ok {
+		annotations["description"] = description
+	} else if n.Message != "" {
+		annotations["description"] = n.Message
+	} else if text := summary(n); text != "" {
+		annotations["description"] = text
+	}
+	if n.URL != "" {
+		annotations["url"] = n.URL
+	}
+	if len(regions) > 0 {
+		annotations["regions"] = strings.Join(regions, ",")
+	}
+	if n.StatusCode != 0 {
+		annotations["statusCode"] = fmt.Sprint(n.StatusCode)
+	}
+
+	alert := alertmanagerAlert{
+		Status:       "firing",
+		Labels:       labels,
+		Annotations:  annotations,
+		StartsAt:     time.UnixMilli(n.Timestamp).UTC(),
+		GeneratorURL: link(t.config, n),
+		Fingerprint:  fingerprint(labels),
+	}
+	switch {
+	case n.Certificate():
+		// The alert ends at the latest with the certificate.
+		alert.EndsAt = time.UnixMilli(n.CertificateExpiry).UTC()
+	case !n.Down() && n.Status != "degraded":
+		alert.Status = "resolved"
+		alert.EndsAt = alert.StartsAt
+		alert.StartsAt = alert.EndsAt.Add(-time.Duration(n.Downtime) * time.Millisecond)
+	}
+
+	alerts := []alertmanagerAlert{alert}
+	commonLabels := labels
+	if name := replaced(n); name != "" {
+		previous := make(map[string]string, len(labels))
+		commonLabels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			previous[key] = value
+			if key != "alertname" {
+				commonLabels[key] = value
+			}
+		}
+		previous["alertname"] = name
+		alerts = append(alerts, alertmanagerAlert{
+			Status:       "resolved",
+			Labels:       previous,
+			Annotations:  annotations,
+			StartsAt:     alert.StartsAt,
+			EndsAt:       alert.StartsAt,
+			GeneratorURL: alert.GeneratorURL,
+			Fingerprint:  fingerprint(previous),
+		})
+	}
+
+	groupLabels := map[string]string{"alertname": labels["alertname"], "monitorId": n.MonitorID}
+	return json.Marshal(alertmanagerMessage{
+		Version:           "4",
+		GroupKey:          fmt.Sprintf("{}:{alertname=%q, monitorId=%q}", labels["alertname"], n.MonitorID),
+		Status:            alert.Status,
+		Receiver:          receiver,
+		GroupLabels:       groupLabels,
+		CommonLabels:      commonLabels,
+		CommonAnnotations: annotations,
+		ExternalURL:       link(t.config, n),
+		Alerts:            alerts,
+	})
+}
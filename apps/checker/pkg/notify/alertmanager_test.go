@@ -0,0 +1,79 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+type alertmanagerMessage struct {
+	Version  string `json:"version"`
+	Status   string `json:"status"`
+	Receiver string `json:"receiver"`
+	Alerts   []struct {
+		Status      string            `json:"status"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+		StartsAt    string            `json:"startsAt"`
+		EndsAt      string            `json:"endsAt"`
+		Fingerprint string            `json:"fingerprint"`
+	} `json:"alerts"`
+}
+
+func TestAlertmanager(t *testing.T) {
+	t.Parallel()
+
+	var received []alertmanagerMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message alertmanagerMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		received = append(received, message)
+	}))
+	defer server.Close()
+
+	_, err := notify.NewWebhook(server.Client(), map[string]string{"url": server.URL, "format": "xml"})
+	require.Error(t, err)
+
+	webhook, err := notify.NewWebhook(server.Client(), map[string]string{"url": server.URL, "format": "alertmanager", "receiver": "oncall"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, webhook.Notify(ctx, notify.Notification{WorkspaceID: "1", MonitorID: "2", Region: "ams", Status: "error", Severity: "critical", Message: "timeout", Timestamp: 1704067200000}))
+	require.NoError(t, webhook.Notify(ctx, notify.Notification{MonitorID: "2", Status: "throttled"}))
+	require.NoError(t, webhook.Notify(ctx, notify.Notification{WorkspaceID: "1", MonitorID: "2", Region: "iad", Status: "active", Previous: "error", Severity: "critical", Timestamp: 1704067260000, Downtime: 60000}))
+
+	require.Len(t, received, 2, "the overflow summaries should not be posted")
+	firing, resolved := received[0], received[1]
+	require.Equal(t, "4", firing.Version)
+	require.Equal(t, "oncall", firing.Receiver)
+	require.Equal(t, "firing", firing.Status)
+	require.Equal(t, map[string]string{"alertname": "MonitorDown", "monitorId": "2", "workspaceId": "1", "severity": "critical", "source": "openstatus"}, firing.Alerts[0].Labels)
+	require.Equal(t, "timeout", firing.Alerts[0].Annotations["description"])
+	require.Equal(t, "ams", firing.Alerts[0].Annotations["regions"])
+
+	require.Equal(t, "resolved", resolved.Status)
+	require.Equal(t, firing.Alerts[0].Fingerprint, resolved.Alerts[0].Fingerprint, "the recovery should resolve the alert")
+	require.Equal(t, "2024-01-01T00:00:00Z", resolved.Alerts[0].StartsAt)
+	require.Equal(t, "2024-01-01T00:01:00Z", resolved.Alerts[0].EndsAt)
+
+	t.Run("it should resolve the degraded alert of a monitor going down", func(t *testing.T) {
+		received = nil
+		require.NoError(t, webhook.Notify(ctx, notify.Notification{WorkspaceID: "1", MonitorID: "2", Region: "ams", Status: "degraded", Severity: "critical", Timestamp: 1704067200000}))
+		require.NoError(t, webhook.Notify(ctx, notify.Notification{WorkspaceID: "1", MonitorID: "2", Region: "ams", Status: "error", Previous: "degraded", Severity: "critical", Timestamp: 1704067260000}))
+
+		require.Len(t, received, 2)
+		degraded, down := received[0], received[1]
+		require.Equal(t, "MonitorDegraded", degraded.Alerts[0].Labels["alertname"])
+		require.Equal(t, "firing", down.Status)
+		require.Len(t, down.Alerts, 2)
+		require.Equal(t, "MonitorDown", down.Alerts[0].Labels["alertname"])
+		require.Equal(t, "firing", down.Alerts[0].Status)
+		require.Equal(t, "resolved", down.Alerts[1].Status)
+		require.Equal(t, degraded.Alerts[0].Fingerprint, down.Alerts[1].Fingerprint)
+	})
+}
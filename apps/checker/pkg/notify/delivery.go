@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delivery is the outcome of a test notification: the error of the
+// provider, if any, and the status of the responses to the requests it sent.
+type Delivery struct {
+	OK        bool       `json:"ok"`
+	Error     string     `json:"error,omitempty"`
+	Responses []Response `json:"responses"`
+}
+
+// Response is a response received by a provider. Its body is never kept,
+// it may echo the secrets of the channel.
+type Response struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// recorder is a transport recording the responses of the requests.
+type recorder struct {
+	next http.RoundTripper
+
+	mu        sync.Mutex
+	responses []Response
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The URL may carry credentials, e.g. the token of a Telegram bot.
+	r.mu.Lock()
+	r.responses = append(r.responses, Response{URL: req.URL.Scheme + "://" + req.URL.Host, StatusCode: resp.StatusCode})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Test sends the notification through a notifier of the channel, built
+// for the test on the transport, e.g. one rejecting the internal addresses,
+// or else on the transport of the registry, recording the responses of the
+// provider.
+func (r *Registry) Test(ctx context.Context, c Channel, n Notification, transport http.RoundTripper) (Delivery, error) {
+	if transport == nil {
+		transport = r.client.Transport
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	rec := &recorder{next: transport}
+
+	notifier, err := r.build(c, rec)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	delivery := Delivery{OK: true}
+	if err := notifier.Notify(ctx, n); err != nil {
+		delivery.OK, delivery.Error = false, errorMessage(err)
+	}
+	delivery.Responses = rec.responses
+	if delivery.Responses == nil {
+		delivery.Responses = []Response{}
+	}
+
+	return delivery, nil
+}
+
+// errorMessage returns the message of the error without the URL of the
+// request, which may carry credentials, e.g. the token of a Telegram bot.
+func errorMessage(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Sprintf("%s request failed: %v", urlErr.Op, urlErr.Err)
+	}
+
+	return err.Error()
+}
+
+// Register adds the endpoint sending a test notification through a
+// configured channel, by name, or a channel of its own type and config. The
+// notification is sent on the transport, if any.
+func (d *Dispatcher) Register(router gin.IRouter, transport http.RoundTripper) {
+	router.POST("/notifications/test", func(ctx *gin.Context) {
+		d.test(ctx, transport)
+	})
+}
+
+// TestRequest is the body of a test notification, through the configured
+// channel of the name, or else a channel of the type and config, e.g. to
+// check a channel before saving it.
+type TestRequest struct {
+	Channel string            `json:"channel,omitempty"`
+	Type    string            `json:"type,omitempty"`
+	Config  map[string]string `json:"config,omitempty"`
+	// Status of the test notification, "error" by default.
+	Status string `json:"status,omitempty"`
+}
+
+func (d *Dispatcher) test(ctx *gin.Context, transport http.RoundTripper) {
+	var req TestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	var c Channel
+	switch {
+	case req.Channel != "" && req.Type == "":
+		var ok bool
+		c, ok = d.channel(req.Channel)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "channel not found"})
+			return
+		}
+	case req.Channel == "" && req.Type != "":
+		c = Channel{Type: req.Type, Config: req.Config}
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "channel or type is required"})
+		return
+	}
+	switch req.Status {
+	case "":
+		req.Status = "error"
+	case "error", "degraded", "active":
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	n := Notification{
+		WorkspaceID: c.WorkspaceID,
+		MonitorID:   "test",
+		URL:         "https://www.openstatus.dev",
+		Region:      "ams",
+		Regions:     []string{"ams"},
+		Status:      req.Status,
+		StatusCode:  http.StatusInternalServerError,
+		Latency:     42,
+		Message:     "This is a test notification from OpenStatus",
+		Timestamp:   time.Now().UTC().UnixMilli(),
+	}
+	if req.Status == "active" {
+		n.Previous, n.StatusCode, n.Downtime, n.AffectedRegions = "error", http.StatusOK, 60000, []string{"ams"}
+	}
+
+	delivery, err := d.registry.Test(ctx.Request.Context(), c, n, transport)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !delivery.OK {
+		ctx.JSON(http.StatusBadGateway, delivery)
+		return
+	}
+	ctx.JSON(http.StatusOK, delivery)
+}
+
+// channel returns the channel of the name.
+func (d *Dispatcher) channel(name string) (Channel, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, c := range d.channels {
+		if c.Name == name {
+			return c.Channel, true
+		}
+	}
+
+	return Channel{}, false
+}
@@ -0,0 +1,97 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsTest(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte("webhook deleted"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dispatcher := notify.NewDispatcher(notify.NewRegistry(server.Client()))
+	require.NoError(t, dispatcher.Set([]notify.Channel{
+		{Name: "ok", Type: "webhook", Config: map[string]string{"url": server.URL}},
+		{Name: "ops", Type: "webhook", Config: map[string]string{"url": server.URL + "/gone", "retries": "0"}},
+		{Name: "unreachable", Type: "webhook", Config: map[string]string{"url": "http://127.0.0.1:1/secret-token", "retries": "0"}},
+	}))
+	router := gin.New()
+	dispatcher.Register(router, nil)
+
+	do := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/notifications/test", strings.NewReader(body))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should return the response of the provider", func(t *testing.T) {
+		w := do(`{"channel":"ok"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var delivery notify.Delivery
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivery))
+		require.True(t, delivery.OK)
+		require.Len(t, delivery.Responses, 1)
+		require.Equal(t, http.StatusOK, delivery.Responses[0].StatusCode)
+	})
+
+	t.Run("it should return the failure of a configured channel", func(t *testing.T) {
+		w := do(`{"channel":"ops"}`)
+		require.Equal(t, http.StatusBadGateway, w.Code)
+
+		var delivery notify.Delivery
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivery))
+		require.False(t, delivery.OK)
+		require.Equal(t, "unexpected status code: 410", delivery.Error)
+		require.Equal(t, http.StatusGone, delivery.Responses[0].StatusCode)
+		require.NotContains(t, w.Body.String(), "webhook deleted")
+	})
+
+	t.Run("it should not leak the url of the channel", func(t *testing.T) {
+		w := do(`{"channel":"unreachable"}`)
+		require.Equal(t, http.StatusBadGateway, w.Code)
+		require.NotContains(t, w.Body.String(), "secret-token")
+	})
+
+	t.Run("it should send through a channel of its own", func(t *testing.T) {
+		w := do(`{"type":"webhook","config":{"url":"` + server.URL + `"}}`)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("it should reject the invalid channels", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, do(`{"channel":"unknown"}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(`{}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(`{"channel":"ok","type":"webhook"}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(`{"type":"unknown"}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(`{"type":"webhook","config":{}}`).Code)
+	})
+
+	t.Run("it should send the channels of their own on the transport", func(t *testing.T) {
+		router := gin.New()
+		dispatcher.Register(router, rejectingTransport{})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notifications/test", strings.NewReader(`{"type":"webhook","config":{"url":"http://169.254.169.254","retries":"0"}}`)))
+		require.Equal(t, http.StatusBadGateway, w.Code)
+		require.Contains(t, w.Body.String(), "address not allowed")
+	})
+}
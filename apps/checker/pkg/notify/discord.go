@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The colors of the embeds.
+const (
+	discordRed   = 0xe11d48
+	discordGreen = 0x22c55e
+)
+
+type discord struct {
+	client    *http.Client
+	url       string
+	config    map[string]string
+	templates templates
+}
+
+// NewDiscord returns a notifier posting embeds to the Discord webhook "url"
+// of the config.
+func NewDiscord(client *http.Client, config map[string]string) (Notifier, error) {
+	if config["url"] == "" {
+		return nil, errors.New("missing url")
+	}
+
+	templates, err := newTemplates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return discord{client: client, url: config["url"], config: config, templates: templates}, nil
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+func (d discord) Notify(ctx context.Context, n Notification) error {
+	embed := discordEmbed{
+		Title:     d.templates.Title(ctx, n),
+		URL:       link(d.config, n),
+		Color:     discordGreen,
+		Timestamp: time.UnixMilli(n.Timestamp).UTC().Format(time.RFC3339),
+		Fields:    []discordField{},
+	}
+	// Discord rejects the embeds with an empty field, e.g. the URL of a
+	// heartbeat.
+	if n.URL != "" {
+		embed.Fields = append(embed.Fields, discordField{Name: "URL", Value: n.URL})
+	}
+	if n.Region != "" {
+		embed.Fields = append(embed.Fields, discordField{Name: "Region", Value: n.Region, Inline: true})
+	}
+	if n.Down() {
+		embed.Color = discordRed
+		if n.Message != "" {
+			embed.Description = "```" + n.Message + "```"
+		}
+	} else if n.Throttled() || n.Certificate() {
+		embed.Description = n.Message
+	} else {
+		embed.Description = summary(n)
+	}
+	if n.StatusCode != 0 {
+		embed.Fields = append(embed.Fields, discordField{Name: "Status code", Value: strconv.Itoa(n.StatusCode), Inline: true})
+	}
+	if n.Latency != 0 {
+		embed.Fields = append(embed.Fields, discordField{Name: "Latency", Value: fmt.Sprintf("%d ms", n.Latency), Inline: true})
+	}
+	if body, ok := d.templates.Body(ctx, n); ok {
+		embed.Description = body
+		embed.Fields = []discordField{}
+	}
+
+	payload, err := json.Marshal(discordMessage{Username: d.config["username"], Embeds: []discordEmbed{embed}})
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	return post(ctx, d.client, d.url, payload, nil)
+}
@@ -0,0 +1,54 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscord(t *testing.T) {
+	t.Parallel()
+
+	var received struct {
+		Embeds []struct {
+			Title  string `json:"title"`
+			Color  int    `json:"color"`
+			Fields []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"embeds"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord, err := notify.NewDiscord(server.Client(), map[string]string{"url": server.URL})
+	require.NoError(t, err)
+
+	t.Run("it should post a red embed when down", func(t *testing.T) {
+		require.NoError(t, discord.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Len(t, received.Embeds, 1)
+		require.Equal(t, "Monitor 1 is down", received.Embeds[0].Title)
+		require.Equal(t, 0xe11d48, received.Embeds[0].Color)
+	})
+
+	t.Run("it should post a green embed when recovered", func(t *testing.T) {
+		require.NoError(t, discord.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Equal(t, "Monitor 1 recovered", received.Embeds[0].Title)
+		require.Equal(t, 0x22c55e, received.Embeds[0].Color)
+	})
+
+	t.Run("it should omit the empty fields", func(t *testing.T) {
+		require.NoError(t, discord.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error", Region: "ams"}))
+		require.Len(t, received.Embeds[0].Fields, 1)
+		require.Equal(t, "Region", received.Embeds[0].Fields[0].Name)
+	})
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultEmailSubject = `[OpenStatus] {{ .Title }}`
+	defaultEmailBody    = `{{ .Title }}
+
+URL: {{ .URL }}
+Region: {{ .Region }}
+{{- if .StatusCode }}
+Status code: {{ .StatusCode }}
+{{- end }}
+{{- if .Latency }}
+Latency: {{ .Latency }} ms
+{{- end }}
+{{- if .Message }}
+
+{{ .Message }}
+{{- end }}
+{{- if .Summary }}
+
+{{ .Summary }}
+{{- end }}
+{{- if .Link }}
+
+{{ .Link }}
+{{- end }}
+`
+)
+
+type email struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+	to       []string
+	security string
+	subject  *template.Template
+	body     *template.Template
+	config   map[string]string
+}
+
+// NewEmail returns a notifier sending emails through the SMTP server "host"
+// and "port" (default 587) of the config, authenticated with "username" and
+// "password" if any, from "from" to the comma separated "to". "tls" is
+// "starttls" (the default), "tls" for implicit TLS or "none". The "subject",
+// or the "title" of the channel, and "body" are templates of the
+// notification.
+func NewEmail(_ *http.Client, config map[string]string) (Notifier, error) {
+	e := email{
+		host:     config["host"],
+		port:     config["port"],
+		username: config["username"],
+		password: config["password"],
+		from:     config["from"],
+		security: config["tls"],
+		config:   config,
+	}
+	for _, to := range strings.Split(config["to"], ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			e.to = append(e.to, to)
+		}
+	}
+	if e.host == "" || e.from == "" || len(e.to) == 0 {
+		return nil, errors.New("missing host, from or to")
+	}
+	if e.port == "" {
+		e.port = "587"
+	}
+	if e.security == "" {
+		e.security = "starttls"
+	}
+	switch e.security {
+	case "starttls", "tls", "none":
+	default:
+		return nil, fmt.Errorf("invalid tls %q", e.security)
+	}
+
+	var err error
+	subject := config["subject"]
+	if subject == "" {
+		subject = config["title"]
+	}
+	if e.subject, err = parseTemplate("subject", subject, defaultEmailSubject); err != nil {
+		return nil, err
+	}
+	if e.body, err = parseTemplate("body", config["body"], defaultEmailBody); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e email) message(n Notification) ([]byte, error) {
+	subject, err := render(e.subject, e.config, n)
+	if err != nil {
+		return nil, err
+	}
+	body, err := render(e.body, e.config, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return msg.Bytes(), nil
+}
+
+func (e email) Notify(ctx context.Context, n Notification) error {
+	msg, err := e.message(n)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(e.host, e.port)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to the smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if e.security == "tls" {
+		conn = tls.Client(conn, &tls.Config{ServerName: e.host})
+	}
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("unable to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if e.security == "starttls" {
+		if err := client.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return fmt.Errorf("unable to start tls: %w", err)
+		}
+	}
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return fmt.Errorf("unable to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return fmt.Errorf("unable to set sender: %w", err)
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("unable to set recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("unable to start data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("unable to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("unable to send message: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,111 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer accepts a single message, sending its recipients and data to
+// the channels.
+func smtpServer(t *testing.T) (string, chan string, chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	recipients, data := make(chan string, 10), make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				recipients <- strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 Go ahead")
+				var b strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					b.WriteString(line)
+				}
+				data <- b.String()
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), recipients, data
+}
+
+func TestEmail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should validate the config", func(t *testing.T) {
+		_, err := notify.NewEmail(nil, map[string]string{"host": "localhost", "from": "checker@openstat.us"})
+		require.Error(t, err, "the recipients should be required")
+
+		_, err = notify.NewEmail(nil, map[string]string{"host": "localhost", "from": "a@b.c", "to": "d@e.f", "subject": "{{ .Nope"})
+		require.Error(t, err, "the templates should be valid")
+	})
+
+	t.Run("it should send the templated email", func(t *testing.T) {
+		addr, recipients, data := smtpServer(t)
+		host, port, err := net.SplitHostPort(addr)
+		require.NoError(t, err)
+
+		email, err := notify.NewEmail(nil, map[string]string{
+			"host":    host,
+			"port":    port,
+			"tls":     "none",
+			"from":    "checker@openstat.us",
+			"to":      "ops@openstat.us, oncall@openstat.us",
+			"subject": "{{ .Title }} in {{ .Region }}",
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, email.Notify(context.Background(), notify.Notification{
+			MonitorID: "1",
+			URL:       "https://openstat.us",
+			Region:    "ams",
+			Status:    "error",
+			Message:   "Timeout after 45000 ms",
+		}))
+
+		require.Equal(t, "ops@openstat.us", <-recipients)
+		require.Equal(t, "oncall@openstat.us", <-recipients)
+		message := <-data
+		require.Contains(t, message, "Subject: Monitor 1 is down in ams\r\n")
+		require.Contains(t, message, "URL: https://openstat.us\r\n")
+		require.Contains(t, message, "Timeout after 45000 ms")
+	})
+}
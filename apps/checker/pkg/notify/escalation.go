@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Step is a step of an escalation policy: its channels are notified once
+// the monitor has been down, unacknowledged, for After, e.g. "15m".
+type Step struct {
+	Channels []string `json:"channels"`
+	After    string   `json:"after,omitempty"`
+
+	after time.Duration
+}
+
+// Policy escalates the failures of the monitors of a workspace, or of some
+// monitors, through its steps until they are acknowledged or recover.
+type Policy struct {
+	Name        string   `json:"name"`
+	WorkspaceID string   `json:"workspaceId,omitempty"`
+	Monitors    []string `json:"monitors,omitempty"`
+	Steps       []Step   `json:"steps"`
+}
+
+// Escalation is the escalation of a monitor which is down.
+type Escalation struct {
+	MonitorID    string    `json:"monitorId"`
+	Policy       string    `json:"policy"`
+	Since        time.Time `json:"since"`
+	Step         int       `json:"step"`
+	Acknowledged bool      `json:"acknowledged"`
+
+	policy       Policy
+	notification Notification
+	notified     []string
+}
+
+// Escalator notifies the monitors with an escalation policy through its
+// steps, and the other ones through the dispatcher.
+type Escalator struct {
+	dispatcher *Dispatcher
+
+	mu          sync.Mutex
+	policies    []Policy
+	escalations map[string]*Escalation
+}
+
+func NewEscalator(dispatcher *Dispatcher) *Escalator {
+	return &Escalator{dispatcher: dispatcher, escalations: map[string]*Escalation{}}
+}
+
+// Set replaces the policies. The ongoing escalations keep their policy.
+func (e *Escalator) Set(policies []Policy) error {
+	for i, p := range policies {
+		if len(p.Steps) == 0 {
+			return fmt.Errorf("policy %s without steps", p.Name)
+		}
+		for j, step := range p.Steps {
+			if step.After == "" {
+				continue
+			}
+			after, err := time.ParseDuration(step.After)
+			if err != nil {
+				return fmt.Errorf("invalid after %q of policy %s: %w", step.After, p.Name, err)
+			}
+			policies[i].Steps[j].after = after
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.policies = policies
+	return nil
+}
+
+// policy returns the first policy of the monitor of the notification.
+func (e *Escalator) policy(n Notification) (Policy, bool) {
+	for _, p := range e.policies {
+		if scoped(p.WorkspaceID, p.Monitors, n) {
+			return p, true
+		}
+	}
+
+	return Policy{}, false
+}
+
+// Notify starts the escalation of the monitors going down, and notifies the
+// recoveries to the channels notified during their escalation.
+func (e *Escalator) Notify(ctx context.Context, n Notification) error {
+	e.mu.Lock()
+	escalation, escalating := e.escalations[n.MonitorID]
+	policy, ok := e.policy(n)
+	if !escalating && !ok {
+		e.mu.Unlock()
+		return e.dispatcher.Notify(ctx, n)
+	}
+
+	if n.Down() {
+		if !escalating {
+			e.escalations[n.MonitorID] = &Escalation{
+				MonitorID:    n.MonitorID,
+				Policy:       policy.Name,
+				Since:        time.Now(),
+				policy:       policy,
+				notification: n,
+			}
+		}
+		e.mu.Unlock()
+
+		e.Escalate(ctx, time.Now())
+		return nil
+	}
+
+	channels := policy.Steps[0].Channels
+	if escalating {
+		channels = escalation.notified
+		if n.Status == "active" {
+			delete(e.escalations, n.MonitorID)
+		}
+	}
+	e.mu.Unlock()
+
+	return e.dispatcher.NotifyChannels(ctx, channels, n)
+}
+
+// Escalate notifies the channels of the steps reached by the escalations.
+func (e *Escalator) Escalate(ctx context.Context, now time.Time) {
+	type send struct {
+		channels []string
+		n        Notification
+	}
+
+	e.mu.Lock()
+	var sends []send
+	for _, escalation := range e.escalations {
+		if escalation.Acknowledged {
+			continue
+		}
+		steps := escalation.policy.Steps
+		for escalation.Step < len(steps) && now.Sub(escalation.Since) >= steps[escalation.Step].after {
+			channels := steps[escalation.Step].Channels
+			sends = append(sends, send{channels: channels, n: escalation.notification})
+			escalation.notified = append(escalation.notified, channels...)
+			escalation.Step++
+		}
+	}
+	e.mu.Unlock()
+
+	for _, s := range sends {
+		if err := e.dispatcher.NotifyChannels(ctx, s.channels, s.n); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("monitor", s.n.MonitorID).Msg("failed to escalate")
+		}
+	}
+}
+
+// Acknowledge stops the escalation of the monitor, the notified channels are
+// still notified of its recovery. It returns false when the monitor is not
+// escalating.
+func (e *Escalator) Acknowledge(monitorID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	escalation, ok := e.escalations[monitorID]
+	if ok {
+		escalation.Acknowledged = true
+	}
+
+	return ok
+}
+
+// List returns the ongoing escalations.
+func (e *Escalator) List() []Escalation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	escalations := make([]Escalation, 0, len(e.escalations))
+	for _, escalation := range e.escalations {
+		escalations = append(escalations, *escalation)
+	}
+	sort.Slice(escalations, func(i, j int) bool {
+		return escalations[i].MonitorID < escalations[j].MonitorID
+	})
+
+	return escalations
+}
+
+// Load replaces the policies with the ones of a JSON file containing an
+// array of policies.
+func (e *Escalator) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read escalation policies: %w", err)
+	}
+
+	var policies []Policy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return fmt.Errorf("unable to decode escalation policies: %w", err)
+	}
+
+	return e.Set(policies)
+}
+
+// Run reloads the policies from the file every refresh, and escalates every
+// interval, until the context is done.
+func (e *Escalator) Run(ctx context.Context, path string, refresh, interval time.Duration) {
+	if err := e.Load(path); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load escalation policies")
+	}
+
+	reload := time.NewTicker(refresh)
+	defer reload.Stop()
+	escalate := time.NewTicker(interval)
+	defer escalate.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload.C:
+			if err := e.Load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload escalation policies")
+			}
+		case now := <-escalate.C:
+			e.Escalate(ctx, now)
+		}
+	}
+}
+
+// Register adds the endpoints listing the escalations and acknowledging the
+// monitors.
+func (e *Escalator) Register(router gin.IRouter) {
+	router.GET("/escalations", e.list)
+	router.POST("/monitors/:id/acknowledge", e.acknowledge)
+}
+
+// Escalations is the body of the list of the escalating monitors.
+type Escalations struct {
+	Escalations []Escalation `json:"escalations"`
+}
+
+func (e *Escalator) list(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Escalations{Escalations: e.List()})
+}
+
+func (e *Escalator) acknowledge(ctx *gin.Context) {
+	if !e.Acknowledge(ctx.Param("id")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "monitor not escalating"})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscalator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := &recorder{notifications: map[string][]notify.Notification{}}
+	registry := notify.NewRegistry(http.DefaultClient)
+	registry.Register("test", r.factory)
+	dispatcher := notify.NewDispatcher(registry)
+	require.NoError(t, dispatcher.Set([]notify.Channel{
+		{Name: "slack", Type: "test", Monitors: []string{"2"}, Config: map[string]string{"id": "slack"}},
+		{Name: "pager", Type: "test", Monitors: []string{"none"}, Config: map[string]string{"id": "pager"}},
+	}))
+
+	escalator := notify.NewEscalator(dispatcher)
+	require.Error(t, escalator.Set([]notify.Policy{{Name: "empty"}}))
+	require.NoError(t, escalator.Set([]notify.Policy{{
+		Name:     "default",
+		Monitors: []string{"1"},
+		Steps: []notify.Step{
+			{Channels: []string{"slack"}},
+			{Channels: []string{"pager"}, After: "15m"},
+		},
+	}}))
+
+	t.Run("it should notify the other monitors through the dispatcher", func(t *testing.T) {
+		require.NoError(t, escalator.Notify(ctx, notify.Notification{MonitorID: "2", Status: "error"}))
+		require.Len(t, r.notifications["slack"], 1)
+		require.Empty(t, escalator.List())
+	})
+
+	t.Run("it should escalate through the steps", func(t *testing.T) {
+		require.NoError(t, escalator.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Len(t, r.notifications["slack"], 2, "the first step should be notified at once")
+		require.Empty(t, r.notifications["pager"])
+
+		escalator.Escalate(ctx, time.Now().Add(10*time.Minute))
+		require.Empty(t, r.notifications["pager"])
+
+		escalator.Escalate(ctx, time.Now().Add(16*time.Minute))
+		escalator.Escalate(ctx, time.Now().Add(20*time.Minute))
+		require.Len(t, r.notifications["pager"], 1)
+	})
+
+	t.Run("it should notify the recovery to the notified channels", func(t *testing.T) {
+		require.NoError(t, escalator.Notify(ctx, notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Len(t, r.notifications["slack"], 3)
+		require.Len(t, r.notifications["pager"], 2)
+		require.Empty(t, escalator.List())
+	})
+
+	t.Run("it should stop escalating once acknowledged", func(t *testing.T) {
+		require.False(t, escalator.Acknowledge("1"))
+		require.NoError(t, escalator.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error"}))
+		require.True(t, escalator.Acknowledge("1"))
+
+		escalator.Escalate(ctx, time.Now().Add(time.Hour))
+		require.Len(t, r.notifications["pager"], 2)
+
+		require.NoError(t, escalator.Notify(ctx, notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Len(t, r.notifications["slack"], 5)
+		require.Len(t, r.notifications["pager"], 2, "the recovery should only notify the notified channels")
+	})
+}
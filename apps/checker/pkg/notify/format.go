@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// title is the one line summary of the notification.
+func title(n Notification) string {
+	switch n.Status {
+	case "error":
+		return fmt.Sprintf("Monitor %s is down", n.MonitorID)
+	case "degraded":
+		return fmt.Sprintf("Monitor %s is degraded", n.MonitorID)
+	case "throttled":
+		return "Notifications throttled"
+	case "certificate":
+		return fmt.Sprintf("Certificate of monitor %s expires soon", n.MonitorID)
+	default:
+		return fmt.Sprintf("Monitor %s recovered", n.MonitorID)
+	}
+}
+
+// summary is the summary of the downtime ended by a recovery, if any.
+func summary(n Notification) string {
+	if n.Downtime == 0 {
+		return ""
+	}
+
+	text := fmt.Sprintf("Down for %s", (time.Duration(n.Downtime) * time.Millisecond).Round(time.Second))
+	if len(n.AffectedRegions) > 0 {
+		text += " in " + strings.Join(n.AffectedRegions, ", ")
+	}
+	if n.LastError != "" {
+		text += ". Last error: " + n.LastError
+	}
+
+	return text
+}
+
+// link is the "link" of the config, e.g. a link to the monitor in the
+// dashboard, with its {monitorId} and {workspaceId} placeholders replaced.
+func link(config map[string]string, n Notification) string {
+	return strings.NewReplacer(
+		"{monitorId}", n.MonitorID,
+		"{workspaceId}", n.WorkspaceID,
+	).Replace(config["link"])
+}
+
+// templateData is the data of the templates: the notification, its title,
+// the summary of its downtime and its link.
+type templateData struct {
+	Notification
+	Title   string
+	Summary string
+	Link    string
+}
+
+// templateFuncs are the functions of the templates.
+var templateFuncs = template.FuncMap{
+	// duration formats milliseconds, e.g. the latency or the downtime.
+	"duration": func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).Round(time.Millisecond).String()
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseTemplate parses the text template, or the fallback when empty.
+func parseTemplate(name, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// render renders the template with the notification.
+func render(tmpl *template.Template, config map[string]string, n Notification) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, templateData{Notification: n, Title: title(n), Summary: summary(n), Link: link(config, n)}); err != nil {
+		return "", fmt.Errorf("unable to render %s template: %w", tmpl.Name(), err)
+	}
+
+	return b.String(), nil
+}
+
+// templates are the optional "title" and "body" templates of a channel,
+// replacing the default title and details of its notifications.
+type templates struct {
+	title  *template.Template
+	body   *template.Template
+	config map[string]string
+}
+
+// newTemplates parses the templates of the config.
+func newTemplates(config map[string]string) (templates, error) {
+	t := templates{config: config}
+	var err error
+	if config["title"] != "" {
+		if t.title, err = parseTemplate("title", config["title"], ""); err != nil {
+			return templates{}, err
+		}
+	}
+	if config["body"] != "" {
+		if t.body, err = parseTemplate("body", config["body"], ""); err != nil {
+			return templates{}, err
+		}
+	}
+
+	return t, nil
+}
+
+// Title returns the title of the notification, the default one when the
+// template is unset or fails.
+func (t templates) Title(ctx context.Context, n Notification) string {
+	if t.title == nil {
+		return title(n)
+	}
+
+	text, err := render(t.title, t.config, n)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("monitor", n.MonitorID).Msg("unable to render the title, using the default one")
+		return title(n)
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// Body returns the body of the notification, false when the template is
+// unset or fails and the default details should be sent.
+func (t templates) Body(ctx context.Context, n Notification) (string, bool) {
+	if t.body == nil {
+		return "", false
+	}
+
+	text, err := render(t.body, t.config, n)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("monitor", n.MonitorID).Msg("unable to render the body, using the default details")
+		return "", false
+	}
+
+	return strings.TrimSpace(text), true
+}
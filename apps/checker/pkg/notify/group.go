@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Groups holds the last status notified of each monitor, the regions which
+// reported it, and the status before.
+type Groups interface {
+	// Join adds the region of the notification to the regions reporting its
+	// status. It reports whether the status is new, the region being the
+	// first to report it.
+	Join(ctx context.Context, n Notification) (bool, error)
+	// Get returns the group of the monitor, nil when unknown.
+	Get(ctx context.Context, monitorID string) (*Group, error)
+}
+
+// Group is the last status notified of a monitor.
+type Group struct {
+	Status  string   `json:"status"`
+	Regions []string `json:"regions"`
+	// Since is the time of the first report, in milliseconds.
+	Since int64 `json:"since"`
+	// Message is the last message reported, e.g. the last error.
+	Message  string `json:"message,omitempty"`
+	Previous *Group `json:"previous,omitempty"`
+}
+
+// join adds the region of the notification to the group, reporting whether
+// its status is new.
+func (g *Group) join(n Notification) bool {
+	if g.Status != n.Status {
+		previous := *g
+		previous.Previous = nil
+		*g = Group{Status: n.Status, Regions: []string{n.Region}, Since: n.Timestamp, Message: n.Message}
+		if previous.Status != "" {
+			g.Previous = &previous
+		}
+		return true
+	}
+
+	if n.Message != "" {
+		g.Message = n.Message
+	}
+	for _, r := range g.Regions {
+		if r == n.Region {
+			return false
+		}
+	}
+	g.Regions = append(g.Regions, n.Region)
+
+	return false
+}
+
+type memoryGroups struct {
+	mu     sync.Mutex
+	groups map[string]*Group
+}
+
+// NewMemoryGroups returns the groups of a single checker.
+func NewMemoryGroups() Groups {
+	return &memoryGroups{groups: map[string]*Group{}}
+}
+
+func (m *memoryGroups) Join(ctx context.Context, n Notification) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.groups[n.MonitorID]
+	if !ok {
+		g = &Group{}
+		m.groups[n.MonitorID] = g
+	}
+
+	return g.join(n), nil
+}
+
+func (m *memoryGroups) Get(ctx context.Context, monitorID string) (*Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.groups[monitorID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *g
+	copied.Regions = append([]string(nil), g.Regions...)
+
+	return &copied, nil
+}
+
+// Grouper deduplicates the notifications: a status already notified for a
+// monitor is not notified again, and the regions reporting a new status
+// within the window are notified together, by the first one.
+type Grouper struct {
+	next   Notifier
+	groups Groups
+	window time.Duration
+
+	wg sync.WaitGroup
+}
+
+func NewGrouper(next Notifier, groups Groups, window time.Duration) *Grouper {
+	return &Grouper{next: next, groups: groups, window: window}
+}
+
+// Notify notifies the new statuses once the window elapsed, in the
+// background, and the other notifications right away.
+func (g *Grouper) Notify(ctx context.Context, n Notification) error {
+	// The certificates expiring are not statuses.
+	if n.Certificate() {
+		return g.next.Notify(ctx, n)
+	}
+
+	created, err := g.groups.Join(ctx, n)
+	if err != nil {
+		// Rather a duplicate than a missed notification.
+		log.Ctx(ctx).Warn().Err(err).Str("monitor", n.MonitorID).Msg("unable to group the notification, sending it")
+		return g.next.Notify(ctx, n)
+	}
+	if !created {
+		log.Ctx(ctx).Debug().Str("monitor", n.MonitorID).Str("status", n.Status).Msg("status already notified, skipping the notification")
+		return nil
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		ctx := context.WithoutCancel(ctx)
+		time.Sleep(g.window)
+
+		group, err := g.groups.Get(ctx, n.MonitorID)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("monitor", n.MonitorID).Msg("unable to get the group of the notification")
+		}
+		// The status may have changed since, the notification is then
+		// sent alone.
+		if group != nil && group.Status == n.Status {
+			n.Regions = group.Regions
+			n.Region = strings.Join(group.Regions, ", ")
+
+			if previous := group.Previous; n.Status == "active" && previous != nil && previous.Status == "error" {
+				n.Downtime = group.Since - previous.Since
+				n.AffectedRegions = previous.Regions
+				n.LastError = previous.Message
+			}
+		}
+
+		if err := g.next.Notify(ctx, n); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("monitor", n.MonitorID).Msg("failed to notify")
+		}
+	}()
+
+	return nil
+}
+
+// Wait waits for the pending notifications.
+func (g *Grouper) Wait() {
+	g.wg.Wait()
+}
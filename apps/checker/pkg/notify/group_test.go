@@ -0,0 +1,58 @@
+package notify_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrouper(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var (
+		mu            sync.Mutex
+		notifications []notify.Notification
+	)
+	next := notifierFunc(func(ctx context.Context, n notify.Notification) error {
+		mu.Lock()
+		defer mu.Unlock()
+		notifications = append(notifications, n)
+		return nil
+	})
+	grouper := notify.NewGrouper(next, notify.NewMemoryGroups(), 50*time.Millisecond)
+
+	t.Run("it should group the regions reporting the same status", func(t *testing.T) {
+		require.NoError(t, grouper.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error", Region: "ams", Message: "timeout", Timestamp: 1000}))
+		require.NoError(t, grouper.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error", Region: "iad", Message: "500", Timestamp: 2000}))
+		grouper.Wait()
+
+		require.Len(t, notifications, 1)
+		require.Equal(t, []string{"ams", "iad"}, notifications[0].Regions)
+		require.Equal(t, "ams, iad", notifications[0].Region)
+	})
+
+	t.Run("it should deduplicate the status already notified", func(t *testing.T) {
+		require.NoError(t, grouper.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error", Region: "gru"}))
+		grouper.Wait()
+
+		require.Len(t, notifications, 1)
+	})
+
+	t.Run("it should notify the recovery with the downtime summary", func(t *testing.T) {
+		require.NoError(t, grouper.Notify(ctx, notify.Notification{MonitorID: "1", Status: "active", Region: "ams", Timestamp: 61000}))
+		grouper.Wait()
+
+		require.Len(t, notifications, 2)
+		recovery := notifications[1]
+		require.Equal(t, "active", recovery.Status)
+		require.Equal(t, []string{"ams"}, recovery.Regions)
+		require.Equal(t, int64(60000), recovery.Downtime)
+		require.Equal(t, []string{"ams", "iad", "gru"}, recovery.AffectedRegions, "the regions which joined later should be affected too")
+		require.Equal(t, "500", recovery.LastError)
+	})
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type matrix struct {
+	client    *http.Client
+	url       string
+	token     string
+	roomID    string
+	config    map[string]string
+	templates templates
+}
+
+// NewMatrix returns a notifier sending messages to the Matrix "roomId" of
+// the config, on the homeserver "url" with the access "token" of a user
+// joined to the room.
+func NewMatrix(client *http.Client, config map[string]string) (Notifier, error) {
+	m := matrix{client: client, url: strings.TrimSuffix(config["url"], "/"), token: config["token"], roomID: config["roomId"], config: config}
+	if m.url == "" || m.token == "" || m.roomID == "" {
+		return nil, errors.New("missing url, token or roomId")
+	}
+
+	var err error
+	if m.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+func (m matrix) message(ctx context.Context, n Notification) matrixMessage {
+	title := m.templates.Title(ctx, n)
+	details := text(ctx, m.templates, n)
+
+	body := title + "\n" + details
+	formatted := "<b>" + html.EscapeString(title) + "</b><br>" + strings.ReplaceAll(html.EscapeString(details), "\n", "<br>")
+	if url := link(m.config, n); url != "" {
+		body += "\n" + url
+		formatted += fmt.Sprintf("<br><a href=\"%s\">View monitor</a>", html.EscapeString(url))
+	}
+
+	// The recoveries are notices, not highlighting the room.
+	msgType := "m.text"
+	if !n.Down() {
+		msgType = "m.notice"
+	}
+
+	return matrixMessage{MsgType: msgType, Body: body, Format: "org.matrix.custom.html", FormattedBody: formatted}
+}
+
+func (m matrix) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(m.message(ctx, n))
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	// The transaction id makes the message idempotent.
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("unable to generate transaction id: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", m.url, url.PathEscape(m.roomID), hex.EncodeToString(id))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.token)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &statusError{code: resp.StatusCode}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrix(t *testing.T) {
+	t.Parallel()
+
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.True(t, strings.HasPrefix(r.URL.EscapedPath(), "/_matrix/client/v3/rooms/%21ops:example.com/send/m.room.message/"))
+		require.Equal(t, "Bearer syt_token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{"event_id":"$1"}`))
+	}))
+	defer server.Close()
+
+	_, err := notify.NewMatrix(server.Client(), map[string]string{"url": server.URL, "token": "syt_token"})
+	require.Error(t, err, "the room should be required")
+
+	matrix, err := notify.NewMatrix(server.Client(), map[string]string{"url": server.URL, "token": "syt_token", "roomId": "!ops:example.com"})
+	require.NoError(t, err)
+
+	require.NoError(t, matrix.Notify(context.Background(), notify.Notification{MonitorID: "1", Region: "ams", Status: "error", Message: "<timeout>"}))
+	require.Equal(t, "m.text", received["msgtype"])
+	require.Contains(t, received["body"], "Monitor 1 is down")
+	require.Contains(t, received["formatted_body"], "&lt;timeout&gt;", "the message should be escaped")
+
+	require.NoError(t, matrix.Notify(context.Background(), notify.Notification{MonitorID: "1", Region: "ams", Status: "active"}))
+	require.Equal(t, "m.notice", received["msgtype"])
+}
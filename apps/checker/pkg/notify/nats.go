@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// maxAttempts bounds the retries of the updates racing with other checkers.
+const maxAttempts = 10
+
+type natsGroups struct {
+	kv nats.KeyValue
+}
+
+// NewNATSGroups returns groups shared by the checkers through a NATS
+// JetStream key-value bucket on the connection, created when missing. The
+// statuses expire after the ttl, when they are notified again.
+func NewNATSGroups(nc *nats.Conn, bucket string, ttl time.Duration) (Groups, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create jetstream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, TTL: ttl})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bucket %s: %w", bucket, err)
+	}
+
+	return natsGroups{kv: kv}, nil
+}
+
+// Join updates the group with a compare-and-swap, retried when another
+// checker updated it in between.
+func (n natsGroups) Join(ctx context.Context, notification Notification) (bool, error) {
+	key := notification.MonitorID
+	for attempt := 0; attempt < maxAttempts && ctx.Err() == nil; attempt++ {
+		var g Group
+		entry, err := n.kv.Get(key)
+		switch {
+		case errors.Is(err, nats.ErrKeyNotFound):
+		case err != nil:
+			return false, fmt.Errorf("unable to get group: %w", err)
+		default:
+			if err := json.Unmarshal(entry.Value(), &g); err != nil {
+				return false, fmt.Errorf("unable to decode group: %w", err)
+			}
+		}
+
+		created := g.join(notification)
+		value, err := json.Marshal(g)
+		if err != nil {
+			return false, fmt.Errorf("unable to encode group: %w", err)
+		}
+		if entry != nil && bytes.Equal(value, entry.Value()) {
+			return false, nil
+		}
+
+		if entry == nil {
+			_, err = n.kv.Create(key, value)
+		} else {
+			_, err = n.kv.Update(key, value, entry.Revision())
+		}
+		if err == nil {
+			return created, nil
+		}
+	}
+
+	return false, fmt.Errorf("unable to join group %s: too many conflicts", key)
+}
+
+func (n natsGroups) Get(ctx context.Context, monitorID string) (*Group, error) {
+	entry, err := n.kv.Get(monitorID)
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get group: %w", err)
+	}
+
+	var g Group
+	if err := json.Unmarshal(entry.Value(), &g); err != nil {
+		return nil, fmt.Errorf("unable to decode group: %w", err)
+	}
+
+	return &g, nil
+}
@@ -0,0 +1,388 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var notificationsSent, _ = telemetry.Meter().Int64Counter("checker.notify.sent",
+	metric.WithDescription("Notifications sent to the channels."),
+)
+
+// Notification is a status transition of a monitor.
+type Notification struct {
+	WorkspaceID string `json:"workspaceId"`
+	MonitorID   string `json:"monitorId"`
+	URL         string `json:"url,omitempty"`
+	// Region is the region which reported the status, or the comma
+	// separated Regions when several regions reported it together.
+	Region  string   `json:"region"`
+	Regions []string `json:"regions,omitempty"`
+	// Status is the new status of the monitor, "error", "degraded" or
+	// "active", and Previous the one it left. The overflow summaries of the
+	// throttled channels are "throttled", the certificates expiring soon
+	// "certificate".
+	Status   string `json:"status"`
+	Previous string `json:"previousStatus,omitempty"`
+	// Severity is the severity of the monitor, "info", "warning" or
+	// "critical", if any.
+	Severity string `json:"severity,omitempty"`
+	// Tags are the tags of the monitor, if any.
+	Tags       []string `json:"tags,omitempty"`
+	StatusCode int      `json:"statusCode,omitempty"`
+	Latency    int64    `json:"latency,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Timestamp  int64    `json:"timestamp"`
+	// Downtime is the duration of the downtime ended by a recovery, in
+	// milliseconds, AffectedRegions the regions which reported it and
+	// LastError the last error they reported.
+	Downtime        int64    `json:"downtime,omitempty"`
+	AffectedRegions []string `json:"affectedRegions,omitempty"`
+	LastError       string   `json:"lastError,omitempty"`
+	// CertificateExpiry is the expiry of the certificate of the monitor, in
+	// milliseconds, of the "certificate" notifications.
+	CertificateExpiry int64 `json:"certificateExpiry,omitempty"`
+	// Channels are the channels of the monitor, notified instead of the
+	// matching ones: channels of the dispatcher by name, or channels of
+	// their own provider and config. They are not sent to the providers.
+	Channels []Channel `json:"-"`
+}
+
+// Certificate reports whether the notification is about the certificate of
+// the monitor expiring, not about its status.
+func (n Notification) Certificate() bool {
+	return n.Status == "certificate"
+}
+
+// Throttled reports whether the notification is the overflow summary of a
+// throttled channel.
+func (n Notification) Throttled() bool {
+	return n.Status == "throttled"
+}
+
+// severity is the severity of the monitor, warning by default.
+func (n Notification) severity() string {
+	if n.Severity == "" {
+		return "warning"
+	}
+
+	return n.Severity
+}
+
+// validSeverity reports whether the severity is "info", "warning" or
+// "critical".
+func validSeverity(severity string) bool {
+	switch severity {
+	case "info", "warning", "critical":
+		return true
+	default:
+		return false
+	}
+}
+
+// Down reports whether the monitor went down.
+func (n Notification) Down() bool {
+	return n.Status == "error"
+}
+
+// Notifier sends the notifications to a destination.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Channel is a configured destination of the notifications: a provider, its
+// configuration and the monitors it is notified of.
+type Channel struct {
+	Name string `json:"name"`
+	// Type is the provider of the channel, e.g. "webhook".
+	Type string `json:"type"`
+	// WorkspaceID and Monitors restrict the channel to the monitors of a
+	// workspace, or to some monitors. Every monitor is notified when unset.
+	WorkspaceID string   `json:"workspaceId,omitempty"`
+	Monitors    []string `json:"monitors,omitempty"`
+	// Severities restrict the channel to the monitors of some severities,
+	// e.g. ["critical"] for a pager. The monitors without a severity are
+	// warnings.
+	Severities []string          `json:"severities,omitempty"`
+	Config     map[string]string `json:"config,omitempty"`
+	// Throttle limits the notifications of the channel, when set.
+	Throttle *Throttle `json:"throttle,omitempty"`
+}
+
+// matches reports whether the channel is notified of the notification.
+func (c Channel) matches(n Notification) bool {
+	if len(c.Severities) == 0 {
+		return scoped(c.WorkspaceID, c.Monitors, n)
+	}
+	for _, severity := range c.Severities {
+		if severity == n.severity() {
+			return scoped(c.WorkspaceID, c.Monitors, n)
+		}
+	}
+
+	return false
+}
+
+// scoped reports whether the notification is of a monitor of the workspace,
+// or of the monitors, when set.
+func scoped(workspaceID string, monitors []string, n Notification) bool {
+	if workspaceID != "" && workspaceID != n.WorkspaceID {
+		return false
+	}
+	if len(monitors) == 0 {
+		return true
+	}
+	for _, monitorID := range monitors {
+		if monitorID == n.MonitorID {
+			return true
+		}
+	}
+
+	return false
+}
+
+type channel struct {
+	Channel
+	notifier Notifier
+	throttle *throttle
+}
+
+// Dispatcher sends the notifications to the matching channels.
+type Dispatcher struct {
+	registry  *Registry
+	transport http.RoundTripper
+
+	mu       sync.RWMutex
+	channels []channel
+}
+
+type DispatcherOption func(*Dispatcher)
+
+// WithTransport sends the notifications of the channels given by the
+// monitors, rather than configured, on the transport, e.g. one rejecting the
+// internal addresses.
+func WithTransport(transport http.RoundTripper) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.transport = transport
+	}
+}
+
+func NewDispatcher(registry *Registry, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{registry: registry}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Set replaces the channels. When a channel is invalid, the previous
+// channels are kept.
+func (d *Dispatcher) Set(channels []Channel) error {
+	built := make([]channel, 0, len(channels))
+	for _, c := range channels {
+		for _, severity := range c.Severities {
+			if !validSeverity(severity) {
+				return fmt.Errorf("invalid channel %s: invalid severity %q", c.Name, severity)
+			}
+		}
+		notifier, err := d.registry.New(c)
+		if err != nil {
+			return fmt.Errorf("invalid channel %s: %w", c.Name, err)
+		}
+		built = append(built, channel{Channel: c, notifier: notifier})
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The throttles of the unchanged channels keep their counts.
+	throttles := map[string]channel{}
+	for _, c := range d.channels {
+		throttles[c.Name] = c
+	}
+	for i, c := range built {
+		if c.Throttle == nil {
+			continue
+		}
+		if previous, ok := throttles[c.Name]; ok && previous.Throttle != nil && *previous.Throttle == *c.Throttle {
+			built[i].throttle = previous.throttle
+			continue
+		}
+		throttle, err := newThrottle(*c.Throttle)
+		if err != nil {
+			return fmt.Errorf("invalid channel %s: %w", c.Name, err)
+		}
+		built[i].throttle = throttle
+	}
+
+	d.channels = built
+	return nil
+}
+
+// Notify sends the notification to every matching channel concurrently. A
+// failing channel does not prevent the others from being notified, the
+// errors are collected and returned together.
+func (d *Dispatcher) Notify(ctx context.Context, n Notification) error {
+	if len(n.Channels) > 0 {
+		return d.notifyOwn(ctx, n)
+	}
+
+	d.mu.RLock()
+	var channels []channel
+	for _, c := range d.channels {
+		if c.matches(n) {
+			channels = append(channels, c)
+		}
+	}
+	d.mu.RUnlock()
+
+	return d.send(ctx, channels, n)
+}
+
+// notifyOwn sends the notification to the channels of the monitor.
+func (d *Dispatcher) notifyOwn(ctx context.Context, n Notification) error {
+	var (
+		channels []channel
+		errs     []error
+	)
+	d.mu.RLock()
+	for _, own := range n.Channels {
+		if own.Type != "" {
+			continue
+		}
+		found := false
+		for _, c := range d.channels {
+			if c.Name == own.Name {
+				channels = append(channels, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: unknown channel", own.Name))
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, own := range n.Channels {
+		if own.Type == "" {
+			continue
+		}
+		if own.Name == "" {
+			own.Name = own.Type
+		}
+		notifier, err := d.registry.build(own, d.transport)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid channel %s: %w", own.Name, err))
+			continue
+		}
+		channels = append(channels, channel{Channel: own, notifier: notifier})
+	}
+
+	n.Channels = nil
+	return errors.Join(append(errs, d.send(ctx, channels, n))...)
+}
+
+// NotifyChannels sends the notification to the named channels, whatever the
+// monitors they are notified of.
+func (d *Dispatcher) NotifyChannels(ctx context.Context, names []string, n Notification) error {
+	d.mu.RLock()
+	var channels []channel
+	for _, c := range d.channels {
+		for _, name := range names {
+			if c.Name == name {
+				channels = append(channels, c)
+				break
+			}
+		}
+	}
+	d.mu.RUnlock()
+
+	return d.send(ctx, channels, n)
+}
+
+// send sends the notification to the channels concurrently.
+func (d *Dispatcher) send(ctx context.Context, channels []channel, n Notification) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, c := range channels {
+		if c.throttle != nil && !c.throttle.allow(ctx, n, c.notifier) {
+			log.Ctx(ctx).Warn().Str("channel", c.Name).Str("monitor", n.MonitorID).Msg("channel throttled, suppressing the notification")
+			continue
+		}
+
+		wg.Add(1)
+		go func(c channel) {
+			defer wg.Done()
+
+			err := c.notifier.Notify(ctx, n)
+			notificationsSent.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("type", c.Type),
+				attribute.Bool("success", err == nil),
+			))
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("channel", c.Name).Str("monitor", n.MonitorID).Msg("failed to notify channel")
+
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Load replaces the channels with the ones of a JSON file containing an
+// array of channels.
+func (d *Dispatcher) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read notification channels: %w", err)
+	}
+
+	var channels []Channel
+	if err := json.Unmarshal(data, &channels); err != nil {
+		return fmt.Errorf("unable to decode notification channels: %w", err)
+	}
+
+	return d.Set(channels)
+}
+
+// Run reloads the channels from the file every refresh until the context is
+// done.
+func (d *Dispatcher) Run(ctx context.Context, path string, refresh time.Duration) {
+	if err := d.Load(path); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load notification channels")
+	}
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload notification channels")
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu            sync.Mutex
+	notifications map[string][]notify.Notification
+}
+
+func (r *recorder) factory(client *http.Client, config map[string]string) (notify.Notifier, error) {
+	if config["fail"] == "build" {
+		return nil, errors.New("invalid config")
+	}
+	return notifierFunc(func(ctx context.Context, n notify.Notification) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.notifications[config["id"]] = append(r.notifications[config["id"]], n)
+		if config["fail"] == "notify" {
+			return errors.New("unavailable")
+		}
+		return nil
+	}), nil
+}
+
+type notifierFunc func(ctx context.Context, n notify.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n notify.Notification) error {
+	return f(ctx, n)
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := &recorder{notifications: map[string][]notify.Notification{}}
+	registry := notify.NewRegistry(http.DefaultClient)
+	registry.Register("test", r.factory)
+	dispatcher := notify.NewDispatcher(registry)
+
+	require.NoError(t, dispatcher.Set([]notify.Channel{
+		{Name: "all", Type: "test", Config: map[string]string{"id": "all"}},
+		{Name: "workspace", Type: "test", WorkspaceID: "1", Config: map[string]string{"id": "workspace"}},
+		{Name: "monitor", Type: "test", Monitors: []string{"2"}, Config: map[string]string{"id": "monitor"}},
+		{Name: "failing", Type: "test", Config: map[string]string{"id": "failing", "fail": "notify"}},
+	}))
+
+	t.Run("it should notify the matching channels", func(t *testing.T) {
+		err := dispatcher.Notify(ctx, notify.Notification{WorkspaceID: "1", MonitorID: "1", Status: "error"})
+
+		require.ErrorContains(t, err, "failing: unavailable")
+		require.Len(t, r.notifications["all"], 1)
+		require.Len(t, r.notifications["workspace"], 1)
+		require.Empty(t, r.notifications["monitor"])
+		require.Len(t, r.notifications["failing"], 1, "the failing channel should not prevent the others")
+	})
+
+	t.Run("it should keep the channels when a new one is invalid", func(t *testing.T) {
+		require.Error(t, dispatcher.Set([]notify.Channel{{Name: "invalid", Type: "test", Config: map[string]string{"fail": "build"}}}))
+		require.Error(t, dispatcher.Set([]notify.Channel{{Name: "unknown", Type: "carrier-pigeon"}}))
+
+		_ = dispatcher.Notify(ctx, notify.Notification{WorkspaceID: "2", MonitorID: "2", Status: "active"})
+		require.Len(t, r.notifications["all"], 2)
+		require.Len(t, r.notifications["monitor"], 1)
+	})
+
+	t.Run("it should route the notifications per severity", func(t *testing.T) {
+		r := &recorder{notifications: map[string][]notify.Notification{}}
+		registry := notify.NewRegistry(http.DefaultClient)
+		registry.Register("test", r.factory)
+		dispatcher := notify.NewDispatcher(registry)
+
+		require.Error(t, dispatcher.Set([]notify.Channel{{Name: "pager", Type: "test", Severities: []string{"urgent"}}}))
+		require.NoError(t, dispatcher.Set([]notify.Channel{
+			{Name: "pager", Type: "test", Severities: []string{"critical"}, Config: map[string]string{"id": "pager"}},
+			{Name: "chat", Type: "test", Severities: []string{"info", "warning"}, Config: map[string]string{"id": "chat"}},
+		}))
+
+		require.NoError(t, dispatcher.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error", Severity: "critical"}))
+		require.NoError(t, dispatcher.Notify(ctx, notify.Notification{MonitorID: "2", Status: "error"}))
+		require.Len(t, r.notifications["pager"], 1)
+		require.Equal(t, "1", r.notifications["pager"][0].MonitorID)
+		require.Len(t, r.notifications["chat"], 1, "the monitors without a severity should be warnings")
+		require.Equal(t, "2", r.notifications["chat"][0].MonitorID)
+	})
+
+	t.Run("it should notify the channels of the monitor instead", func(t *testing.T) {
+		r := &recorder{notifications: map[string][]notify.Notification{}}
+		registry := notify.NewRegistry(http.DefaultClient)
+		registry.Register("test", r.factory)
+		dispatcher := notify.NewDispatcher(registry)
+		require.NoError(t, dispatcher.Set([]notify.Channel{
+			{Name: "all", Type: "test", Config: map[string]string{"id": "all"}},
+			{Name: "ops", Type: "test", Config: map[string]string{"id": "ops"}},
+		}))
+
+		err := dispatcher.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error", Channels: []notify.Channel{
+			{Name: "ops"},
+			{Type: "test", Config: map[string]string{"id": "own"}},
+			{Name: "unknown"},
+		}})
+
+		require.ErrorContains(t, err, "unknown: unknown channel")
+		require.Empty(t, r.notifications["all"], "the matching channels should not be notified")
+		require.Len(t, r.notifications["ops"], 1)
+		require.Len(t, r.notifications["own"], 1)
+		require.Empty(t, r.notifications["own"][0].Channels, "the channels should not be sent to the providers")
+	})
+
+	t.Run("it should notify the channels of the monitor on the transport", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}))
+		defer server.Close()
+
+		dispatcher := notify.NewDispatcher(notify.NewRegistry(server.Client()), notify.WithTransport(rejectingTransport{}))
+		err := dispatcher.Notify(ctx, notify.Notification{MonitorID: "1", Status: "error", Channels: []notify.Channel{
+			{Type: "webhook", Config: map[string]string{"url": server.URL, "retries": "0"}},
+		}})
+
+		require.ErrorContains(t, err, "address not allowed")
+		require.Zero(t, requests.Load())
+	})
+}
+
+// rejectingTransport rejects every request, as for the internal addresses.
+type rejectingTransport struct{}
+
+func (rejectingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return nil, errors.New("address not allowed")
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const opsgenieAPI = "https://api.opsgenie.com"
+
+// opsgeniePriorities maps the severities of the monitors to the priorities
+// of the alerts.
+var opsgeniePriorities = map[string]string{
+	"critical": "P1",
+	"warning":  "P3",
+	"info":     "P5",
+}
+
+type opsgenie struct {
+	client    *http.Client
+	url       string
+	apiKey    string
+	priority  string
+	config    map[string]string
+	templates templates
+}
+
+// NewOpsgenie returns a notifier creating, and closing, Opsgenie alerts with
+// the "apiKey" of the config. The priority of the alerts is mapped from the
+// severity of the monitor, or is the "priority" of the config, P3 by
+// default. "url" overrides the API, e.g. for the EU instance.
+func NewOpsgenie(client *http.Client, config map[string]string) (Notifier, error) {
+	o := opsgenie{client: client, url: config["url"], apiKey: config["apiKey"], priority: config["priority"], config: config}
+	if o.apiKey == "" {
+		return nil, errors.New("missing apiKey")
+	}
+	if o.url == "" {
+		o.url = opsgenieAPI
+	}
+	if o.priority == "" {
+		o.priority = "P3"
+	}
+	switch o.priority {
+	case "P1", "P2", "P3", "P4", "P5":
+	default:
+		return nil, fmt.Errorf("invalid priority %q", o.priority)
+	}
+
+	var err error
+	if o.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+func (o opsgenie) Notify(ctx context.Context, n Notification) error {
+	// The overflow summaries are not alerts.
+	if n.Throttled() {
+		return nil
+	}
+
+	headers := map[string]string{"Authorization": "GenieKey " + o.apiKey}
+	alias := dedupKey(n)
+
+	if !n.Down() && !n.Certificate() {
+		payload, err := json.Marshal(map[string]string{"source": "openstatus", "note": o.templates.Title(ctx, n)})
+		if err != nil {
+			return fmt.Errorf("unable to encode close: %w", err)
+		}
+		return post(ctx, o.client, fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", o.url, url.PathEscape(alias)), payload, headers)
+	}
+
+	priority, ok := opsgeniePriorities[n.Severity]
+	if !ok {
+		priority = o.priority
+	}
+	description, ok := o.templates.Body(ctx, n)
+	if !ok {
+		description = n.Message
+	}
+	alert := opsgenieAlert{
+		Message:     o.templates.Title(ctx, n),
+		Alias:       alias,
+		Description: description,
+		Priority:    priority,
+		Entity:      n.URL,
+		Source:      "openstatus",
+		Details: map[string]string{
+			"workspaceId": n.WorkspaceID,
+			"monitorId":   n.MonitorID,
+			"region":      n.Region,
+			"statusCode":  strconv.Itoa(n.StatusCode),
+			"latency":     strconv.FormatInt(n.Latency, 10),
+		},
+	}
+	if url := link(o.config, n); url != "" {
+		alert.Details["link"] = url
+	}
+
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("unable to encode alert: %w", err)
+	}
+
+	return post(ctx, o.client, o.url+"/v2/alerts", payload, headers)
+}
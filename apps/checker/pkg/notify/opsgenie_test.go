@@ -0,0 +1,51 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsgenie(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path     string
+		received map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "GenieKey key", r.Header.Get("Authorization"))
+		path = r.URL.RequestURI()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	_, err := notify.NewOpsgenie(server.Client(), map[string]string{"apiKey": "key", "priority": "P9"})
+	require.Error(t, err)
+
+	opsgenie, err := notify.NewOpsgenie(server.Client(), map[string]string{"apiKey": "key", "url": server.URL})
+	require.NoError(t, err)
+
+	t.Run("it should map the severity to the priority", func(t *testing.T) {
+		require.NoError(t, opsgenie.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error", Severity: "critical"}))
+		require.Equal(t, "/v2/alerts", path)
+		require.Equal(t, "P1", received["priority"])
+		require.Equal(t, "openstatus-1", received["alias"])
+	})
+
+	t.Run("it should default to the priority of the config", func(t *testing.T) {
+		require.NoError(t, opsgenie.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Equal(t, "P3", received["priority"])
+	})
+
+	t.Run("it should close the alert when recovered", func(t *testing.T) {
+		require.NoError(t, opsgenie.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Equal(t, "/v2/alerts/openstatus-1/close?identifierType=alias", path)
+	})
+}
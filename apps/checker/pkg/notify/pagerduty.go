@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const pagerDutyAPI = "https://events.pagerduty.com/v2/enqueue"
+
+type pagerDuty struct {
+	client     *http.Client
+	url        string
+	routingKey string
+	severity   string
+	config     map[string]string
+	templates  templates
+}
+
+// NewPagerDuty returns a notifier triggering, and resolving, PagerDuty
+// alerts with the Events API v2 "routingKey" of the config. The "severity"
+// of the alerts defaults to critical, "url" overrides the API, e.g. for the
+// EU service region.
+func NewPagerDuty(client *http.Client, config map[string]string) (Notifier, error) {
+	p := pagerDuty{client: client, url: config["url"], routingKey: config["routingKey"], severity: config["severity"], config: config}
+	if p.routingKey == "" {
+		return nil, errors.New("missing routingKey")
+	}
+	if p.url == "" {
+		p.url = pagerDutyAPI
+	}
+	if p.severity == "" {
+		p.severity = "critical"
+	}
+	switch p.severity {
+	case "critical", "error", "warning", "info":
+	default:
+		return nil, fmt.Errorf("invalid severity %q", p.severity)
+	}
+
+	var err error
+	if p.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp"`
+	Component     string         `json:"component"`
+	Group         string         `json:"group,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+// dedupKey is the key of the alerts of the monitor, resolving the alert
+// triggered by its failure on recovery. The certificate alerts have their
+// own key.
+func dedupKey(n Notification) string {
+	if n.Certificate() {
+		return "openstatus-" + n.MonitorID + "-certificate"
+	}
+
+	return "openstatus-" + n.MonitorID
+}
+
+func (p pagerDuty) Notify(ctx context.Context, n Notification) error {
+	// The overflow summaries are not alerts.
+	if n.Throttled() {
+		return nil
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "resolve",
+		DedupKey:    dedupKey(n),
+	}
+	if n.Down() || n.Certificate() {
+		source := n.URL
+		if source == "" {
+			source = n.MonitorID
+		}
+		severity := p.severity
+		if n.Certificate() {
+			severity = "warning"
+		}
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{
+			Summary:   p.templates.Title(ctx, n),
+			Source:    source,
+			Severity:  severity,
+			Timestamp: time.UnixMilli(n.Timestamp).UTC().Format(time.RFC3339),
+			Component: n.MonitorID,
+			Group:     n.WorkspaceID,
+			CustomDetails: map[string]any{
+				"region":     n.Region,
+				"statusCode": n.StatusCode,
+				"latency":    n.Latency,
+				"message":    n.Message,
+			},
+		}
+		if body, ok := p.templates.Body(ctx, n); ok {
+			event.Payload.CustomDetails["body"] = body
+		}
+		if url := link(p.config, n); url != "" {
+			event.Links = []pagerDutyLink{{Href: url, Text: "View monitor"}}
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode event: %w", err)
+	}
+
+	return post(ctx, p.client, p.url, payload, nil)
+}
@@ -0,0 +1,52 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDuty(t *testing.T) {
+	t.Parallel()
+
+	var received struct {
+		RoutingKey  string `json:"routing_key"`
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+		Payload     *struct {
+			Severity string `json:"severity"`
+		} `json:"payload"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Payload = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	_, err := notify.NewPagerDuty(server.Client(), map[string]string{"routingKey": "key", "severity": "meh"})
+	require.Error(t, err)
+
+	pagerDuty, err := notify.NewPagerDuty(server.Client(), map[string]string{"routingKey": "key", "url": server.URL, "severity": "warning"})
+	require.NoError(t, err)
+
+	t.Run("it should trigger an alert when down", func(t *testing.T) {
+		require.NoError(t, pagerDuty.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Equal(t, "key", received.RoutingKey)
+		require.Equal(t, "trigger", received.EventAction)
+		require.Equal(t, "openstatus-1", received.DedupKey)
+		require.Equal(t, "warning", received.Payload.Severity)
+	})
+
+	t.Run("it should resolve the alert when recovered", func(t *testing.T) {
+		require.NoError(t, pagerDuty.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Equal(t, "resolve", received.EventAction)
+		require.Equal(t, "openstatus-1", received.DedupKey)
+		require.Nil(t, received.Payload)
+	})
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	ntfyServer  = "https://ntfy.sh"
+	pushoverAPI = "https://api.pushover.net/1/messages.json"
+)
+
+// text is the plain text message of the notification, its templated body
+// if any.
+func text(ctx context.Context, t templates, n Notification) string {
+	if body, ok := t.Body(ctx, n); ok {
+		return body
+	}
+
+	lines := []string{n.URL, "Region: " + n.Region}
+	if n.StatusCode != 0 {
+		lines = append(lines, fmt.Sprintf("Status code: %d", n.StatusCode))
+	}
+	if n.Latency != 0 {
+		lines = append(lines, fmt.Sprintf("Latency: %d ms", n.Latency))
+	}
+	if n.Message != "" {
+		lines = append(lines, n.Message)
+	}
+	if text := summary(n); text != "" {
+		lines = append(lines, text)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+type ntfy struct {
+	client    *http.Client
+	url       string
+	topic     string
+	token     string
+	config    map[string]string
+	templates templates
+}
+
+// NewNtfy returns a notifier publishing to the ntfy "topic" of the config,
+// on the "url" server (default https://ntfy.sh) with the optional access
+// "token".
+func NewNtfy(client *http.Client, config map[string]string) (Notifier, error) {
+	n := ntfy{client: client, url: config["url"], topic: config["topic"], token: config["token"], config: config}
+	if n.topic == "" {
+		return nil, errors.New("missing topic")
+	}
+	if n.url == "" {
+		n.url = ntfyServer
+	}
+
+	var err error
+	if n.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+func (p ntfy) Notify(ctx context.Context, n Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.url, "/")+"/"+url.PathEscape(p.topic), strings.NewReader(text(ctx, p.templates, n)))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Set("Title", p.templates.Title(ctx, n))
+	switch n.Status {
+	case "error":
+		req.Header.Set("Priority", "urgent")
+		req.Header.Set("Tags", "rotating_light")
+	case "degraded":
+		req.Header.Set("Priority", "high")
+		req.Header.Set("Tags", "warning")
+	case "certificate":
+		req.Header.Set("Priority", "high")
+		req.Header.Set("Tags", "lock")
+	default:
+		req.Header.Set("Priority", "default")
+		req.Header.Set("Tags", "white_check_mark")
+	}
+	if link := link(p.config, n); link != "" {
+		req.Header.Set("Click", link)
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &statusError{code: resp.StatusCode}
+	}
+
+	return nil
+}
+
+type pushover struct {
+	client    *http.Client
+	url       string
+	token     string
+	user      string
+	priority  int
+	config    map[string]string
+	templates templates
+}
+
+// NewPushover returns a notifier sending Pushover messages with the
+// application "token" of the config to its "user" key. The monitors going
+// down are sent with the "priority" of the config, from -2 to 1, high (1) by
+// default.
+func NewPushover(client *http.Client, config map[string]string) (Notifier, error) {
+	p := pushover{client: client, url: config["url"], token: config["token"], user: config["user"], priority: 1, config: config}
+	if p.token == "" || p.user == "" {
+		return nil, errors.New("missing token or user")
+	}
+	if p.url == "" {
+		p.url = pushoverAPI
+	}
+	if value := config["priority"]; value != "" {
+		// The emergency priority requires acknowledgements, not supported.
+		priority, err := strconv.Atoi(value)
+		if err != nil || priority < -2 || priority > 1 {
+			return nil, fmt.Errorf("invalid priority %q", value)
+		}
+		p.priority = priority
+	}
+
+	var err error
+	if p.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p pushover) Notify(ctx context.Context, n Notification) error {
+	priority := 0
+	if n.Down() {
+		priority = p.priority
+	}
+
+	form := url.Values{
+		"token":    {p.token},
+		"user":     {p.user},
+		"title":    {p.templates.Title(ctx, n)},
+		"message":  {text(ctx, p.templates, n)},
+		"priority": {strconv.Itoa(priority)},
+	}
+	if link := link(p.config, n); link != "" {
+		form.Set("url", link)
+		form.Set("url_title", "View monitor")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Status int      `json:"status"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+	if response.Status != 1 {
+		return fmt.Errorf("unable to send message: %s", strings.Join(response.Errors, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package notify_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNtfy(t *testing.T) {
+	t.Parallel()
+
+	var (
+		header http.Header
+		body   string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/alerts", r.URL.Path)
+		header = r.Header
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	_, err := notify.NewNtfy(server.Client(), map[string]string{})
+	require.Error(t, err, "the topic should be required")
+
+	ntfy, err := notify.NewNtfy(server.Client(), map[string]string{"url": server.URL, "topic": "alerts", "token": "tk_1"})
+	require.NoError(t, err)
+	require.NoError(t, ntfy.Notify(context.Background(), notify.Notification{MonitorID: "1", URL: "https://openstat.us", Region: "ams", Status: "error"}))
+
+	require.Equal(t, "Monitor 1 is down", header.Get("Title"))
+	require.Equal(t, "urgent", header.Get("Priority"))
+	require.Equal(t, "Bearer tk_1", header.Get("Authorization"))
+	require.Equal(t, "https://openstat.us\nRegion: ams", body)
+}
+
+func TestPushover(t *testing.T) {
+	t.Parallel()
+
+	var priority string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		priority = r.PostForm.Get("priority")
+		if r.PostForm.Get("user") != "user" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":0,"errors":["user identifier is invalid"]}`))
+			return
+		}
+		w.Write([]byte(`{"status":1}`))
+	}))
+	defer server.Close()
+
+	_, err := notify.NewPushover(server.Client(), map[string]string{"token": "app", "user": "user", "priority": "2"})
+	require.Error(t, err, "the emergency priority should not be supported")
+
+	t.Run("it should send the monitors going down with the priority", func(t *testing.T) {
+		pushover, err := notify.NewPushover(server.Client(), map[string]string{"url": server.URL, "token": "app", "user": "user"})
+		require.NoError(t, err)
+
+		require.NoError(t, pushover.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Equal(t, "1", priority)
+		require.NoError(t, pushover.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Equal(t, "0", priority)
+	})
+
+	t.Run("it should report the errors", func(t *testing.T) {
+		pushover, err := notify.NewPushover(server.Client(), map[string]string{"url": server.URL, "token": "app", "user": "nobody"})
+		require.NoError(t, err)
+
+		require.ErrorContains(t, pushover.Notify(context.Background(), notify.Notification{MonitorID: "1"}), "user identifier is invalid")
+	})
+}
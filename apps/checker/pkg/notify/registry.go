@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Factory creates the notifier of a channel from its configuration.
+type Factory func(client *http.Client, config map[string]string) (Notifier, error)
+
+// Registry holds the providers of the notifications, by type.
+type Registry struct {
+	client *http.Client
+
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry returns a registry of the built-in providers, whose notifiers
+// use the client.
+func NewRegistry(client *http.Client) *Registry {
+	r := &Registry{client: client, factories: map[string]Factory{}}
+	r.Register("webhook", NewWebhook)
+	r.Register("slack", NewSlack)
+	r.Register("discord", NewDiscord)
+	r.Register("pagerduty", NewPagerDuty)
+	r.Register("opsgenie", NewOpsgenie)
+	r.Register("teams", NewTeams)
+	r.Register("telegram", NewTelegram)
+	r.Register("email", NewEmail)
+	r.Register("twilio", NewTwilio)
+	r.Register("ntfy", NewNtfy)
+	r.Register("pushover", NewPushover)
+	r.Register("matrix", NewMatrix)
+
+	return r
+}
+
+// Register registers the provider of a type, replacing any previous one.
+func (r *Registry) Register(kind string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[kind] = factory
+}
+
+// New creates the notifier of the channel.
+func (r *Registry) New(c Channel) (Notifier, error) {
+	return r.build(c, nil)
+}
+
+// Validate tells whether the channel is of a known provider and of a valid
+// configuration, building its notifier without sending anything.
+func (r *Registry) Validate(c Channel) error {
+	_, err := r.build(c, nil)
+	return err
+}
+
+// build creates the notifier of the channel, on the transport when set
+// rather than the one of the client.
+func (r *Registry) build(c Channel, transport http.RoundTripper) (Notifier, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[c.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", c.Type)
+	}
+
+	client := r.client
+	if transport != nil {
+		copied := *r.client
+		copied.Transport = transport
+		client = &copied
+	}
+
+	return factory(client, c.Config)
+}
@@ -0,0 +1,227 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Silence suppresses the notifications of the monitors it matches, of its
+// monitor, tag or region, and of its workspace if any, from StartsAt to
+// EndsAt. The statuses are still updated and recorded.
+type Silence struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	MonitorID   string    `json:"monitorId,omitempty"`
+	Tag         string    `json:"tag,omitempty"`
+	Region      string    `json:"region,omitempty"`
+	Comment     string    `json:"comment,omitempty"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+}
+
+// matches reports whether the silence suppresses the notification at t. A
+// notification reported by several regions is suppressed when all of them
+// are silenced.
+func (s Silence) matches(n Notification, t time.Time) bool {
+	if t.Before(s.StartsAt) || !t.Before(s.EndsAt) {
+		return false
+	}
+	if s.WorkspaceID != "" && s.WorkspaceID != n.WorkspaceID {
+		return false
+	}
+	if s.MonitorID != "" && s.MonitorID != n.MonitorID {
+		return false
+	}
+	if s.Tag != "" && !contains(n.Tags, s.Tag) {
+		return false
+	}
+	if s.Region != "" {
+		regions := n.Regions
+		if len(regions) == 0 {
+			regions = []string{n.Region}
+		}
+		for _, region := range regions {
+			if region != s.Region {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// contains reports whether the values contain the value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Silencer suppresses the notifications of the silenced monitors, and sends
+// the other ones to the next notifier. The silences are kept in memory, and
+// expire on their own.
+type Silencer struct {
+	next Notifier
+
+	mu       sync.Mutex
+	silences map[string]Silence
+}
+
+func NewSilencer(next Notifier) *Silencer {
+	return &Silencer{next: next, silences: map[string]Silence{}}
+}
+
+// Silence adds the silence, starting now when its start is unset, and
+// returns it with its id.
+func (s *Silencer) Silence(silence Silence) (Silence, error) {
+	if silence.MonitorID == "" && silence.Tag == "" && silence.Region == "" {
+		return Silence{}, errors.New("missing monitorId, tag or region")
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+	if !silence.EndsAt.After(silence.StartsAt) || !silence.EndsAt.After(time.Now()) {
+		return Silence{}, errors.New("silence ending before it starts, or in the past")
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Silence{}, fmt.Errorf("unable to generate id: %w", err)
+	}
+	silence.ID = hex.EncodeToString(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.silences[silence.ID] = silence
+	return silence, nil
+}
+
+// Expire ends the silence, reporting whether it existed.
+func (s *Silencer) Expire(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.silences[id]
+	delete(s.silences, id)
+	return ok
+}
+
+// List returns the silences not expired at t, sorted by end, and forgets
+// the expired ones.
+func (s *Silencer) List(t time.Time) []Silence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silences := make([]Silence, 0, len(s.silences))
+	for id, silence := range s.silences {
+		if !t.Before(silence.EndsAt) {
+			delete(s.silences, id)
+			continue
+		}
+		silences = append(silences, silence)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].EndsAt.Before(silences[j].EndsAt)
+	})
+
+	return silences
+}
+
+// silenced returns the silence suppressing the notification at t, if any.
+func (s *Silencer) silenced(n Notification, t time.Time) (Silence, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, silence := range s.silences {
+		if !t.Before(silence.EndsAt) {
+			delete(s.silences, id)
+			continue
+		}
+		if silence.matches(n, t) {
+			return silence, true
+		}
+	}
+
+	return Silence{}, false
+}
+
+func (s *Silencer) Notify(ctx context.Context, n Notification) error {
+	if silence, ok := s.silenced(n, time.Now()); ok {
+		log.Ctx(ctx).Info().Str("monitor", n.MonitorID).Str("silence", silence.ID).Msg("monitor silenced, suppressing the notification")
+		return nil
+	}
+
+	return s.next.Notify(ctx, n)
+}
+
+// Register adds the endpoints listing, creating and expiring the silences.
+func (s *Silencer) Register(router gin.IRouter) {
+	router.GET("/silences", s.list)
+	router.POST("/silences", s.create)
+	router.DELETE("/silences/:id", s.expire)
+}
+
+// Silences is the body of the list of the active silences.
+type Silences struct {
+	Silences []Silence `json:"silences"`
+}
+
+// SilenceRequest is the body of the creation of a silence.
+type SilenceRequest struct {
+	Silence
+	// Duration, e.g. "2h", sets the end of the silence from its start.
+	Duration string `json:"duration,omitempty"`
+}
+
+func (s *Silencer) list(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Silences{Silences: s.List(time.Now())})
+}
+
+func (s *Silencer) create(ctx *gin.Context) {
+	var req SilenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration"})
+			return
+		}
+		if req.StartsAt.IsZero() {
+			req.StartsAt = time.Now()
+		}
+		req.EndsAt = req.StartsAt.Add(duration)
+	}
+
+	silence, err := s.Silence(req.Silence)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, silence)
+}
+
+func (s *Silencer) expire(ctx *gin.Context) {
+	if !s.Expire(ctx.Param("id")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "silence not found"})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilencer(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	var notified []notify.Notification
+	silencer := notify.NewSilencer(notifierFunc(func(ctx context.Context, n notify.Notification) error {
+		notified = append(notified, n)
+		return nil
+	}))
+	router := gin.New()
+	silencer.Register(router)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	ctx := context.Background()
+
+	t.Run("it should reject the silences without matcher", func(t *testing.T) {
+		w := do(http.MethodPost, "/silences", `{"duration":"1h"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	var silence notify.Silence
+	t.Run("it should suppress the notifications of the silenced monitors", func(t *testing.T) {
+		w := do(http.MethodPost, "/silences", `{"tag":"staging","duration":"1h","comment":"migration"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &silence))
+		require.NotEmpty(t, silence.ID)
+
+		require.NoError(t, silencer.Notify(ctx, notify.Notification{MonitorID: "1", Tags: []string{"staging"}, Status: "error"}))
+		require.NoError(t, silencer.Notify(ctx, notify.Notification{MonitorID: "2", Tags: []string{"production"}, Status: "error"}))
+		require.Len(t, notified, 1)
+		require.Equal(t, "2", notified[0].MonitorID)
+	})
+
+	t.Run("it should suppress a region only when every region is silenced", func(t *testing.T) {
+		_, err := silencer.Silence(notify.Silence{Region: "ams", EndsAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+
+		notified = nil
+		require.NoError(t, silencer.Notify(ctx, notify.Notification{MonitorID: "3", Region: "ams", Status: "error"}))
+		require.NoError(t, silencer.Notify(ctx, notify.Notification{MonitorID: "3", Region: "ams,iad", Regions: []string{"ams", "iad"}, Status: "error"}))
+		require.Len(t, notified, 1)
+	})
+
+	t.Run("it should list and expire the silences", func(t *testing.T) {
+		require.Len(t, silencer.List(time.Now()), 2)
+
+		w := do(http.MethodDelete, "/silences/"+silence.ID, "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Len(t, silencer.List(time.Now()), 1)
+		require.Empty(t, silencer.List(time.Now().Add(2*time.Hour)), "the silences should expire")
+
+		_, err := silencer.Silence(notify.Silence{MonitorID: "1", EndsAt: time.Now().Add(-time.Minute)})
+		require.Error(t, err, "the silences should not end in the past")
+	})
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const slackAPI = "https://slack.com/api/chat.postMessage"
+
+type slack struct {
+	client    *http.Client
+	url       string
+	token     string
+	channel   string
+	config    map[string]string
+	templates templates
+}
+
+// NewSlack returns a notifier posting to the Slack incoming webhook "url" of
+// the config, or with the bot "token" to its "channel".
+func NewSlack(client *http.Client, config map[string]string) (Notifier, error) {
+	s := slack{client: client, url: config["url"], token: config["token"], channel: config["channel"], config: config}
+	if s.url == "" && (s.token == "" || s.channel == "") {
+		return nil, errors.New("missing url, or token and channel")
+	}
+
+	var err error
+	if s.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string         `json:"type"`
+	Text     *slackText     `json:"text,omitempty"`
+	Fields   []slackText    `json:"fields,omitempty"`
+	Elements []slackElement `json:"elements,omitempty"`
+}
+
+type slackElement struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+type slackMessage struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"`
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+func (s slack) message(ctx context.Context, n Notification) slackMessage {
+	emoji := ":large_green_circle:"
+	if n.Down() {
+		emoji = ":red_circle:"
+	}
+
+	title := s.templates.Title(ctx, n)
+	blocks := []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: emoji + " " + title}}}
+	if body, ok := s.templates.Body(ctx, n); ok {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: body}})
+	} else {
+		blocks = append(blocks, s.details(n)...)
+	}
+	if url := link(s.config, n); url != "" {
+		blocks = append(blocks, slackBlock{Type: "actions", Elements: []slackElement{{
+			Type: "button",
+			Text: slackText{Type: "plain_text", Text: "View monitor"},
+			URL:  url,
+		}}})
+	}
+
+	return slackMessage{Channel: s.channel, Text: title, Blocks: blocks}
+}
+
+// details are the default blocks detailing the notification.
+func (s slack) details(n Notification) []slackBlock {
+	fields := []slackText{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*URL*\n%s", n.URL)},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Region*\n%s", n.Region)},
+	}
+	if n.StatusCode != 0 {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*Status code*\n%d", n.StatusCode)})
+	}
+	if n.Latency != 0 {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*Latency*\n%d ms", n.Latency)})
+	}
+
+	blocks := []slackBlock{{Type: "section", Fields: fields}}
+	if n.Message != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "```" + n.Message + "```"}})
+	}
+	if text := summary(n); text != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+
+	return blocks
+}
+
+func (s slack) Notify(ctx context.Context, n Notification) error {
+	message := s.message(ctx, n)
+	if s.url != "" {
+		message.Channel = ""
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	if s.url != "" {
+		return post(ctx, s.client, s.url, payload, nil)
+	}
+
+	// The Web API answers 200 with the outcome in the body.
+	var response struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := postJSON(ctx, s.client, slackAPI, payload, map[string]string{"Authorization": "Bearer " + s.token}, &response); err != nil {
+		return err
+	}
+	if !response.OK {
+		return fmt.Errorf("unable to post message: %s", response.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlack(t *testing.T) {
+	t.Parallel()
+
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	_, err := notify.NewSlack(server.Client(), map[string]string{"token": "xoxb"})
+	require.Error(t, err, "the bot should require a channel")
+
+	slack, err := notify.NewSlack(server.Client(), map[string]string{
+		"url":  server.URL,
+		"link": "https://www.openstatus.dev/app/{workspaceId}/monitors/{monitorId}",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, slack.Notify(context.Background(), notify.Notification{
+		WorkspaceID: "1",
+		MonitorID:   "2",
+		URL:         "https://openstat.us",
+		Region:      "ams",
+		Status:      "error",
+		StatusCode:  500,
+		Latency:     42,
+		Message:     "Internal Server Error",
+	}))
+
+	require.Equal(t, "Monitor 2 is down", received["text"])
+	blocks := received["blocks"].([]any)
+	require.Len(t, blocks, 4)
+	require.Contains(t, blocks[2].(map[string]any)["text"].(map[string]any)["text"], "Internal Server Error")
+	button := blocks[3].(map[string]any)["elements"].([]any)[0].(map[string]any)
+	require.Equal(t, "https://www.openstatus.dev/app/1/monitors/2", button["url"])
+}
+
+func TestSlackTemplates(t *testing.T) {
+	t.Parallel()
+
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	slack, err := notify.NewSlack(server.Client(), map[string]string{
+		"url":   server.URL,
+		"title": "[{{ upper .Region }}] {{ .Title }}",
+		"body":  "{{ .StatusCode }} after {{ .Latency }} ms, see the runbook",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, slack.Notify(context.Background(), notify.Notification{
+		MonitorID:  "2",
+		Region:     "ams",
+		Status:     "error",
+		StatusCode: 500,
+		Latency:    42,
+	}))
+
+	require.Equal(t, "[AMS] Monitor 2 is down", received["text"])
+	blocks := received["blocks"].([]any)
+	require.Len(t, blocks, 2, "the body should replace the default details")
+	require.Equal(t, "500 after 42 ms, see the runbook", blocks[1].(map[string]any)["text"].(map[string]any)["text"])
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type teams struct {
+	client    *http.Client
+	url       string
+	config    map[string]string
+	templates templates
+}
+
+// NewTeams returns a notifier posting adaptive cards to the Microsoft Teams
+// incoming webhook "url" of the config.
+func NewTeams(client *http.Client, config map[string]string) (Notifier, error) {
+	if config["url"] == "" {
+		return nil, errors.New("missing url")
+	}
+
+	templates, err := newTemplates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return teams{client: client, url: config["url"], config: config, templates: templates}, nil
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func (t teams) card(ctx context.Context, n Notification) map[string]any {
+	color := "Good"
+	if n.Down() {
+		color = "Attention"
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "size": "Large", "weight": "Bolder", "color": color, "text": t.templates.Title(ctx, n)},
+	}
+	if text, ok := t.templates.Body(ctx, n); ok {
+		body = append(body, map[string]any{"type": "TextBlock", "wrap": true, "text": text})
+	} else {
+		body = append(body, t.details(n)...)
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if url := link(t.config, n); url != "" {
+		card["actions"] = []map[string]any{{"type": "Action.OpenUrl", "title": "View monitor", "url": url}}
+	}
+
+	return card
+}
+
+// details are the default elements of the card detailing the notification.
+func (t teams) details(n Notification) []map[string]any {
+	facts := []teamsFact{{Title: "URL", Value: n.URL}, {Title: "Region", Value: n.Region}}
+	if n.StatusCode != 0 {
+		facts = append(facts, teamsFact{Title: "Status code", Value: strconv.Itoa(n.StatusCode)})
+	}
+	if n.Latency != 0 {
+		facts = append(facts, teamsFact{Title: "Latency", Value: fmt.Sprintf("%d ms", n.Latency)})
+	}
+
+	elements := []map[string]any{{"type": "FactSet", "facts": facts}}
+	if n.Message != "" {
+		elements = append(elements, map[string]any{"type": "TextBlock", "wrap": true, "fontType": "Monospace", "text": n.Message})
+	}
+	if text := summary(n); text != "" {
+		elements = append(elements, map[string]any{"type": "TextBlock", "wrap": true, "text": text})
+	}
+
+	return elements
+}
+
+func (t teams) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     t.card(ctx, n),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode card: %w", err)
+	}
+
+	return post(ctx, t.client, t.url, payload, nil)
+}
@@ -0,0 +1,44 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeams(t *testing.T) {
+	t.Parallel()
+
+	var received struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string           `json:"type"`
+				Body []map[string]any `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	teams, err := notify.NewTeams(server.Client(), map[string]string{"url": server.URL})
+	require.NoError(t, err)
+	require.NoError(t, teams.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error", Message: "timeout"}))
+
+	require.Equal(t, "message", received.Type)
+	require.Len(t, received.Attachments, 1)
+	require.Equal(t, "application/vnd.microsoft.card.adaptive", received.Attachments[0].ContentType)
+	card := received.Attachments[0].Content
+	require.Equal(t, "AdaptiveCard", card.Type)
+	require.Equal(t, "Monitor 1 is down", card.Body[0]["text"])
+	require.Equal(t, "Attention", card.Body[0]["color"])
+	require.Equal(t, "timeout", card.Body[2]["text"])
+}
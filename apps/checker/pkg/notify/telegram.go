@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const telegramAPI = "https://api.telegram.org"
+
+type telegram struct {
+	client    *http.Client
+	url       string
+	token     string
+	chatID    string
+	config    map[string]string
+	templates templates
+}
+
+// NewTelegram returns a notifier sending messages with the bot "token" of
+// the config to its "chatId". "url" overrides the Bot API, e.g. for a local
+// Bot API server.
+func NewTelegram(client *http.Client, config map[string]string) (Notifier, error) {
+	t := telegram{client: client, url: config["url"], token: config["token"], chatID: config["chatId"], config: config}
+	if t.token == "" || t.chatID == "" {
+		return nil, errors.New("missing token or chatId")
+	}
+	if t.url == "" {
+		t.url = telegramAPI
+	}
+
+	var err error
+	if t.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// text is the HTML message of the notification, the templated body being
+// sent as plain text.
+func (t telegram) text(ctx context.Context, n Notification) string {
+	emoji := "🟢"
+	switch n.Status {
+	case "error":
+		emoji = "🔴"
+	case "degraded":
+		emoji = "🟡"
+	case "throttled":
+		emoji = "🔕"
+	case "certificate":
+		emoji = "🔐"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s <b>%s</b>\n", emoji, html.EscapeString(t.templates.Title(ctx, n)))
+	if body, ok := t.templates.Body(ctx, n); ok {
+		fmt.Fprintf(&b, "\n%s", html.EscapeString(body))
+	} else {
+		t.details(&b, n)
+	}
+	if url := link(t.config, n); url != "" {
+		fmt.Fprintf(&b, "\n\n<a href=\"%s\">View monitor</a>", html.EscapeString(url))
+	}
+
+	return b.String()
+}
+
+// details writes the default details of the notification.
+func (t telegram) details(b *strings.Builder, n Notification) {
+	if n.URL != "" {
+		fmt.Fprintf(b, "\nURL: %s", html.EscapeString(n.URL))
+	}
+	fmt.Fprintf(b, "\nRegion: %s", html.EscapeString(n.Region))
+	if n.StatusCode != 0 {
+		fmt.Fprintf(b, "\nStatus code: %d", n.StatusCode)
+	}
+	if n.Latency != 0 {
+		fmt.Fprintf(b, "\nLatency: %d ms", n.Latency)
+	}
+	if n.Message != "" {
+		fmt.Fprintf(b, "\n\n<pre>%s</pre>", html.EscapeString(n.Message))
+	}
+	if text := summary(n); text != "" {
+		fmt.Fprintf(b, "\n\n%s", html.EscapeString(text))
+	}
+}
+
+func (t telegram) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(map[string]any{
+		"chat_id":                  t.chatID,
+		"text":                     t.text(ctx, n),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	var response struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := postJSON(ctx, t.client, fmt.Sprintf("%s/bot%s/sendMessage", t.url, t.token), payload, nil, &response); err != nil {
+		// The url of the Bot API holds the token, which must not be logged.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = strings.ReplaceAll(urlErr.URL, t.token, "<token>")
+			return fmt.Errorf("unable to send request: %w", urlErr)
+		}
+		return err
+	}
+	if !response.OK {
+		return fmt.Errorf("unable to send message: %s", response.Description)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegram(t *testing.T) {
+	t.Parallel()
+
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/bot123:abc/sendMessage", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	_, err := notify.NewTelegram(server.Client(), map[string]string{"token": "123:abc"})
+	require.Error(t, err, "the chat should be required")
+
+	telegram, err := notify.NewTelegram(server.Client(), map[string]string{"token": "123:abc", "chatId": "42", "url": server.URL})
+	require.NoError(t, err)
+
+	require.NoError(t, telegram.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "degraded", Message: "<slow>"}))
+	require.Equal(t, "42", received["chat_id"])
+	require.Equal(t, "HTML", received["parse_mode"])
+	require.Contains(t, received["text"], "Monitor 1 is degraded")
+	require.Contains(t, received["text"], "&lt;slow&gt;", "the message should be escaped")
+
+	t.Run("it should not return the token in the errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		telegram, err := notify.NewTelegram(http.DefaultClient, map[string]string{"token": "123:abc", "chatId": "42", "url": server.URL})
+		require.NoError(t, err)
+
+		err = telegram.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"})
+		require.Error(t, err)
+		require.NotContains(t, err.Error(), "123:abc")
+	})
+}
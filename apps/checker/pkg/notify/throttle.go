@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Throttle limits the notifications of a channel to Limit per Period, e.g.
+// "5m", for each workspace.
+type Throttle struct {
+	Limit  int    `json:"limit"`
+	Period string `json:"period"`
+}
+
+// window counts the notifications of a workspace during a period.
+type window struct {
+	start      time.Time
+	count      int
+	suppressed map[string]int
+}
+
+// throttle throttles the notifications of a channel, notifying the
+// suppressed ones at the end of the period.
+type throttle struct {
+	limit  int
+	period time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+func newThrottle(t Throttle) (*throttle, error) {
+	period, err := time.ParseDuration(t.Period)
+	if err != nil || period <= 0 {
+		return nil, fmt.Errorf("invalid throttle period %q", t.Period)
+	}
+	if t.Limit < 1 {
+		return nil, fmt.Errorf("invalid throttle limit %d", t.Limit)
+	}
+
+	return &throttle{limit: t.Limit, period: period, windows: map[string]*window{}}, nil
+}
+
+// allow reports whether the notification is within the limit. The first
+// notification suppressed during a period schedules the overflow summary,
+// sent to the notifier at the end of the period.
+func (t *throttle) allow(ctx context.Context, n Notification, notifier Notifier) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	w, ok := t.windows[n.WorkspaceID]
+	if !ok || now.Sub(w.start) >= t.period {
+		w = &window{start: now, suppressed: map[string]int{}}
+		t.windows[n.WorkspaceID] = w
+	}
+
+	w.count++
+	if w.count <= t.limit {
+		return true
+	}
+
+	if len(w.suppressed) == 0 {
+		ctx := context.WithoutCancel(ctx)
+		time.AfterFunc(t.period-now.Sub(w.start), func() {
+			t.overflow(ctx, n.WorkspaceID, w, notifier)
+		})
+	}
+	w.suppressed[n.MonitorID]++
+
+	return false
+}
+
+// overflow notifies the summary of the notifications suppressed during the
+// window.
+func (t *throttle) overflow(ctx context.Context, workspaceID string, w *window, notifier Notifier) {
+	t.mu.Lock()
+	total := 0
+	monitors := make([]string, 0, len(w.suppressed))
+	for monitorID, count := range w.suppressed {
+		total += count
+		monitors = append(monitors, monitorID)
+	}
+	t.mu.Unlock()
+	sort.Strings(monitors)
+
+	err := notifier.Notify(ctx, Notification{
+		WorkspaceID: workspaceID,
+		Status:      "throttled",
+		Message:     fmt.Sprintf("%d notifications suppressed in %s, of the monitors %s", total, t.period, strings.Join(monitors, ", ")),
+		Timestamp:   time.Now().UTC().UnixMilli(),
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("workspace", workspaceID).Msg("failed to notify the overflow summary")
+	}
+}
@@ -0,0 +1,49 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := &recorder{notifications: map[string][]notify.Notification{}}
+	registry := notify.NewRegistry(http.DefaultClient)
+	registry.Register("test", r.factory)
+	dispatcher := notify.NewDispatcher(registry)
+
+	require.Error(t, dispatcher.Set([]notify.Channel{{Name: "slack", Type: "test", Throttle: &notify.Throttle{Limit: 0, Period: "1m"}}}))
+	require.NoError(t, dispatcher.Set([]notify.Channel{
+		{Name: "slack", Type: "test", Config: map[string]string{"id": "slack"}, Throttle: &notify.Throttle{Limit: 2, Period: "100ms"}},
+	}))
+
+	for _, monitorID := range []string{"1", "2", "3", "3"} {
+		require.NoError(t, dispatcher.Notify(ctx, notify.Notification{WorkspaceID: "1", MonitorID: monitorID, Status: "error"}))
+	}
+	require.NoError(t, dispatcher.Notify(ctx, notify.Notification{WorkspaceID: "2", MonitorID: "4", Status: "error"}))
+
+	r.mu.Lock()
+	require.Len(t, r.notifications["slack"], 3, "the workspaces should be throttled separately")
+	r.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.notifications["slack"]) == 4
+	}, time.Second, 10*time.Millisecond, "the overflow summary should be sent at the end of the period")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summary := r.notifications["slack"][3]
+	require.True(t, summary.Throttled())
+	require.Equal(t, "1", summary.WorkspaceID)
+	require.Contains(t, summary.Message, "2 notifications suppressed")
+	require.Contains(t, summary.Message, "monitors 3")
+}
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oncall"
+	"github.com/rs/zerolog/log"
+)
+
+const twilioAPI = "https://api.twilio.com"
+
+type twilio struct {
+	client     *http.Client
+	url        string
+	accountSID string
+	authToken  string
+	from       string
+	call       string
+	config     map[string]string
+	templates  templates
+	schedule   oncall.Schedule
+}
+
+// NewTwilio returns a notifier sending SMS with the Twilio "accountSid" and
+// "authToken" of the config, from the "from" number to the comma separated
+// "to" numbers, or to the "to:<monitorId>" numbers of the monitor. With
+// "call" set to "critical", the recipients are also called when a critical
+// monitor goes down, with "always" when any monitor goes down. With an
+// on-call schedule, the iCalendar feed "ical" or the Grafana OnCall
+// "scheduleId" of the "grafanaUrl" API with the "grafanaToken", the persons
+// on call are texted at their "number:<person>" instead of "to".
+func NewTwilio(client *http.Client, config map[string]string) (Notifier, error) {
+	t := twilio{
+		client:     client,
+		url:        config["url"],
+		accountSID: config["accountSid"],
+		authToken:  config["authToken"],
+		from:       config["from"],
+		call:       config["call"],
+		config:     config,
+	}
+	if t.accountSID == "" || t.authToken == "" || t.from == "" {
+		return nil, errors.New("missing accountSid, authToken or from")
+	}
+	if t.url == "" {
+		t.url = twilioAPI
+	}
+	switch t.call {
+	case "", "never", "critical", "always":
+	default:
+		return nil, fmt.Errorf("invalid call %q", t.call)
+	}
+	switch {
+	case config["ical"] != "":
+		t.schedule = oncall.NewICal(client, config["ical"], 5*time.Minute)
+	case config["scheduleId"] != "":
+		if config["grafanaUrl"] == "" || config["grafanaToken"] == "" {
+			return nil, errors.New("missing grafanaUrl or grafanaToken")
+		}
+		t.schedule = oncall.NewGrafana(client, config["grafanaUrl"], config["grafanaToken"], config["scheduleId"])
+	}
+
+	var err error
+	if t.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// recipients returns the numbers notified of the monitor: its own numbers,
+// or the numbers of the persons on call, or the default ones when nobody
+// known is on call.
+func (t twilio) recipients(ctx context.Context, monitorID string) []string {
+	if to, ok := t.config["to:"+monitorID]; ok {
+		return numbers(to)
+	}
+	if t.schedule != nil {
+		if on := t.onCall(ctx); len(on) > 0 {
+			return on
+		}
+	}
+
+	return numbers(t.config["to"])
+}
+
+// onCall returns the numbers of the persons on call.
+func (t twilio) onCall(ctx context.Context) []string {
+	persons, err := t.schedule.OnCall(ctx, time.Now())
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("unable to get the persons on call, texting the default recipients")
+		return nil
+	}
+
+	var on []string
+	for _, person := range persons {
+		number, ok := t.config["number:"+person]
+		if !ok {
+			log.Ctx(ctx).Warn().Str("person", person).Msg("no number for the person on call")
+			continue
+		}
+		on = append(on, numbers(number)...)
+	}
+
+	return on
+}
+
+// numbers parses the comma separated numbers.
+func numbers(value string) []string {
+	var numbers []string
+	for _, number := range strings.Split(value, ",") {
+		if number = strings.TrimSpace(number); number != "" {
+			numbers = append(numbers, number)
+		}
+	}
+
+	return numbers
+}
+
+func (t twilio) calls(n Notification) bool {
+	if !n.Down() {
+		return false
+	}
+
+	switch t.call {
+	case "always":
+		return true
+	case "critical":
+		return n.Severity == "critical"
+	default:
+		return false
+	}
+}
+
+func (t twilio) Notify(ctx context.Context, n Notification) error {
+	recipients := t.recipients(ctx, n.MonitorID)
+	if len(recipients) == 0 {
+		return fmt.Errorf("no recipient for monitor %s", n.MonitorID)
+	}
+
+	title := t.templates.Title(ctx, n)
+	text, ok := t.templates.Body(ctx, n)
+	if !ok {
+		text = title
+		if n.Message != "" {
+			text += ": " + n.Message
+		}
+		if summary := summary(n); summary != "" {
+			text += ". " + summary
+		}
+		if url := link(t.config, n); url != "" {
+			text += " " + url
+		}
+	}
+
+	var say strings.Builder
+	xml.EscapeText(&say, []byte(title))
+	twiml := fmt.Sprintf("<Response><Say>%s</Say><Pause length=\"1\"/><Say>%s</Say></Response>", say.String(), say.String())
+
+	var errs []error
+	for _, to := range recipients {
+		if err := t.send(ctx, "Messages", url.Values{"To": {to}, "From": {t.from}, "Body": {text}}); err != nil {
+			errs = append(errs, fmt.Errorf("unable to text %s: %w", to, err))
+		}
+		if t.calls(n) {
+			if err := t.send(ctx, "Calls", url.Values{"To": {to}, "From": {t.from}, "Twiml": {twiml}}); err != nil {
+				errs = append(errs, fmt.Errorf("unable to call %s: %w", to, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send creates a resource, "Messages" or "Calls", of the account.
+func (t twilio) send(ctx context.Context, resource string, form url.Values) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/%s.json", t.url, url.PathEscape(t.accountSID), resource)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package notify_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwilio(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+		calls    []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		require.Equal(t, "AC123", user)
+		require.Equal(t, "secret", password)
+		require.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/2010-04-01/Accounts/AC123/Messages.json":
+			messages = append(messages, r.PostForm.Get("To"))
+		case "/2010-04-01/Accounts/AC123/Calls.json":
+			calls = append(calls, r.PostForm.Get("To"))
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	twilio, err := notify.NewTwilio(server.Client(), map[string]string{
+		"url":        server.URL,
+		"accountSid": "AC123",
+		"authToken":  "secret",
+		"from":       "+15550000000",
+		"to":         "+15550000001",
+		"to:2":       "+15550000002, +15550000003",
+		"call":       "critical",
+	})
+	require.NoError(t, err)
+
+	t.Run("it should text the recipients", func(t *testing.T) {
+		require.NoError(t, twilio.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Equal(t, []string{"+15550000001"}, messages)
+		require.Empty(t, calls)
+	})
+
+	t.Run("it should text and call the recipients of a critical monitor", func(t *testing.T) {
+		messages, calls = nil, nil
+		require.NoError(t, twilio.Notify(context.Background(), notify.Notification{MonitorID: "2", Status: "error", Severity: "critical"}))
+		require.Equal(t, []string{"+15550000002", "+15550000003"}, messages)
+		require.Equal(t, []string{"+15550000002", "+15550000003"}, calls)
+	})
+
+	t.Run("it should not call on recovery", func(t *testing.T) {
+		messages, calls = nil, nil
+		require.NoError(t, twilio.Notify(context.Background(), notify.Notification{MonitorID: "2", Status: "active", Severity: "critical"}))
+		require.Len(t, messages, 2)
+		require.Empty(t, calls)
+	})
+
+	t.Run("it should text the persons on call", func(t *testing.T) {
+		calendar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now().UTC().Add(-time.Hour).Format("20060102T150405Z")
+			end := time.Now().UTC().Add(time.Hour).Format("20060102T150405Z")
+			fmt.Fprintf(w, "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:%s\r\nDTEND:%s\r\nSUMMARY:alice\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", start, end)
+		}))
+		defer calendar.Close()
+
+		onCall, err := notify.NewTwilio(server.Client(), map[string]string{
+			"url":          server.URL,
+			"accountSid":   "AC123",
+			"authToken":    "secret",
+			"from":         "+15550000000",
+			"to":           "+15550000001",
+			"ical":         calendar.URL,
+			"number:alice": "+15550000004",
+		})
+		require.NoError(t, err)
+
+		messages, calls = nil, nil
+		require.NoError(t, onCall.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Equal(t, []string{"+15550000004"}, messages)
+	})
+}
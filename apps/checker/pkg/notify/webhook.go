@@ -0,0 +1,212 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+)
+
+type webhook struct {
+	client      *http.Client
+	url         string
+	secret      string
+	retries     uint64
+	contentType string
+	format      string
+	receiver    string
+	templates   templates
+}
+
+// NewWebhook returns a notifier posting the JSON notifications to the "url"
+// of the config, retried "retries" times (default 3) on network and server
+// errors. With a "secret", the payloads are signed. With a "body" template,
+// the rendered body is posted instead, as "contentType" (default JSON).
+// With the "alertmanager" "format", the notifications are posted as the
+// webhooks of a Prometheus Alertmanager "receiver", the templates filling
+// the summary and the description of the alerts.
+func NewWebhook(client *http.Client, config map[string]string) (Notifier, error) {
+	w := webhook{
+		client:      client,
+		url:         config["url"],
+		secret:      config["secret"],
+		retries:     3,
+		contentType: config["contentType"],
+		format:      config["format"],
+		receiver:    config["receiver"],
+	}
+	if w.url == "" {
+		return nil, errors.New("missing url")
+	}
+	switch w.format {
+	case "", "json", "alertmanager":
+	default:
+		return nil, fmt.Errorf("invalid format %q", w.format)
+	}
+	if w.receiver == "" {
+		w.receiver = "openstatus"
+	}
+	if value := config["retries"]; value != "" {
+		retries, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retries %q", value)
+		}
+		w.retries = retries
+	}
+
+	var err error
+	if w.templates, err = newTemplates(config); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Sign returns the signature of the payload sent at the unix timestamp, the
+// hex encoded HMAC-SHA256 of "<timestamp>.<payload>" with the secret.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// event is the type of the webhook of the notification.
+func event(n Notification) string {
+	switch n.Status {
+	case "error":
+		return "monitor.down"
+	case "degraded":
+		return "monitor.degraded"
+	case "throttled":
+		return "notifications.throttled"
+	case "certificate":
+		return "certificate.expiring"
+	default:
+		return "monitor.recovered"
+	}
+}
+
+// payload returns the payload of the notification, and whether it is a
+// templated body.
+func (w webhook) payload(ctx context.Context, n Notification) ([]byte, bool, error) {
+	if w.format == "alertmanager" {
+		payload, err := alertmanager(ctx, w.templates, w.receiver, n)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to encode alert: %w", err)
+		}
+		return payload, false, nil
+	}
+	if body, ok := w.templates.Body(ctx, n); ok {
+		return []byte(body), true, nil
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to encode notification: %w", err)
+	}
+
+	return payload, false, nil
+}
+
+func (w webhook) Notify(ctx context.Context, n Notification) error {
+	// The overflow summaries are not alerts.
+	if w.format == "alertmanager" && n.Throttled() {
+		return nil
+	}
+
+	payload, templated, err := w.payload(ctx, n)
+	if err != nil {
+		return err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("unable to generate delivery id: %w", err)
+	}
+	headers := map[string]string{
+		"X-OpenStatus-Event":    event(n),
+		"X-OpenStatus-Delivery": hex.EncodeToString(id),
+	}
+	if templated && w.contentType != "" {
+		headers["Content-Type"] = w.contentType
+	}
+
+	// The retries of a delivery share its id, and are signed again.
+	op := func() error {
+		if w.secret != "" {
+			timestamp := time.Now().Unix()
+			headers["X-OpenStatus-Signature"] = fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(w.secret, timestamp, payload))
+		}
+
+		err := post(ctx, w.client, w.url, payload, headers)
+		var status *statusError
+		if errors.As(err, &status) && !status.retryable() {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	return backoff.Retry(op, backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), w.retries), ctx))
+}
+
+// statusError is the error of an unexpected status code.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.code)
+}
+
+// retryable reports whether the request may succeed later.
+func (e *statusError) retryable() bool {
+	return e.code >= http.StatusInternalServerError || e.code == http.StatusTooManyRequests || e.code == http.StatusRequestTimeout
+}
+
+// post posts the JSON payload, failing on a non 2xx response.
+func post(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string) error {
+	return postJSON(ctx, client, url, payload, headers, nil)
+}
+
+// postJSON posts the JSON payload and decodes the JSON response into v, if
+// not nil, failing on a non 2xx response.
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &statusError{code: resp.StatusCode}
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("unable to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should require the url", func(t *testing.T) {
+		_, err := notify.NewWebhook(http.DefaultClient, map[string]string{})
+		require.Error(t, err)
+	})
+
+	t.Run("it should post the signed notification", func(t *testing.T) {
+		var received notify.Notification
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &received))
+			require.Equal(t, "monitor.down", r.Header.Get("X-OpenStatus-Event"))
+			require.NotEmpty(t, r.Header.Get("X-OpenStatus-Delivery"))
+
+			var timestamp, signature string
+			for _, part := range strings.Split(r.Header.Get("X-OpenStatus-Signature"), ",") {
+				key, value, _ := strings.Cut(part, "=")
+				switch key {
+				case "t":
+					timestamp = value
+				case "v1":
+					signature = value
+				}
+			}
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			require.NoError(t, err)
+			require.Equal(t, notify.Sign("secret", unix, body), signature)
+		}))
+		defer server.Close()
+
+		webhook, err := notify.NewWebhook(server.Client(), map[string]string{"url": server.URL, "secret": "secret"})
+		require.NoError(t, err)
+		require.NoError(t, webhook.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error"}))
+		require.Equal(t, "1", received.MonitorID)
+	})
+
+	t.Run("it should retry the server errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer server.Close()
+
+		webhook, err := notify.NewWebhook(server.Client(), map[string]string{"url": server.URL, "retries": "1"})
+		require.NoError(t, err)
+		require.NoError(t, webhook.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "active"}))
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("it should not retry the client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		webhook, err := notify.NewWebhook(server.Client(), map[string]string{"url": server.URL})
+		require.NoError(t, err)
+		require.EqualError(t, webhook.Notify(context.Background(), notify.Notification{MonitorID: "1"}), fmt.Sprintf("unexpected status code: %d", http.StatusUnauthorized))
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("it should post the templated body", func(t *testing.T) {
+		var received, contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			received, contentType = string(body), r.Header.Get("Content-Type")
+		}))
+		defer server.Close()
+
+		_, err := notify.NewWebhook(server.Client(), map[string]string{"url": server.URL, "body": "{{ .Region"})
+		require.Error(t, err, "the template should be valid")
+
+		webhook, err := notify.NewWebhook(server.Client(), map[string]string{
+			"url":         server.URL,
+			"contentType": "text/plain",
+			"body":        "{{ .MonitorID }} {{ upper .Status }} in {{ .Region }} after {{ duration .Latency }}",
+		})
+		require.NoError(t, err)
+		require.NoError(t, webhook.Notify(context.Background(), notify.Notification{MonitorID: "1", Status: "error", Region: "ams", Latency: 1500}))
+		require.Equal(t, "1 ERROR in ams after 1.5s", received)
+		require.Equal(t, "text/plain", contentType)
+	})
+}
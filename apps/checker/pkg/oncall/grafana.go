@@ -0,0 +1,74 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Grafana is a schedule of Grafana OnCall, whose persons are the usernames
+// of the users on call.
+type Grafana struct {
+	client     *http.Client
+	url        string
+	token      string
+	scheduleID string
+}
+
+// NewGrafana returns the schedule of the Grafana OnCall API at url, e.g.
+// https://oncall-prod-us-central-0.grafana.net/oncall, authenticated with
+// the API token.
+func NewGrafana(client *http.Client, url, token, scheduleID string) *Grafana {
+	return &Grafana{client: client, url: strings.TrimSuffix(url, "/"), token: token, scheduleID: scheduleID}
+}
+
+// OnCall returns the users on call now, the API not telling who is on call
+// at another time.
+func (g *Grafana) OnCall(ctx context.Context, _ time.Time) ([]string, error) {
+	var schedule struct {
+		OnCallNow []string `json:"on_call_now"`
+	}
+	if err := g.get(ctx, "/api/v1/schedules/"+url.PathEscape(g.scheduleID)+"/", &schedule); err != nil {
+		return nil, fmt.Errorf("unable to get schedule: %w", err)
+	}
+
+	persons := make([]string, 0, len(schedule.OnCallNow))
+	for _, userID := range schedule.OnCallNow {
+		var user struct {
+			Username string `json:"username"`
+		}
+		if err := g.get(ctx, "/api/v1/users/"+url.PathEscape(userID)+"/", &user); err != nil {
+			return nil, fmt.Errorf("unable to get user %s: %w", userID, err)
+		}
+		persons = append(persons, user.Username)
+	}
+
+	return persons, nil
+}
+
+func (g *Grafana) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Authorization", g.token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+package oncall
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule tells who is on call.
+type Schedule interface {
+	// OnCall returns the persons on call at t.
+	OnCall(ctx context.Context, t time.Time) ([]string, error)
+}
+
+// Shift is a person on call from Start to End, repeated when recurring.
+type Shift struct {
+	Person string
+	Start  time.Time
+	End    time.Time
+
+	recurrence *recurrence
+}
+
+// recurrence repeats a shift every days, count times or until a time when
+// set, except at the start of the excluded occurrences.
+type recurrence struct {
+	days   int
+	count  int
+	until  time.Time
+	except []time.Time
+	// weekday is the day of a weekly recurrence, which must be the one of
+	// the start of the shift.
+	weekday string
+}
+
+// covers tells whether an occurrence of the shift covers t.
+func (s Shift) covers(t time.Time) bool {
+	r := s.recurrence
+	if r == nil {
+		return !t.Before(s.Start) && t.Before(s.End)
+	}
+	if t.Before(s.Start) {
+		return false
+	}
+
+	// The occurrences are checked from the last one started before t, the
+	// earlier ones ending before.
+	duration := s.End.Sub(s.Start)
+	i := int(t.Sub(s.Start).Hours()/24)/r.days + 1
+	if r.count > 0 {
+		i = min(i, r.count-1)
+	}
+	for ; i >= 0; i-- {
+		start := s.Start.AddDate(0, 0, i*r.days)
+		if start.After(t) || !r.until.IsZero() && start.After(r.until) {
+			continue
+		}
+		if !t.Before(start.Add(duration)) {
+			return false
+		}
+		if !r.excluded(start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *recurrence) excluded(start time.Time) bool {
+	for _, except := range r.except {
+		if except.Equal(start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ICal is a schedule published as an iCalendar feed, e.g. the export of a
+// rotation, whose events are the shifts of the persons in their summary.
+type ICal struct {
+	client  *http.Client
+	url     string
+	refresh time.Duration
+
+	mu        sync.Mutex
+	shifts    []Shift
+	fetchedAt time.Time
+}
+
+// NewICal returns the schedule of the iCalendar feed at url, fetched again
+// every refresh.
+func NewICal(client *http.Client, url string, refresh time.Duration) *ICal {
+	return &ICal{client: client, url: url, refresh: refresh}
+}
+
+func (c *ICal) OnCall(ctx context.Context, t time.Time) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fetchedAt.IsZero() || time.Since(c.fetchedAt) >= c.refresh {
+		shifts, err := c.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.shifts, c.fetchedAt = shifts, time.Now()
+	}
+
+	var persons []string
+	for _, s := range c.shifts {
+		if s.covers(t) {
+			persons = append(persons, s.Person)
+		}
+	}
+
+	return persons, nil
+}
+
+func (c *ICal) fetch(ctx context.Context) ([]Shift, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return ParseICal(resp.Body)
+}
+
+// ParseICal parses the events of an iCalendar as shifts. The daily and
+// weekly recurrences are repeated, with their INTERVAL, COUNT, UNTIL and
+// EXDATE, and the BYDAY of their start. The calendars with other recurrences are rejected, rather than
+// telling the wrong persons on call.
+func ParseICal(r io.Reader) ([]Shift, error) {
+	// The long lines are folded, continued on the lines starting with a
+	// space or a tab.
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read calendar: %w", err)
+	}
+
+	var (
+		shifts []Shift
+		shift  *Shift
+		except []time.Time
+	)
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			shift, except = &Shift{}, nil
+		case name == "END" && value == "VEVENT" && shift != nil:
+			if shift.Person != "" && !shift.Start.IsZero() {
+				if shift.End.IsZero() {
+					shift.End = shift.Start.AddDate(0, 0, 1)
+				}
+				if r := shift.recurrence; r != nil {
+					if r.weekday != "" && r.weekday != strings.ToUpper(shift.Start.Weekday().String()[:2]) {
+						return nil, fmt.Errorf("unsupported BYDAY %s of shift starting on %s", r.weekday, shift.Start.Weekday())
+					}
+					r.except = except
+				}
+				shifts = append(shifts, *shift)
+			}
+			shift = nil
+		case shift == nil:
+		case name == "RRULE":
+			recurrence, err := parseRRule(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE %q: %w", value, err)
+			}
+			shift.recurrence = recurrence
+		case name == "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, err := parseICalTime(v, params)
+				if err != nil {
+					return nil, fmt.Errorf("invalid EXDATE %q: %w", value, err)
+				}
+				except = append(except, t)
+			}
+		case name == "RDATE", name == "RECURRENCE-ID":
+			return nil, fmt.Errorf("unsupported %s", name)
+		case name == "SUMMARY":
+			shift.Person = strings.TrimSpace(unescape(value))
+		case name == "DTSTART", name == "DTEND":
+			t, err := parseICalTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+			}
+			if name == "DTSTART" {
+				shift.Start = t
+			} else {
+				shift.End = t
+			}
+		}
+	}
+
+	return shifts, nil
+}
+
+// parseRRule parses the daily and weekly recurrences, without BY rules but
+// the day of the start of a weekly one.
+func parseRRule(value string) (*recurrence, error) {
+	r := &recurrence{}
+	interval := 1
+	for _, part := range strings.Split(value, ";") {
+		key, v, _ := strings.Cut(part, "=")
+		var err error
+		switch key {
+		case "FREQ":
+			switch v {
+			case "DAILY":
+				r.days = 1
+			case "WEEKLY":
+				r.days = 7
+			default:
+				return nil, fmt.Errorf("unsupported frequency %s", v)
+			}
+		case "INTERVAL":
+			interval, err = strconv.Atoi(v)
+			if err == nil && interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+			if err == nil && r.count < 1 {
+				err = errors.New("must be positive")
+			}
+		case "UNTIL":
+			r.until, err = parseICalTime(v, "")
+		case "BYDAY":
+			// Only the day of the start, as written by most calendars.
+			if len(v) != 2 {
+				return nil, fmt.Errorf("unsupported BYDAY %s", v)
+			}
+			r.weekday = v
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("unsupported %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	if r.days == 0 {
+		return nil, errors.New("missing FREQ")
+	}
+	if r.weekday != "" && r.days != 7 {
+		return nil, errors.New("unsupported BYDAY of a daily recurrence")
+	}
+	r.days *= interval
+
+	return r, nil
+}
+
+// parseICalTime parses a date, a UTC time, or a local time in the TZID of
+// the params, UTC by default.
+func parseICalTime(value, params string) (time.Time, error) {
+	location := time.UTC
+	for _, param := range strings.Split(params, ";") {
+		if tzid, ok := strings.CutPrefix(param, "TZID="); ok {
+			loc, err := time.LoadLocation(strings.Trim(tzid, `"`))
+			if err != nil {
+				return time.Time{}, err
+			}
+			location = loc
+		}
+	}
+
+	switch {
+	case len(value) == len("20060102"):
+		return time.ParseInLocation("20060102", value, location)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	default:
+		return time.ParseInLocation("20060102T150405", value, location)
+	}
+}
+
+// unescape unescapes a text value.
+func unescape(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value)
+}
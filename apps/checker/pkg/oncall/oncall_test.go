@@ -0,0 +1,121 @@
+package oncall_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oncall"
+	"github.com/stretchr/testify/require"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20240101T090000Z\r\n" +
+	"DTEND:20240101T170000Z\r\n" +
+	"SUMMARY:alice\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;TZID=Europe/Paris:20240101T180000\r\n" +
+	"DTEND;TZID=Europe/Paris:20240102T090000\r\n" +
+	"SUMMARY:b\r\n" +
+	" ob\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20240106\r\n" +
+	"SUMMARY:carol\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+// recurring alternates alice and bob every week, alice being off the third
+// week and bob on call twice.
+const recurring = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;TZID=Europe/Paris:20240101T090000\r\n" +
+	"DTEND;TZID=Europe/Paris:20240108T090000\r\n" +
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO;UNTIL=20240501T000000Z\r\n" +
+	"EXDATE;TZID=Europe/Paris:20240115T090000\r\n" +
+	"SUMMARY:alice\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20240108T080000Z\r\n" +
+	"DTEND:20240115T080000Z\r\n" +
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=2\r\n" +
+	"SUMMARY:bob\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestICal(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write([]byte(calendar))
+	}))
+	defer server.Close()
+
+	schedule := oncall.NewICal(server.Client(), server.URL, time.Hour)
+	for _, test := range []struct {
+		at      time.Time
+		persons []string
+	}{
+		{at: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), persons: []string{"alice"}},
+		{at: time.Date(2024, 1, 1, 17, 30, 0, 0, time.UTC), persons: []string{"bob"}},
+		{at: time.Date(2024, 1, 6, 23, 0, 0, 0, time.UTC), persons: []string{"carol"}},
+		{at: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), persons: nil},
+	} {
+		persons, err := schedule.OnCall(context.Background(), test.at)
+		require.NoError(t, err)
+		require.Equal(t, test.persons, persons, test.at)
+	}
+	require.Equal(t, int32(1), fetches.Load(), "the calendar should be cached")
+}
+
+func TestParseICal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should repeat the recurring shifts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(recurring))
+		}))
+		defer server.Close()
+
+		schedule := oncall.NewICal(server.Client(), server.URL, time.Hour)
+		for _, test := range []struct {
+			at      time.Time
+			persons []string
+		}{
+			{at: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), persons: []string{"alice"}},
+			{at: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), persons: []string{"bob"}},
+			{at: time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC), persons: nil},
+			{at: time.Date(2024, 1, 24, 12, 0, 0, 0, time.UTC), persons: []string{"bob"}},
+			{at: time.Date(2024, 2, 7, 12, 0, 0, 0, time.UTC), persons: nil},
+			// At 09:00 in Paris, after the change to the summer time.
+			{at: time.Date(2024, 4, 8, 7, 30, 0, 0, time.UTC), persons: []string{"alice"}},
+			{at: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC), persons: nil},
+		} {
+			persons, err := schedule.OnCall(context.Background(), test.at)
+			require.NoError(t, err)
+			require.Equal(t, test.persons, persons, test.at)
+		}
+	})
+
+	t.Run("it should reject the unsupported recurrences", func(t *testing.T) {
+		for _, rule := range []string{"FREQ=MONTHLY", "FREQ=WEEKLY;BYDAY=MO,TU", "FREQ=WEEKLY;BYDAY=TU"} {
+			_, err := oncall.ParseICal(strings.NewReader("BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"DTSTART:20240101T090000Z\r\n" +
+				"RRULE:" + rule + "\r\n" +
+				"SUMMARY:alice\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n"))
+			require.Error(t, err, rule)
+		}
+	})
+}
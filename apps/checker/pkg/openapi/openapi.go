@@ -0,0 +1,287 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Schema is a JSON schema of the document.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Operation describes an endpoint. The request and the response are values
+// of their types, their schemas derived from the types.
+type Operation struct {
+	Summary string
+	// Request is the body of the request, none when nil.
+	Request any
+	// Response is the body of the successful response, any when nil.
+	Response any
+	// Status of the successful response, 200 when unset.
+	Status int
+	// Public operations are not authenticated.
+	Public bool
+	// Admin operations are authenticated by the admin credentials.
+	Admin bool
+	// Error is the body of the error responses, the one of the document
+	// when nil.
+	Error any
+}
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	title, version string
+	errorSchema    any
+
+	mu         sync.Mutex
+	operations map[string]map[string]Operation
+}
+
+// New returns a document of the API, its errors having the schema of the
+// value.
+func New(title, version string, errorSchema any) *Document {
+	return &Document{
+		title:       title,
+		version:     version,
+		errorSchema: errorSchema,
+		operations:  map[string]map[string]Operation{},
+	}
+}
+
+// Add adds the operation of the route, a gin path, e.g. "/monitors/:id".
+func (d *Document) Add(method, path string, op Operation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.operations[path] == nil {
+		d.operations[path] = map[string]Operation{}
+	}
+	d.operations[path][strings.ToLower(method)] = op
+}
+
+// Build returns the document, the schemas of the named types being shared
+// as components.
+func (d *Document) Build() map[string]any {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	g := &generator{components: map[string]*Schema{}}
+	errorResponse := func(schema any) map[string]any {
+		return map[string]any{
+			"description": "Error",
+			"content": map[string]any{"application/json": map[string]any{
+				"schema": g.schema(reflect.TypeOf(schema)),
+			}},
+		}
+	}
+
+	paths := map[string]any{}
+	for path, operations := range d.operations {
+		item := map[string]any{}
+		for method, op := range operations {
+			status := op.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			response := map[string]any{"description": http.StatusText(status)}
+			if op.Response != nil {
+				response["content"] = map[string]any{"application/json": map[string]any{
+					"schema": g.schema(reflect.TypeOf(op.Response)),
+				}}
+			}
+
+			errorSchema := op.Error
+			if errorSchema == nil {
+				errorSchema = d.errorSchema
+			}
+			operation := map[string]any{
+				"summary": op.Summary,
+				"responses": map[string]any{
+					strconv.Itoa(status): response,
+					"default":            errorResponse(errorSchema),
+				},
+			}
+			if params := parameters(path); len(params) > 0 {
+				operation["parameters"] = params
+			}
+			if op.Request != nil {
+				operation["requestBody"] = map[string]any{
+					"required": true,
+					"content": map[string]any{"application/json": map[string]any{
+						"schema": g.schema(reflect.TypeOf(op.Request)),
+					}},
+				}
+			}
+			switch {
+			case op.Public:
+				operation["security"] = []any{}
+			case op.Admin:
+				operation["security"] = []any{map[string]any{"admin": []string{}}}
+			}
+			item[method] = operation
+		}
+		paths[convert(path)] = item
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": d.title, "version": d.version},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": g.components,
+			"securitySchemes": map[string]any{
+				"secret":    map[string]any{"type": "apiKey", "in": "header", "name": "Authorization", "description": "Basic <secret>"},
+				"idToken":   map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"signature": map[string]any{"type": "apiKey", "in": "header", "name": "X-OpenStatus-Signature", "description": "HMAC-SHA256 of <timestamp>.<nonce>.<method>.<path>.<body>, along with X-OpenStatus-Timestamp and X-OpenStatus-Nonce"},
+				"admin":     map[string]any{"type": "apiKey", "in": "header", "name": "Authorization", "description": "Basic <ADMIN_SECRET>, or the signature of the request with it"},
+			},
+		},
+		"security": []any{
+			map[string]any{"signature": []string{}},
+			map[string]any{"idToken": []string{}},
+			map[string]any{"secret": []string{}},
+		},
+	}
+}
+
+// Handler serves the document.
+func (d *Document) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, d.Build())
+	}
+}
+
+// convert converts the parameters of a gin path, ":id", to "{id}".
+func convert(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func parameters(path string) []any {
+	var params []any
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			params = append(params, map[string]any{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+
+	return params
+}
+
+type generator struct {
+	components map[string]*Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns the schema of the type, a reference to the component of
+// the named structs.
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.components[t.Name()]; !ok {
+			// Reserved first, for the recursive types.
+			g.components[t.Name()] = &Schema{}
+			*g.components[t.Name()] = *g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// object returns the schema of the struct, with the properties of its JSON
+// fields, the embedded structs flattened.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.object(field.Type)
+			for key, property := range embedded.Properties {
+				s.Properties[key] = property
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = g.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
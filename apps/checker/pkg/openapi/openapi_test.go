@@ -0,0 +1,122 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/openapi"
+	"github.com/stretchr/testify/require"
+)
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Monitor struct {
+	Base
+	URL       string            `json:"url"`
+	Regions   []string          `json:"regions,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Parent    *Monitor          `json:"parent,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Timeout   int64             `json:"timeout,omitempty"`
+	secret    string
+	Ignored   string `json:"-"`
+}
+
+func build(t *testing.T, doc *openapi.Document) map[string]any {
+	t.Helper()
+
+	router := gin.New()
+	router.GET("/openapi.json", doc.Handler())
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	router.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func TestDocument(t *testing.T) {
+	t.Parallel()
+
+	doc := openapi.New("Checker", "1.0.0", struct {
+		Error string `json:"error"`
+	}{})
+	doc.Add(http.MethodPost, "/v1/monitors/:id", openapi.Operation{
+		Summary:  "Update a monitor",
+		Request:  Monitor{},
+		Response: Monitor{},
+	})
+	doc.Add(http.MethodDelete, "/v1/monitors/:id", openapi.Operation{Status: http.StatusNoContent})
+	doc.Add(http.MethodGet, "/v1/ping", openapi.Operation{Public: true})
+	doc.Add(http.MethodPut, "/admin/workers", openapi.Operation{Admin: true, Error: struct {
+		Message string `json:"message"`
+	}{}})
+	body := build(t, doc)
+
+	require.Equal(t, "3.0.3", body["openapi"])
+	paths := body["paths"].(map[string]any)
+
+	t.Run("it should convert the parameters of the paths", func(t *testing.T) {
+		item, ok := paths["/v1/monitors/{id}"].(map[string]any)
+		require.True(t, ok)
+		post := item["post"].(map[string]any)
+		require.Equal(t, []any{map[string]any{
+			"name":     "id",
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		}}, post["parameters"])
+
+		responses := item["delete"].(map[string]any)["responses"].(map[string]any)
+		require.Contains(t, responses, "204")
+		require.Contains(t, responses, "default")
+	})
+
+	t.Run("it should reference the schemas of the named types", func(t *testing.T) {
+		post := paths["/v1/monitors/{id}"].(map[string]any)["post"].(map[string]any)
+		schema := post["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"]
+		require.Equal(t, map[string]any{"$ref": "#/components/schemas/Monitor"}, schema)
+	})
+
+	t.Run("it should derive the schemas from the types", func(t *testing.T) {
+		schemas := body["components"].(map[string]any)["schemas"].(map[string]any)
+		monitor := schemas["Monitor"].(map[string]any)
+		properties := monitor["properties"].(map[string]any)
+
+		require.Equal(t, []any{"createdAt", "id", "url"}, monitor["required"])
+		require.Len(t, properties, 7)
+		require.Equal(t, map[string]any{"type": "string", "format": "date-time"}, properties["createdAt"])
+		require.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, properties["regions"])
+		require.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}, properties["headers"])
+		require.Equal(t, map[string]any{"$ref": "#/components/schemas/Monitor"}, properties["parent"])
+		require.Equal(t, map[string]any{"type": "integer", "format": "int64"}, properties["timeout"])
+	})
+
+	t.Run("it should not authenticate the public operations", func(t *testing.T) {
+		get := paths["/v1/ping"].(map[string]any)["get"].(map[string]any)
+		require.Equal(t, []any{}, get["security"])
+		require.NotEmpty(t, body["security"])
+	})
+
+	t.Run("it should authenticate the admin operations with the admin credentials", func(t *testing.T) {
+		put := paths["/admin/workers"].(map[string]any)["put"].(map[string]any)
+		require.Equal(t, []any{map[string]any{"admin": []any{}}}, put["security"])
+	})
+
+	t.Run("it should describe the errors of the operation", func(t *testing.T) {
+		errorSchema := func(method, path string) any {
+			responses := paths[path].(map[string]any)[method].(map[string]any)["responses"].(map[string]any)
+			return responses["default"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)["properties"]
+		}
+		require.Contains(t, errorSchema("put", "/admin/workers"), "message")
+		require.Contains(t, errorSchema("get", "/v1/ping"), "error")
+	})
+}
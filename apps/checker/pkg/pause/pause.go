@@ -0,0 +1,125 @@
+package pause
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mode is how the checks of a paused monitor are handled.
+type Mode string
+
+const (
+	// Skip does not run the checks.
+	Skip Mode = "skip"
+	// Silent runs the checks without updating the status of the monitor.
+	Silent Mode = "silent"
+)
+
+// Registry holds the monitors paused at the checker level, in memory.
+type Registry struct {
+	mu     sync.RWMutex
+	paused map[string]Mode
+}
+
+func NewRegistry() *Registry {
+	return &Registry{paused: map[string]Mode{}}
+}
+
+// Pause pauses the monitor, until it is resumed.
+func (r *Registry) Pause(monitorID string, mode Mode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.paused[monitorID] = mode
+}
+
+// Resume resumes the monitor.
+func (r *Registry) Resume(monitorID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.paused, monitorID)
+}
+
+// Get returns how the checks of the monitor are handled, if it is paused.
+func (r *Registry) Get(monitorID string) (Mode, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mode, ok := r.paused[monitorID]
+	return mode, ok
+}
+
+// Monitor is a paused monitor.
+type Monitor struct {
+	MonitorID string `json:"monitorId"`
+	Mode      Mode   `json:"mode"`
+}
+
+// List returns the paused monitors, sorted by id.
+func (r *Registry) List() []Monitor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	monitors := make([]Monitor, 0, len(r.paused))
+	for id, mode := range r.paused {
+		monitors = append(monitors, Monitor{MonitorID: id, Mode: mode})
+	}
+	sort.Slice(monitors, func(i, j int) bool {
+		return monitors[i].MonitorID < monitors[j].MonitorID
+	})
+
+	return monitors
+}
+
+// Register adds the endpoints pausing and resuming the monitors.
+func (r *Registry) Register(router gin.IRouter) {
+	router.GET("/monitors/paused", r.list)
+	router.POST("/monitors/:id/pause", r.pause)
+	router.POST("/monitors/:id/resume", r.resume)
+}
+
+// List is the body of the list of the paused monitors.
+type List struct {
+	Monitors []Monitor `json:"monitors"`
+}
+
+// Request is the body of the pause of a monitor, optional.
+type Request struct {
+	Mode Mode `json:"mode,omitempty"`
+}
+
+func (r *Registry) list(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, List{Monitors: r.List()})
+}
+
+func (r *Registry) pause(ctx *gin.Context) {
+	var req Request
+	// The body is optional, the checks are skipped by default.
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+
+	switch req.Mode {
+	case "":
+		req.Mode = Skip
+	case Skip, Silent:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode"})
+		return
+	}
+
+	r.Pause(ctx.Param("id"), req.Mode)
+	ctx.JSON(http.StatusOK, Monitor{MonitorID: ctx.Param("id"), Mode: req.Mode})
+}
+
+func (r *Registry) resume(ctx *gin.Context) {
+	r.Resume(ctx.Param("id"))
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package pause_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pause"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	registry := pause.NewRegistry()
+	router := gin.New()
+	registry.Register(router)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should skip the checks by default", func(t *testing.T) {
+		w := do(http.MethodPost, "/monitors/1/pause", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		mode, ok := registry.Get("1")
+		require.True(t, ok)
+		require.Equal(t, pause.Skip, mode)
+	})
+
+	t.Run("it should pause silently", func(t *testing.T) {
+		w := do(http.MethodPost, "/monitors/2/pause", `{"mode":"silent"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		mode, ok := registry.Get("2")
+		require.True(t, ok)
+		require.Equal(t, pause.Silent, mode)
+	})
+
+	t.Run("it should reject unknown modes", func(t *testing.T) {
+		w := do(http.MethodPost, "/monitors/3/pause", `{"mode":"later"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		_, ok := registry.Get("3")
+		require.False(t, ok)
+	})
+
+	t.Run("it should list and resume the monitors", func(t *testing.T) {
+		w := do(http.MethodGet, "/monitors/paused", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"monitors":[{"monitorId":"1","mode":"skip"},{"monitorId":"2","mode":"silent"}]}`, w.Body.String())
+
+		w = do(http.MethodPost, "/monitors/1/resume", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		_, ok := registry.Get("1")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,265 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AnyWorkspace is the workspace of the rules applying to every workspace.
+const AnyWorkspace = "*"
+
+// ErrDenied is the error of the targets the policy does not allow.
+var ErrDenied = errors.New("target denied by policy")
+
+// Rule allows or denies the targets of a workspace matching its hosts or
+// ranges, and its ports. An empty dimension matches everything.
+type Rule struct {
+	WorkspaceID string `json:"workspaceId"`
+	// Action is "allow" or "deny".
+	Action string `json:"action"`
+	// Hosts are host names, e.g. "api.example.com", or wildcards matching
+	// their subdomains, e.g. "*.example.com".
+	Hosts []string `json:"hosts,omitempty"`
+	// CIDRs are the ranges of the addresses the hosts resolve to.
+	CIDRs []string `json:"cidrs,omitempty"`
+	Ports []int    `json:"ports,omitempty"`
+}
+
+type rule struct {
+	Rule
+	prefixes []netip.Prefix
+}
+
+// matches reports whether the rule matches the target. A deny rule matches
+// when one of the addresses is in its ranges, an allow rule when all are.
+func (r rule) matches(host string, addrs []netip.Addr, port int) bool {
+	if len(r.Ports) > 0 && !containsPort(r.Ports, port) {
+		return false
+	}
+	if len(r.Hosts) == 0 && len(r.prefixes) == 0 {
+		return true
+	}
+
+	for _, pattern := range r.Hosts {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+
+	if len(r.prefixes) == 0 || len(addrs) == 0 {
+		return false
+	}
+	all := true
+	for _, addr := range addrs {
+		in := false
+		for _, prefix := range r.prefixes {
+			in = in || prefix.Contains(addr.Unmap())
+		}
+		if in && r.Action == "deny" {
+			return true
+		}
+		all = all && in
+	}
+
+	return all && r.Action == "allow"
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolver resolves the addresses of the hosts.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Engine restricts the targets the workspaces may check. The deny rules take
+// precedence, and a workspace with allow rules, its own or the ones of any
+// workspace, may only check the targets they allow.
+type Engine struct {
+	resolver Resolver
+
+	mu    sync.RWMutex
+	rules []rule
+}
+
+func New(resolver Resolver) *Engine {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return &Engine{resolver: resolver}
+}
+
+// Set replaces the rules.
+func (e *Engine) Set(rules []Rule) error {
+	parsed := make([]rule, 0, len(rules))
+	for i, r := range rules {
+		if r.Action != "allow" && r.Action != "deny" {
+			return fmt.Errorf("rule %d: invalid action %q", i, r.Action)
+		}
+		if r.WorkspaceID == "" {
+			r.WorkspaceID = AnyWorkspace
+		}
+
+		p := rule{Rule: r}
+		for _, cidr := range r.CIDRs {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return fmt.Errorf("rule %d: invalid cidr %q: %w", i, cidr, err)
+			}
+			p.prefixes = append(p.prefixes, prefix)
+		}
+		parsed = append(parsed, p)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = parsed
+	return nil
+}
+
+// workspaceRules returns the rules applying to the workspace.
+func (e *Engine) workspaceRules(workspaceID string) []rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var rules []rule
+	for _, r := range e.rules {
+		if r.WorkspaceID == AnyWorkspace || r.WorkspaceID == workspaceID {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules
+}
+
+// Check returns an error wrapping ErrDenied when the workspace may not check
+// the target, or another error when its host can not be resolved.
+func (e *Engine) Check(ctx context.Context, workspaceID string, target *url.URL) error {
+	rules := e.workspaceRules(workspaceID)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(target.Hostname())
+	port, err := strconv.Atoi(target.Port())
+	if err != nil {
+		port = 80
+		if target.Scheme == "https" {
+			port = 443
+		}
+	}
+
+	// The addresses are only resolved for the rules with ranges.
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		for _, r := range rules {
+			if len(r.prefixes) == 0 {
+				continue
+			}
+			if addrs, err = e.resolver.LookupNetIP(ctx, "ip", host); err != nil {
+				return fmt.Errorf("unable to resolve %s: %w", host, err)
+			}
+			break
+		}
+	}
+
+	return evaluate(rules, host, addrs, port)
+}
+
+// CheckAddr returns an error wrapping ErrDenied when the workspace may not
+// connect to the address the host resolved to, e.g. from the control of the
+// dialer, whatever the DNS answers when the target was checked.
+func (e *Engine) CheckAddr(workspaceID, host string, addr netip.AddrPort) error {
+	rules := e.workspaceRules(workspaceID)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return evaluate(rules, strings.ToLower(host), []netip.Addr{addr.Addr()}, int(addr.Port()))
+}
+
+// evaluate returns an error wrapping ErrDenied when the rules do not allow
+// the host, resolved to the addresses, on the port.
+func evaluate(rules []rule, host string, addrs []netip.Addr, port int) error {
+	allowRules := false
+	allowed := false
+	for _, r := range rules {
+		switch {
+		case r.Action == "deny" && r.matches(host, addrs, port):
+			return fmt.Errorf("%w: %s:%d", ErrDenied, host, port)
+		case r.Action == "allow":
+			allowRules = true
+			allowed = allowed || r.matches(host, addrs, port)
+		}
+	}
+	if allowRules && !allowed {
+		return fmt.Errorf("%w: %s:%d", ErrDenied, host, port)
+	}
+
+	return nil
+}
+
+// Load replaces the rules with the ones of a JSON file containing an array
+// of rules.
+func (e *Engine) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read policy: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("unable to decode policy: %w", err)
+	}
+
+	return e.Set(rules)
+}
+
+// Run reloads the rules from the file every refresh until the context is
+// done.
+func (e *Engine) Run(ctx context.Context, path string, refresh time.Duration) {
+	if err := e.Load(path); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load policy")
+	}
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Load(path); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload policy")
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/policy"
+	"github.com/stretchr/testify/require"
+)
+
+type resolver map[string][]netip.Addr
+
+func (r resolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestEngine(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dns := resolver{
+		"intranet.acme.com":  {netip.MustParseAddr("10.1.2.3")},
+		"www.example.com":    {netip.MustParseAddr("93.184.216.34")},
+		"www.openstatus.dev": {netip.MustParseAddr("76.76.21.21")},
+	}
+	target := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		return u
+	}
+
+	e := policy.New(dns)
+	require.NoError(t, e.Set([]policy.Rule{
+		{Action: "deny", Ports: []int{22, 25}},
+		{WorkspaceID: "acme", Action: "allow", Hosts: []string{"*.acme.com"}},
+		{WorkspaceID: "acme", Action: "allow", CIDRs: []string{"93.184.216.0/24"}},
+		{WorkspaceID: "other", Action: "deny", CIDRs: []string{"10.0.0.0/8"}},
+	}))
+
+	tests := []struct {
+		name      string
+		workspace string
+		target    string
+		allowed   bool
+	}{
+		{"it should allow the targets without rules", "free", "https://www.openstatus.dev", true},
+		{"it should deny the ports of every workspace", "free", "http://www.openstatus.dev:25", false},
+		{"it should allow the hosts of the allow rules", "acme", "https://intranet.acme.com", true},
+		{"it should allow the ranges of the allow rules", "acme", "https://www.example.com", true},
+		{"it should deny the targets outside the allow rules", "acme", "https://www.openstatus.dev", false},
+		{"it should deny the ranges of the deny rules", "other", "https://intranet.acme.com", false},
+		{"it should match the addresses of the targets", "other", "http://10.0.0.1:8080", false},
+		{"it should prefer the deny rules", "acme", "ssh://intranet.acme.com:22", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Check(ctx, tt.workspace, target(tt.target))
+			if tt.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, policy.ErrDenied)
+			}
+		})
+	}
+
+	t.Run("it should not deny the unresolved targets", func(t *testing.T) {
+		err := e.Check(ctx, "other", target("https://unknown.example.com"))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, policy.ErrDenied, "a resolve failure is not a policy decision")
+	})
+
+	t.Run("it should check the connected addresses", func(t *testing.T) {
+		require.NoError(t, e.CheckAddr("other", "www.example.com", netip.MustParseAddrPort("93.184.216.34:443")))
+		require.ErrorIs(t, e.CheckAddr("other", "www.example.com", netip.MustParseAddrPort("10.0.0.1:443")), policy.ErrDenied, "the host may resolve elsewhere once checked")
+		require.NoError(t, e.CheckAddr("acme", "intranet.acme.com", netip.MustParseAddrPort("10.1.2.3:443")))
+		require.ErrorIs(t, e.CheckAddr("free", "www.openstatus.dev", netip.MustParseAddrPort("76.76.21.21:25")), policy.ErrDenied)
+	})
+
+	t.Run("it should reject an invalid rule", func(t *testing.T) {
+		require.Error(t, policy.New(dns).Set([]policy.Rule{{Action: "maybe"}}))
+		require.Error(t, policy.New(dns).Set([]policy.Rule{{Action: "deny", CIDRs: []string{"10.0.0.1"}}}))
+	})
+
+	t.Run("it should load the rules of a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"workspaceId":"1","action":"deny","hosts":["www.example.com"]}]`), 0o600))
+
+		e := policy.New(dns)
+		require.NoError(t, e.Load(path))
+		require.ErrorIs(t, e.Check(ctx, "1", target("https://www.example.com")), policy.ErrDenied)
+		require.NoError(t, e.Check(ctx, "2", target("https://www.example.com")))
+	})
+}
@@ -0,0 +1,342 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	queued, _ = telemetry.Meter().Int64UpDownCounter("checker.pool.queued",
+		metric.WithDescription("Checks waiting for a worker."),
+	)
+	running, _ = telemetry.Meter().Int64UpDownCounter("checker.pool.running",
+		metric.WithDescription("Checks run by the workers."),
+	)
+	wait, _ = telemetry.Meter().Int64Histogram("checker.pool.wait",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Time spent by the checks waiting for a worker."),
+	)
+)
+
+// DefaultTimeout bounds the checks of a pool, e.g. the ones of a request
+// without timeout, retries included.
+const DefaultTimeout = 5 * time.Minute
+
+type task struct {
+	ctx      context.Context
+	fn       func(ctx context.Context)
+	queuedAt time.Time
+	done     chan struct{}
+}
+
+// Pool runs the checks on a bounded number of workers.
+type Pool struct {
+	name    string
+	attrs   metric.MeasurementOption
+	tasks   chan task
+	wg      sync.WaitGroup
+	depth   atomic.Int64
+	running atomic.Int64
+	recover func(ctx context.Context, v any)
+	timeout time.Duration
+
+	mu      sync.Mutex
+	workers int
+	// stopping is the number of workers to stop, once their running check
+	// is done. A worker receiving from quit stops while some remain.
+	stopping int
+	quit     chan struct{}
+	closed   chan struct{}
+}
+
+type Option func(*Pool)
+
+// WithRecover recovers the panics of the checks, passing them to fn, so a
+// single check cannot crash the checker. The worker then runs the next ones.
+func WithRecover(fn func(ctx context.Context, v any)) Option {
+	return func(p *Pool) {
+		p.recover = fn
+	}
+}
+
+// WithTimeout bounds each check run by the workers (default
+// DefaultTimeout), so a check hanging without a timeout of its own cannot
+// hold a worker, nor the shutdown, forever.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Pool) {
+		p.timeout = timeout
+	}
+}
+
+// New starts a pool of workers, named after its lane in the metrics.
+func New(name string, workers int, opts ...Option) *Pool {
+	p := &Pool{
+		name:    name,
+		attrs:   metric.WithAttributes(attribute.String("lane", name)),
+		tasks:   make(chan task),
+		quit:    make(chan struct{}),
+		closed:  make(chan struct{}),
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.Resize(workers)
+	return p
+}
+
+// Resize starts or stops workers until the pool has the given number of
+// them. The stopped workers finish their running check first, and growing
+// the pool again keeps the workers still to stop rather than starting new
+// ones.
+func (p *Pool) Resize(workers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.closed:
+		return
+	default:
+	}
+
+	for ; p.workers < workers; p.workers++ {
+		if p.stopping > 0 {
+			p.stopping--
+			continue
+		}
+		p.wg.Add(1)
+		go p.work()
+	}
+	for ; p.workers > workers; p.workers-- {
+		p.stopping++
+		go func() {
+			select {
+			case p.quit <- struct{}{}:
+			case <-p.closed:
+			}
+		}()
+	}
+}
+
+// Workers returns the number of workers of the pool.
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.workers
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		var t task
+		select {
+		case <-p.quit:
+			if p.stop() {
+				return
+			}
+			continue
+		case next, ok := <-p.tasks:
+			if !ok {
+				return
+			}
+			t = next
+		}
+
+		p.depth.Add(-1)
+		queued.Add(t.ctx, -1, p.attrs)
+		wait.Record(t.ctx, time.Since(t.queuedAt).Milliseconds(), p.attrs)
+
+		p.run(t)
+	}
+}
+
+// stop tells whether the worker should stop, the pool having been shrunk and
+// not grown back since.
+func (p *Pool) stop() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopping == 0 {
+		return false
+	}
+	p.stopping--
+	return true
+}
+
+func (p *Pool) run(t task) {
+	defer close(t.done)
+
+	// A started check runs to completion, even when its caller gives up or
+	// the checker shuts down, within the timeout of the pool.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(t.ctx), p.timeout)
+	defer cancel()
+	p.running.Add(1)
+	running.Add(t.ctx, 1, p.attrs)
+	defer func() {
+		running.Add(t.ctx, -1, p.attrs)
+		p.running.Add(-1)
+	}()
+
+	if p.recover != nil {
+		defer func() {
+			if v := recover(); v != nil {
+				p.recover(ctx, v)
+			}
+		}()
+	}
+
+	t.fn(ctx)
+}
+
+// Do runs fn on a worker and waits for it to return. It returns the error of
+// the context when it is done before a worker is available.
+func (p *Pool) Do(ctx context.Context, fn func(ctx context.Context)) error {
+	t := task{ctx: ctx, fn: fn, queuedAt: time.Now(), done: make(chan struct{})}
+
+	p.depth.Add(1)
+	queued.Add(ctx, 1, p.attrs)
+	select {
+	case <-ctx.Done():
+		p.depth.Add(-1)
+		queued.Add(ctx, -1, p.attrs)
+		return ctx.Err()
+	case p.tasks <- t:
+	}
+
+	<-t.done
+	return nil
+}
+
+// Queued returns the number of checks waiting for a worker.
+func (p *Pool) Queued() int {
+	return int(p.depth.Load())
+}
+
+// Drain waits for the queued and running checks to be done, or for the
+// context to be done.
+func (p *Pool) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for p.depth.Load() > 0 || p.running.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("unable to drain the %s pool: %w", p.name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// Close stops the workers, once the running checks are done. No check can be
+// submitted after.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	close(p.closed)
+	close(p.tasks)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// Priority is the lane of a check.
+type Priority string
+
+const (
+	// High is the lane of the checks triggered by the users, e.g. "check now".
+	High Priority = "high"
+	// Normal is the lane of the scheduled checks.
+	Normal Priority = "normal"
+)
+
+// ErrPaused is returned for the checks submitted while the intake is
+// paused.
+var ErrPaused = errors.New("intake paused")
+
+// Lanes runs the checks on a pool per priority, so the high priority ones
+// do not wait behind the scheduled backlog.
+type Lanes struct {
+	high   *Pool
+	normal *Pool
+	paused atomic.Bool
+}
+
+func NewLanes(high, normal int, opts ...Option) *Lanes {
+	return &Lanes{
+		high:   New(string(High), high, opts...),
+		normal: New(string(Normal), normal, opts...),
+	}
+}
+
+// lane returns the pool of the priority, the normal one by default.
+func (l *Lanes) lane(priority Priority) *Pool {
+	if priority == High {
+		return l.high
+	}
+
+	return l.normal
+}
+
+// Do runs fn on the pool of the priority. It returns ErrPaused, without
+// running fn, while the intake is paused.
+func (l *Lanes) Do(ctx context.Context, priority Priority, fn func(ctx context.Context)) error {
+	if l.paused.Load() {
+		return ErrPaused
+	}
+
+	return l.lane(priority).Do(ctx, fn)
+}
+
+// Queued returns the number of checks waiting for a worker of the priority.
+func (l *Lanes) Queued(priority Priority) int {
+	return l.lane(priority).Queued()
+}
+
+// Resize sets the number of workers of the priority.
+func (l *Lanes) Resize(priority Priority, workers int) {
+	l.lane(priority).Resize(workers)
+}
+
+// Workers returns the number of workers of the priority.
+func (l *Lanes) Workers(priority Priority) int {
+	return l.lane(priority).Workers()
+}
+
+// Pause stops the intake of the checks, the queued and running ones still
+// being run, until resumed.
+func (l *Lanes) Pause() {
+	l.paused.Store(true)
+}
+
+func (l *Lanes) Resume() {
+	l.paused.Store(false)
+}
+
+// Paused tells whether the intake is paused.
+func (l *Lanes) Paused() bool {
+	return l.paused.Load()
+}
+
+// Drain waits for the checks of every lane to be done, or for the context to
+// be done.
+func (l *Lanes) Drain(ctx context.Context) error {
+	return errors.Join(l.high.Drain(ctx), l.normal.Drain(ctx))
+}
+
+// Close stops the pools.
+func (l *Lanes) Close() {
+	l.high.Close()
+	l.normal.Close()
+}
@@ -0,0 +1,263 @@
+package pool_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should bound the concurrency", func(t *testing.T) {
+		p := pool.New("test", 2)
+		defer p.Close()
+
+		var current, peak atomic.Int32
+		done := make(chan struct{})
+		for i := 0; i < 6; i++ {
+			go func() {
+				_ = p.Do(context.Background(), func(ctx context.Context) {
+					n := current.Add(1)
+					for {
+						old := peak.Load()
+						if n <= old || peak.CompareAndSwap(old, n) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					current.Add(-1)
+				})
+				done <- struct{}{}
+			}()
+		}
+		for i := 0; i < 6; i++ {
+			<-done
+		}
+
+		require.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("it should give up when the context is done", func(t *testing.T) {
+		p := pool.New("test", 1)
+		defer p.Close()
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_ = p.Do(context.Background(), func(ctx context.Context) {
+				close(started)
+				<-release
+			})
+		}()
+		<-started
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		go func() {
+			_ = p.Do(ctx, func(ctx context.Context) {})
+		}()
+		require.Eventually(t, func() bool { return p.Queued() == 1 }, time.Second, time.Millisecond)
+
+		require.ErrorIs(t, p.Do(ctx, func(ctx context.Context) {}), context.DeadlineExceeded)
+		require.Eventually(t, func() bool { return p.Queued() == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("it should recover the panics of the checks", func(t *testing.T) {
+		var recovered any
+		p := pool.New("test", 1, pool.WithRecover(func(ctx context.Context, v any) {
+			recovered = v
+		}))
+		defer p.Close()
+
+		require.NoError(t, p.Do(context.Background(), func(ctx context.Context) {
+			panic("malformed monitor")
+		}))
+		require.Equal(t, "malformed monitor", recovered)
+
+		var ran bool
+		require.NoError(t, p.Do(context.Background(), func(ctx context.Context) { ran = true }))
+		require.True(t, ran, "the worker should run the next checks")
+	})
+}
+
+func TestLanes(t *testing.T) {
+	t.Parallel()
+
+	lanes := pool.NewLanes(1, 1)
+	defer lanes.Close()
+
+	// The normal lane is busy.
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = lanes.Do(context.Background(), pool.Normal, func(ctx context.Context) {
+			close(started)
+			<-release
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var ran bool
+	require.NoError(t, lanes.Do(ctx, pool.High, func(ctx context.Context) { ran = true }))
+	require.True(t, ran, "the high priority checks should not wait for the normal ones")
+}
+
+func TestResize(t *testing.T) {
+	t.Parallel()
+
+	p := pool.New("test", 1)
+	defer p.Close()
+
+	t.Run("it should start the new workers", func(t *testing.T) {
+		p.Resize(2)
+		require.Equal(t, 2, p.Workers())
+
+		release := make(chan struct{})
+		var started atomic.Int32
+		for i := 0; i < 2; i++ {
+			go func() {
+				_ = p.Do(context.Background(), func(ctx context.Context) {
+					started.Add(1)
+					<-release
+				})
+			}()
+		}
+		require.Eventually(t, func() bool { return started.Load() == 2 }, time.Second, time.Millisecond)
+		close(release)
+	})
+
+	t.Run("it should stop the extra workers", func(t *testing.T) {
+		p.Resize(1)
+		require.Equal(t, 1, p.Workers())
+
+		var ran bool
+		require.NoError(t, p.Do(context.Background(), func(ctx context.Context) { ran = true }))
+		require.True(t, ran)
+	})
+
+	t.Run("it should keep the workers to stop when grown back", func(t *testing.T) {
+		p := pool.New("test", 2)
+		defer p.Close()
+
+		release := make(chan struct{})
+		var started atomic.Int32
+		for i := 0; i < 2; i++ {
+			go func() {
+				_ = p.Do(context.Background(), func(ctx context.Context) {
+					started.Add(1)
+					<-release
+				})
+			}()
+		}
+		require.Eventually(t, func() bool { return started.Load() == 2 }, time.Second, time.Millisecond)
+
+		p.Resize(0)
+		p.Resize(2)
+		require.Equal(t, 2, p.Workers())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, p.Do(ctx, func(ctx context.Context) {}), context.DeadlineExceeded, "no worker should be started while the previous ones run")
+		close(release)
+
+		var ran atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, p.Do(context.Background(), func(ctx context.Context) { ran.Add(1) }))
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(2), ran.Load())
+	})
+}
+
+func TestPause(t *testing.T) {
+	t.Parallel()
+
+	lanes := pool.NewLanes(1, 1)
+	defer lanes.Close()
+
+	t.Run("it should reject the checks while paused", func(t *testing.T) {
+		lanes.Pause()
+		require.True(t, lanes.Paused())
+		require.ErrorIs(t, lanes.Do(context.Background(), pool.High, func(ctx context.Context) {}), pool.ErrPaused)
+	})
+
+	t.Run("it should run the checks once resumed", func(t *testing.T) {
+		lanes.Resume()
+		require.NoError(t, lanes.Do(context.Background(), pool.High, func(ctx context.Context) {}))
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	p := pool.New("test", 1, pool.WithTimeout(20*time.Millisecond))
+	defer p.Close()
+
+	t.Run("it should bound the checks without timeout", func(t *testing.T) {
+		var err error
+		require.NoError(t, p.Do(context.Background(), func(checkCtx context.Context) {
+			<-checkCtx.Done()
+			err = checkCtx.Err()
+		}))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestDrain(t *testing.T) {
+	t.Parallel()
+
+	p := pool.New("test", 1)
+	defer p.Close()
+
+	t.Run("it should wait for the running checks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		var done atomic.Bool
+		go func() {
+			_ = p.Do(ctx, func(ctx context.Context) {
+				close(started)
+				time.Sleep(30 * time.Millisecond)
+				done.Store(ctx.Err() == nil)
+			})
+		}()
+		<-started
+
+		// The check runs to completion, even when its caller gives up.
+		cancel()
+		require.NoError(t, p.Drain(context.Background()))
+		require.True(t, done.Load())
+	})
+
+	t.Run("it should give up when the context is done", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_ = p.Do(context.Background(), func(ctx context.Context) {
+				close(started)
+				<-release
+			})
+		}()
+		<-started
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, p.Drain(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+// fetchRetry is the delay before fetching again after a failed fetch.
+const fetchRetry = time.Second
+
+// fetcher fetches the messages of a pull subscription.
+type fetcher interface {
+	Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error)
+}
+
+type natsConsumer struct {
+	url     string
+	subject string
+	durable string
+	batch   int
+}
+
+// NewNATSConsumer returns a consumer pulling the requests published on the
+// subject of a NATS JetStream stream, through a durable consumer shared by
+// the checkers of the same region. At most batch requests run concurrently.
+func NewNATSConsumer(url, subject, durable string, batch int) Consumer {
+	return natsConsumer{
+		url:     url,
+		subject: subject,
+		durable: durable,
+		batch:   batch,
+	}
+}
+
+func (c natsConsumer) Consume(ctx context.Context, handler Handler) error {
+	nc, err := nats.Connect(c.url)
+	if err != nil {
+		return fmt.Errorf("unable to connect to nats: %w", err)
+	}
+	defer nc.Drain()
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return fmt.Errorf("unable to create jetstream context: %w", err)
+	}
+
+	sub, err := js.PullSubscribe(c.subject, c.durable, nats.ManualAck())
+	if err != nil {
+		return fmt.Errorf("unable to subscribe: %w", err)
+	}
+
+	c.consume(ctx, sub, handler)
+	return nil
+}
+
+// consume handles the messages fetched until the context is done.
+func (c natsConsumer) consume(ctx context.Context, sub fetcher, handler Handler) {
+	for ctx.Err() == nil {
+		fetchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		msgs, err := sub.Fetch(c.batch, nats.Context(fetchCtx))
+		cancel()
+		if err != nil {
+			if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+				continue
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("failed to fetch messages")
+			select {
+			case <-ctx.Done():
+			case <-time.After(fetchRetry):
+			}
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, msg := range msgs {
+			wg.Add(1)
+			go func(msg *nats.Msg) {
+				defer wg.Done()
+				c.handle(ctx, msg, handler)
+			}(msg)
+		}
+		wg.Wait()
+	}
+}
+
+func (c natsConsumer) handle(ctx context.Context, msg *nats.Msg, handler Handler) {
+	var req request.CheckerRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		// A malformed request will never succeed, drop it.
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		if err := msg.Term(); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to terminate message")
+		}
+		return
+	}
+
+	if err := handler(ctx, req); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("monitor", req.MonitorID).Msg("failed to handle checker request")
+		if err := msg.Nak(); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to nak message")
+		}
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to ack message")
+	}
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFetcher returns its messages once, then fails.
+type fakeFetcher struct {
+	msgs []*nats.Msg
+}
+
+func (f *fakeFetcher) Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error) {
+	if len(f.msgs) == 0 {
+		return nil, errors.New("connection closed")
+	}
+	msgs := f.msgs[:min(batch, len(f.msgs))]
+	f.msgs = f.msgs[len(msgs):]
+	return msgs, nil
+}
+
+func TestConsume(t *testing.T) {
+	t.Parallel()
+
+	consumer := natsConsumer{batch: 10}
+
+	t.Run("it should handle the fetched requests", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sub := &fakeFetcher{msgs: []*nats.Msg{{Data: []byte(`{"monitorId":"1"}`)}, {Data: []byte(`{`)}}}
+		handled := make(chan string, 2)
+		go consumer.consume(ctx, sub, func(ctx context.Context, req request.CheckerRequest) error {
+			handled <- req.MonitorID
+			return nil
+		})
+
+		require.Equal(t, "1", <-handled)
+		require.Never(t, func() bool { return len(handled) > 0 }, 100*time.Millisecond, 10*time.Millisecond, "the malformed requests should be dropped")
+	})
+
+	t.Run("it should stop retrying the failed fetches once done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			consumer.consume(ctx, &fakeFetcher{}, func(ctx context.Context, req request.CheckerRequest) error { return nil })
+			close(done)
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(fetchRetry / 2):
+			t.Fatal("the consumer should stop without waiting for the retry")
+		}
+	})
+}
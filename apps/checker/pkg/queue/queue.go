@@ -0,0 +1,18 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// Handler processes a check request pulled from a queue. Returning an error
+// makes the request available again for a later delivery.
+type Handler func(ctx context.Context, req request.CheckerRequest) error
+
+// Consumer pulls the check requests from a queue instead of receiving them
+// over HTTP.
+type Consumer interface {
+	// Consume calls the handler for every request until the context is done.
+	Consume(ctx context.Context, handler Handler) error
+}
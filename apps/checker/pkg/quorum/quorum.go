@@ -0,0 +1,67 @@
+package quorum
+
+import (
+	"context"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fanout"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+type confirmer struct {
+	dispatcher    fanout.Dispatcher
+	regions       []string
+	quorum        int
+	authorization string
+}
+
+// NewConfirmer returns a confirmer asking the other regions to check the
+// monitor, confirming the failure when at least quorum regions, the local
+// one included, see it failing, out of the regions which voted.
+func NewConfirmer(dispatcher fanout.Dispatcher, regions []string, quorum int, authorization string) checker.Confirmer {
+	return confirmer{
+		dispatcher:    dispatcher,
+		regions:       regions,
+		quorum:        quorum,
+		authorization: authorization,
+	}
+}
+
+func (c confirmer) Confirm(ctx context.Context, req request.CheckerRequest) bool {
+	req.Confirmation = true
+
+	// The local region already failed.
+	failures, voters := 1, 1
+	for _, result := range c.dispatcher.Dispatch(ctx, req, c.regions, c.authorization) {
+		failing, ok := vote(result)
+		if !ok {
+			log.Ctx(ctx).Warn().Str("region", result.Region).Str("error", result.Error).Msg("failed to confirm with region")
+			continue
+		}
+		voters++
+		if failing {
+			failures++
+		}
+	}
+
+	return confirmed(failures, voters, c.quorum)
+}
+
+// vote returns whether the region sees the monitor failing, and false when
+// the region could not be reached: it does not vote.
+func vote(result fanout.RegionResult) (bool, bool) {
+	if result.Result == nil {
+		return false, false
+	}
+
+	return result.Result.StatusCode < 200 || result.Result.StatusCode >= 300, true
+}
+
+// confirmed tells whether the failures confirm the failure of the monitor,
+// out of the regions which voted. The quorum is lowered to the voters, so a
+// failure no other region could check is confirmed: better a false positive
+// than a missed outage.
+func confirmed(failures, voters, quorum int) bool {
+	return failures >= min(quorum, voters)
+}
@@ -0,0 +1,76 @@
+package quorum_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fanout"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/quorum"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+type staticDispatcher map[string]int
+
+func (d staticDispatcher) Dispatch(ctx context.Context, req request.CheckerRequest, regions []string, authorization string) []fanout.RegionResult {
+	results := []fanout.RegionResult{}
+	for _, region := range regions {
+		statusCode, ok := d[region]
+		if !ok {
+			results = append(results, fanout.RegionResult{Region: region, Error: "unreachable"})
+			continue
+		}
+		results = append(results, fanout.RegionResult{Region: region, Result: &checker.PingData{StatusCode: statusCode}})
+	}
+	return results
+}
+
+func TestConfirm(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	req := request.CheckerRequest{MonitorID: "1"}
+
+	tests := []struct {
+		name       string
+		dispatcher staticDispatcher
+		want       bool
+	}{
+		{name: "confirmed by another region", dispatcher: staticDispatcher{"iad": 500, "gru": 200}, want: true},
+		{name: "not confirmed", dispatcher: staticDispatcher{"iad": 200, "gru": 200}, want: false},
+		{name: "unreachable regions do not vote", dispatcher: staticDispatcher{"iad": 200}, want: false},
+		{name: "confirmed by the regions which voted", dispatcher: staticDispatcher{"iad": 500}, want: true},
+		{name: "confirmed when no region answers", dispatcher: staticDispatcher{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := quorum.NewConfirmer(tt.dispatcher, []string{"iad", "gru"}, 2, "Basic secret")
+			require.Equal(t, tt.want, c.Confirm(ctx, req))
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	req := request.CheckerRequest{MonitorID: "1"}
+
+	tests := []struct {
+		name       string
+		dispatcher staticDispatcher
+		want       bool
+	}{
+		{name: "confirmed by the second region", dispatcher: staticDispatcher{"iad": 500}, want: true},
+		{name: "not confirmed by the second region", dispatcher: staticDispatcher{"iad": 200}, want: false},
+		{name: "falls back to the next region", dispatcher: staticDispatcher{"gru": 200}, want: false},
+		{name: "confirmed when no region answers", dispatcher: staticDispatcher{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := quorum.NewVerifier(tt.dispatcher, []string{"iad", "gru"}, "Basic secret")
+			require.Equal(t, tt.want, v.Confirm(ctx, req))
+		})
+	}
+}
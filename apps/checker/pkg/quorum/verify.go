@@ -0,0 +1,56 @@
+package quorum
+
+import (
+	"context"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fanout"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+type verifier struct {
+	dispatcher    fanout.Dispatcher
+	regions       []string
+	authorization string
+}
+
+// NewVerifier returns a confirmer checking the monitor again from a second
+// region, the first of the regions to answer. The failure is confirmed when
+// that region sees it failing too, or when no region could verify it, the
+// unreachable regions not voting as for the quorum.
+func NewVerifier(dispatcher fanout.Dispatcher, regions []string, authorization string) checker.Confirmer {
+	return verifier{
+		dispatcher:    dispatcher,
+		regions:       regions,
+		authorization: authorization,
+	}
+}
+
+func (v verifier) Confirm(ctx context.Context, req request.CheckerRequest) bool {
+	req.Confirmation = true
+
+	// The local region already failed, the second one confirms it, as a
+	// quorum of both.
+	failures, voters := 1, 1
+	for _, region := range v.regions {
+		results := v.dispatcher.Dispatch(ctx, req, []string{region}, v.authorization)
+		if len(results) != 1 {
+			log.Ctx(ctx).Warn().Str("region", region).Msg("failed to verify with region, trying the next one")
+			continue
+		}
+		failing, ok := vote(results[0])
+		if !ok {
+			log.Ctx(ctx).Warn().Str("region", region).Str("error", results[0].Error).Msg("failed to verify with region, trying the next one")
+			continue
+		}
+
+		voters++
+		if failing {
+			failures++
+		}
+		break
+	}
+
+	return confirmed(failures, voters, 2)
+}
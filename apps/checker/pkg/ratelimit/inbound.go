@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Inbound limits the requests per minute of the workspaces to a checker,
+// with a token bucket per workspace: a workspace may burst up to its limit,
+// then is refilled continuously over the minute.
+type Inbound struct {
+	limits map[string]int64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewInbound returns a limiter allowing limits[workspaceID] requests per
+// minute, or limits[DefaultWorkspace] for the other workspaces, unlimited
+// without.
+func NewInbound(limits map[string]int64) *Inbound {
+	return &Inbound{limits: limits, buckets: map[string]*bucket{}}
+}
+
+// Allow reports whether a request of the workspace is within its limit at
+// now, or else how long until the next one is.
+func (l *Inbound) Allow(workspaceID string, now time.Time) (bool, time.Duration) {
+	limit, ok := l.limits[workspaceID]
+	if !ok {
+		limit, ok = l.limits[DefaultWorkspace]
+	}
+	if !ok {
+		return true, 0
+	}
+	if limit <= 0 {
+		return false, time.Minute
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rate := float64(limit) / time.Minute.Seconds()
+	b, ok := l.buckets[workspaceID]
+	if !ok {
+		b = &bucket{tokens: float64(limit), updated: now}
+		l.buckets[workspaceID] = b
+	}
+	b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+
+	return true, 0
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// maxAttempts bounds the retries of the increments racing with other
+// checkers.
+const maxAttempts = 10
+
+type natsCounter struct {
+	kv nats.KeyValue
+}
+
+// NewNATSCounter returns a counter shared by the checkers through a NATS
+// JetStream key-value bucket on the connection, created when missing. The
+// counts expire after two windows.
+func NewNATSCounter(nc *nats.Conn, bucket string) (Counter, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create jetstream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, TTL: 2 * time.Minute})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bucket %s: %w", bucket, err)
+	}
+
+	return natsCounter{kv: kv}, nil
+}
+
+// Incr increments the count with a compare-and-swap, retried when another
+// checker incremented it in between.
+func (c natsCounter) Incr(ctx context.Context, workspaceID string, window int64) (int64, error) {
+	key := fmt.Sprintf("%s.%d", workspaceID, window)
+
+	for attempt := 0; attempt < maxAttempts && ctx.Err() == nil; attempt++ {
+		entry, err := c.kv.Get(key)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			if _, err := c.kv.Create(key, []byte("1")); err == nil {
+				return 1, nil
+			}
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to get count: %w", err)
+		}
+
+		count, err := strconv.ParseInt(string(entry.Value()), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse count: %w", err)
+		}
+		count++
+		if _, err := c.kv.Update(key, []byte(strconv.FormatInt(count, 10)), entry.Revision()); err == nil {
+			return count, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unable to increment %s: too many conflicts", key)
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultWorkspace is the key of the limit applied to the workspaces without
+// their own.
+const DefaultWorkspace = "*"
+
+// Counter counts the checks of a window. It is shared by the checkers of
+// every region for the limits to be global.
+type Counter interface {
+	// Incr increments the count of the workspace in the window, a minute
+	// since the epoch, and returns it.
+	Incr(ctx context.Context, workspaceID string, window int64) (int64, error)
+}
+
+// Limiter limits the number of checks per minute of the workspaces.
+type Limiter struct {
+	counter Counter
+	limits  map[string]int64
+}
+
+// New returns a limiter allowing limits[workspaceID] checks per minute, or
+// limits[DefaultWorkspace] for the other workspaces, unlimited without.
+func New(counter Counter, limits map[string]int64) *Limiter {
+	return &Limiter{
+		counter: counter,
+		limits:  limits,
+	}
+}
+
+// Allow reports whether the check of the request is within the limit of its
+// workspace. The checks are allowed when the counter is unavailable.
+func (l *Limiter) Allow(ctx context.Context, req request.CheckerRequest) bool {
+	limit, ok := l.limits[req.WorkspaceID]
+	if !ok {
+		limit, ok = l.limits[DefaultWorkspace]
+	}
+	if !ok {
+		return true
+	}
+
+	count, err := l.counter.Incr(ctx, req.WorkspaceID, time.Now().Unix()/60)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("workspace", req.WorkspaceID).Msg("failed to count check")
+		return true
+	}
+
+	return count <= limit
+}
+
+type memoryCounter struct {
+	mu     sync.Mutex
+	window int64
+	counts map[string]int64
+}
+
+// NewMemoryCounter returns a counter local to the checker, for a single
+// instance. It only keeps the counts of the current window.
+func NewMemoryCounter() Counter {
+	return &memoryCounter{counts: map[string]int64{}}
+}
+
+func (c *memoryCounter) Incr(ctx context.Context, workspaceID string, window int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if window != c.window {
+		c.window = window
+		clear(c.counts)
+	}
+
+	c.counts[workspaceID]++
+	return c.counts[workspaceID], nil
+}
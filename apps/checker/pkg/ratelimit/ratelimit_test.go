@@ -0,0 +1,114 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ratelimit"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+type failingCounter struct{}
+
+func (failingCounter) Incr(ctx context.Context, workspaceID string, window int64) (int64, error) {
+	return 0, errors.New("unavailable")
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("it should limit the checks of the workspace", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryCounter(), map[string]int64{"1": 2, ratelimit.DefaultWorkspace: 1})
+
+		require.True(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "1"}))
+		require.True(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "1"}))
+		require.False(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "1"}))
+
+		require.True(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "2"}), "the workspaces should be limited separately")
+		require.False(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "2"}))
+	})
+
+	t.Run("it should not limit the workspaces without limit", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryCounter(), map[string]int64{"1": 1})
+
+		for i := 0; i < 5; i++ {
+			require.True(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "2"}))
+		}
+	})
+
+	t.Run("it should allow the checks when the counter is unavailable", func(t *testing.T) {
+		limiter := ratelimit.New(failingCounter{}, map[string]int64{ratelimit.DefaultWorkspace: 1})
+
+		require.True(t, limiter.Allow(ctx, request.CheckerRequest{WorkspaceID: "1"}))
+	})
+}
+
+func TestMemoryCounter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	counter := ratelimit.NewMemoryCounter()
+
+	count, err := counter.Incr(ctx, "1", 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	count, err = counter.Incr(ctx, "1", 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	count, err = counter.Incr(ctx, "1", 11)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count, "the counts should reset with the window")
+}
+
+func TestInbound(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	t.Run("it should limit the requests of the workspace", func(t *testing.T) {
+		limiter := ratelimit.NewInbound(map[string]int64{"1": 2, ratelimit.DefaultWorkspace: 1})
+
+		ok, _ := limiter.Allow("1", now)
+		require.True(t, ok)
+		ok, _ = limiter.Allow("1", now)
+		require.True(t, ok)
+		ok, retry := limiter.Allow("1", now)
+		require.False(t, ok)
+		require.Equal(t, 30*time.Second, retry)
+
+		ok, _ = limiter.Allow("2", now)
+		require.True(t, ok, "the workspaces should be limited separately")
+		ok, _ = limiter.Allow("2", now)
+		require.False(t, ok)
+	})
+
+	t.Run("it should refill the bucket over the minute", func(t *testing.T) {
+		limiter := ratelimit.NewInbound(map[string]int64{"1": 60})
+
+		for i := 0; i < 60; i++ {
+			ok, _ := limiter.Allow("1", now)
+			require.True(t, ok)
+		}
+		ok, _ := limiter.Allow("1", now)
+		require.False(t, ok)
+
+		ok, _ = limiter.Allow("1", now.Add(time.Second))
+		require.True(t, ok)
+	})
+
+	t.Run("it should not limit the workspaces without limit", func(t *testing.T) {
+		limiter := ratelimit.NewInbound(map[string]int64{"1": 1})
+
+		for i := 0; i < 5; i++ {
+			ok, _ := limiter.Allow("2", now)
+			require.True(t, ok)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+)
+
+// Panic is a panic recovered by the checker, with the request it happened
+// in, if any.
+type Panic struct {
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	RequestID string    `json:"requestId,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Route     string    `json:"route,omitempty"`
+}
+
+// Reporter reports the recovered panics, e.g. to Sentry.
+type Reporter interface {
+	Report(ctx context.Context, p Panic) error
+}
+
+type jsonReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSON returns a reporter writing the panics as JSON lines, e.g. to
+// stderr.
+func NewJSON(w io.Writer) Reporter {
+	return &jsonReporter{w: w}
+}
+
+func (r *jsonReporter) Report(ctx context.Context, p Panic) error {
+	line, err := json.Marshal(struct {
+		Level string `json:"level"`
+		Panic
+	}{Level: "panic", Panic: p})
+	if err != nil {
+		return fmt.Errorf("unable to encode panic: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("unable to write panic: %w", err)
+	}
+
+	return nil
+}
+
+// newPanic returns the panic of the value, recovered in the calling
+// goroutine.
+func newPanic(ctx context.Context, v any) Panic {
+	return Panic{
+		Time:      time.Now().UTC(),
+		Message:   fmt.Sprint(v),
+		Stack:     string(debug.Stack()),
+		RequestID: telemetry.RequestID(ctx),
+	}
+}
+
+// reportTimeout bounds the report of a panic, not to hold the worker or the
+// response of the request on a slow reporter.
+const reportTimeout = 5 * time.Second
+
+func report(ctx context.Context, reporter Reporter, p Panic) {
+	log.Ctx(ctx).Error().Str("panic", p.Message).Str("request_id", p.RequestID).Msg("recovered from panic")
+
+	// The panic is reported even when the caller is gone.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reportTimeout)
+	defer cancel()
+	if err := reporter.Report(ctx, p); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to report panic")
+	}
+}
+
+// Recover returns the function reporting the panics recovered by the
+// workers running the checks.
+func Recover(reporter Reporter) func(ctx context.Context, v any) {
+	return func(ctx context.Context, v any) {
+		report(ctx, reporter, newPanic(ctx, v))
+	}
+}
+
+// Middleware converts the panics of the handlers into internal errors,
+// answered with the ID of the request, and reports them.
+func Middleware(reporter Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			// The handlers abort the responses on purpose with this one.
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+
+			ctx := c.Request.Context()
+			p := newPanic(ctx, v)
+			p.Method, p.Route = c.Request.Method, c.FullPath()
+			report(ctx, reporter, p)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal error", "requestId": p.RequestID})
+		}()
+
+		c.Next()
+	}
+}
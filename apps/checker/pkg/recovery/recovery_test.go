@@ -0,0 +1,128 @@
+package recovery_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/recovery"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	panics  []recovery.Panic
+	bounded bool
+}
+
+func (r *recorder) Report(ctx context.Context, p recovery.Panic) error {
+	r.panics = append(r.panics, p)
+	_, r.bounded = ctx.Deadline()
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	reporter := &recorder{}
+	router := gin.New()
+	router.Use(telemetry.Middleware(), recovery.Middleware(reporter))
+	router.GET("/written", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+		panic("malformed monitor")
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("malformed monitor")
+	})
+
+	t.Run("it should answer an internal error with the request id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/panic", nil)
+		r.Header.Set("X-Request-ID", "req-1")
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.JSONEq(t, `{"error":"internal error","requestId":"req-1"}`, w.Body.String())
+		require.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("it should report the panic", func(t *testing.T) {
+		require.NotEmpty(t, reporter.panics)
+		p := reporter.panics[0]
+		require.Equal(t, "malformed monitor", p.Message)
+		require.Equal(t, "req-1", p.RequestID)
+		require.Equal(t, "GET", p.Method)
+		require.Equal(t, "/panic", p.Route)
+		require.Contains(t, p.Stack, "recovery_test")
+		require.True(t, reporter.bounded, "the report should have a deadline")
+	})
+
+	t.Run("it should keep the response already written", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/written", nil)
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, reporter.panics, 2)
+	})
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	reporter := recovery.NewJSON(&buf)
+	require.NoError(t, reporter.Report(context.Background(), recovery.Panic{Message: "boom", RequestID: "req-1"}))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "panic", line["level"])
+	require.Equal(t, "boom", line["message"])
+	require.Equal(t, "req-1", line["requestId"])
+}
+
+func TestSentry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path, auth string
+		items      []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var item map[string]any
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &item))
+			items = append(items, item)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("it should reject an invalid dsn", func(t *testing.T) {
+		_, err := recovery.NewSentry(server.Client(), server.URL+"/42", "ams")
+		require.Error(t, err)
+	})
+
+	t.Run("it should send the panic in an envelope", func(t *testing.T) {
+		dsn := "http://key@" + server.Listener.Addr().String() + "/42"
+		reporter, err := recovery.NewSentry(server.Client(), dsn, "ams")
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Report(context.Background(), recovery.Panic{Message: "boom", RequestID: "req-1", Stack: "goroutine 1"}))
+		require.Equal(t, "/api/42/envelope/", path)
+		require.Contains(t, auth, "sentry_key=key")
+		require.Len(t, items, 3)
+		require.Equal(t, "event", items[1]["type"])
+		require.Equal(t, items[0]["event_id"], items[2]["event_id"])
+		require.Equal(t, map[string]any{"region": "ams", "request_id": "req-1"}, items[2]["tags"])
+		require.Equal(t, "boom", items[2]["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)["value"])
+	})
+}
@@ -0,0 +1,116 @@
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+type sentry struct {
+	httpClient *http.Client
+	url        string
+	auth       string
+	region     string
+}
+
+// NewSentry returns a reporter sending the panics to the project of the
+// Sentry DSN, e.g. "https://<key>@o0.ingest.sentry.io/<project>", tagged
+// with the region of the checker.
+func NewSentry(httpClient *http.Client, dsn, region string) (Reporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse sentry dsn: %w", err)
+	}
+	key := u.User.Username()
+	project := path.Base(u.Path)
+	if key == "" || project == "" || project == "/" || project == "." {
+		return nil, fmt.Errorf("invalid sentry dsn: missing key or project")
+	}
+
+	prefix := strings.TrimSuffix(path.Dir(u.Path), "/")
+	return sentry{
+		httpClient: httpClient,
+		url:        fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		auth:       fmt.Sprintf("Sentry sentry_version=7, sentry_client=openstatus-checker/1.0, sentry_key=%s", key),
+		region:     region,
+	}, nil
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryEvent struct {
+	EventID   string `json:"event_id"`
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Platform  string `json:"platform"`
+	Exception struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+	Tags  map[string]string `json:"tags"`
+	Extra map[string]string `json:"extra"`
+}
+
+// Report sends the panic as an event in an envelope, the stack being an
+// extra of the event.
+func (s sentry) Report(ctx context.Context, p Panic) error {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	event := sentryEvent{
+		EventID:   hex.EncodeToString(id),
+		Timestamp: p.Time.Format(time.RFC3339Nano),
+		Level:     "fatal",
+		Platform:  "go",
+		Tags:      map[string]string{"region": s.region},
+		Extra:     map[string]string{"stack": p.Stack},
+	}
+	event.Exception.Values = []sentryException{{Type: "panic", Value: p.Message}}
+	if p.RequestID != "" {
+		event.Tags["request_id"] = p.RequestID
+	}
+	if p.Route != "" {
+		event.Tags["route"] = p.Method + " " + p.Route
+	}
+
+	var payload bytes.Buffer
+	enc := json.NewEncoder(&payload)
+	for _, item := range []any{
+		map[string]string{"event_id": event.EventID, "sent_at": time.Now().UTC().Format(time.RFC3339Nano)},
+		map[string]string{"type": "event"},
+		event,
+	} {
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("unable to encode envelope: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, &payload)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package redact
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+)
+
+const placeholder = "[REDACTED]"
+
+// sensitiveParams are the query parameters whose value is always redacted.
+var sensitiveParams = []string{"token", "key", "secret", "password", "auth", "signature", "sig", "credential"}
+
+type pattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// urlPattern matches the urls in a free-form text, e.g. in an error message,
+// without their trailing punctuation.
+var urlPattern = regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://[^\s"'<>]*[^\s"'<>.,:;)]`)
+
+// defaultPatterns match credentials commonly found in error messages.
+var defaultPatterns = []pattern{
+	// The header name is kept to make the message understandable.
+	{re: regexp.MustCompile(`(?i)(authorization\s*[:=]\s*"?)[^\s",]+(\s+[^\s",]+)?`), replacement: "${1}" + placeholder},
+	{re: regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-z0-9._~+/=-]+`), replacement: placeholder},
+}
+
+type Redactor struct {
+	patterns []pattern
+}
+
+// New returns a Redactor scrubbing the default patterns plus the given ones.
+func New(patterns []*regexp.Regexp) Redactor {
+	r := Redactor{patterns: append([]pattern{}, defaultPatterns...)}
+	for _, re := range patterns {
+		r.patterns = append(r.patterns, pattern{re: re, replacement: placeholder})
+	}
+
+	return r
+}
+
+// String redacts the sensitive parts of a free-form text, the credentials of
+// its urls included.
+func (r Redactor) String(s string) string {
+	s = urlPattern.ReplaceAllStringFunc(s, redactURL)
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+
+	return s
+}
+
+// URL redacts the password and the sensitive query parameters of a url.
+func (r Redactor) URL(raw string) string {
+	return r.String(redactURL(raw))
+}
+
+// PingData redacts the url and the message of a check result.
+func (r Redactor) PingData(data checker.PingData) checker.PingData {
+	data.URL = r.URL(data.URL)
+	data.Message = r.String(data.Message)
+
+	return data
+}
+
+// redactURL redacts the password and the sensitive query parameters of a
+// url, left untouched without any.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	_, password := u.User.Password()
+	if password {
+		u.User = url.UserPassword(u.User.Username(), placeholder)
+	}
+
+	q := u.Query()
+	params := false
+	for name := range q {
+		if isSensitive(name) {
+			q.Set(name, placeholder)
+			params = true
+		}
+	}
+	if params {
+		u.RawQuery = q.Encode()
+	}
+	if !password && !params {
+		return raw
+	}
+
+	return u.String()
+}
+
+func isSensitive(param string) bool {
+	param = strings.ToLower(param)
+	for _, s := range sensitiveParams {
+		if strings.Contains(param, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type redactSink struct {
+	next     sink.Sink
+	redactor Redactor
+}
+
+// NewSink returns a sink redacting the check results before forwarding them
+// to the next sink.
+func NewSink(next sink.Sink, redactor Redactor) sink.Sink {
+	return redactSink{
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+func (s redactSink) SendEvent(ctx context.Context, event any) error {
+	if data, ok := event.(checker.PingData); ok {
+		event = s.redactor.PingData(data)
+	}
+
+	return s.next.SendEvent(ctx, event)
+}
+
+type redactNotifier struct {
+	next     notify.Notifier
+	redactor Redactor
+}
+
+// NewNotifier returns a notifier redacting the notifications before
+// forwarding them to the next notifier.
+func NewNotifier(next notify.Notifier, redactor Redactor) notify.Notifier {
+	return redactNotifier{
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+func (n redactNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	notification.URL = n.redactor.URL(notification.URL)
+	notification.Message = n.redactor.String(notification.Message)
+
+	return n.next.Notify(ctx, notification)
+}
@@ -0,0 +1,306 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/health"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/idempotency"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/stream"
+	checkerv1 "github.com/openstatushq/openstatus/apps/checker/proto/checker/v1"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type server struct {
+	checkerv1.UnimplementedCheckerServiceServer
+
+	run    RunFunc
+	health HealthFunc
+	broker *stream.Broker
+}
+
+// RunFunc runs a check and returns its result, or the error of a check
+// refused before running, e.g. a gRPC status telling the caller to back off.
+type RunFunc func(ctx context.Context, req request.CheckerRequest) (checker.PingData, error)
+
+// Health is the state of the checker.
+type Health struct {
+	Region string
+	// Queued is the number of checks waiting for a worker.
+	Queued int
+	// Draining is set once the checker is shutting down.
+	Draining bool
+	Report   health.Report
+}
+
+// HealthFunc returns the health of the checker.
+type HealthFunc func(ctx context.Context) Health
+
+// NewServer returns a gRPC server exposing the checker service. Every call
+// must carry the same authorization metadata as the HTTP endpoints, for a
+// POST to the full method of the call, e.g.
+// /checker.v1.CheckerService/RunCheck, whose body is its request message
+// encoded in protobuf, its fields in order: a signature is only valid for
+// that message. The options, e.g. the TLS credentials, are added to the
+// server.
+func NewServer(run RunFunc, health HealthFunc, broker *stream.Broker, authenticator auth.Authenticator, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			principal, err := authorize(ctx, authenticator, info.FullMethod, req)
+			if err != nil {
+				return nil, err
+			}
+			return handler(actor(ctx, principal), req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ss.Context(), authenticator: authenticator, method: info.FullMethod})
+		}),
+	}, opts...)...)
+	checkerv1.RegisterCheckerServiceServer(s, &server{
+		run:    run,
+		health: health,
+		broker: broker,
+	})
+
+	return s
+}
+
+// serverStream is a server stream authenticated with its request message,
+// the first one received, and then with the context of the authenticated
+// call. Nothing is sent before.
+type serverStream struct {
+	grpc.ServerStream
+	authenticator auth.Authenticator
+	method        string
+
+	mu            sync.Mutex
+	ctx           context.Context
+	authenticated bool
+}
+
+func (s *serverStream) Context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ctx
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.authenticated {
+		return nil
+	}
+	principal, err := authorize(s.ServerStream.Context(), s.authenticator, s.method, m)
+	if err != nil {
+		return err
+	}
+	s.ctx = actor(s.ServerStream.Context(), principal)
+	s.authenticated = true
+
+	return nil
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	s.mu.Lock()
+	authenticated := s.authenticated
+	s.mu.Unlock()
+	if !authenticated {
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+// IdempotencyKey returns the key of the call given by the caller in the
+// idempotency-key metadata, if any.
+func IdempotencyKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(idempotency.Header); len(keys) > 0 {
+		return keys[0]
+	}
+
+	return ""
+}
+
+// actor returns the context of a call authenticated for the principal, as
+// recorded by the audit log.
+func actor(ctx context.Context, principal string) context.Context {
+	a := audit.Actor{Source: "grpc"}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get("x-request-id"); len(ids) > 0 {
+			a.RequestID = ids[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		a.RemoteAddr = p.Addr.String()
+	}
+
+	return audit.WithActor(auth.WithPrincipal(ctx, principal), a)
+}
+
+// authorize authenticates the call as an http request to its method, with
+// its message as body, carrying its metadata as headers, and the TLS state
+// of its connection, and returns its principal.
+func authorize(ctx context.Context, authenticator auth.Authenticator, method string, msg any) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var body []byte
+	if m, ok := msg.(proto.Message); ok {
+		var err error
+		body, err = proto.MarshalOptions{Deterministic: true}.Marshal(m)
+		if err != nil {
+			return "", status.Error(codes.Internal, "internal error")
+		}
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, method, bytes.NewReader(body))
+	if err != nil {
+		return "", status.Error(codes.Internal, "internal error")
+	}
+	for key, values := range md {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+
+	principal, err := authenticator.Authenticate(r)
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	return principal, nil
+}
+
+func (s *server) RunCheck(ctx context.Context, req *checkerv1.RunCheckRequest) (*checkerv1.RunCheckResponse, error) {
+	if req.GetUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "url is required")
+	}
+
+	checkerRequest := request.CheckerRequest{
+		WorkspaceID:   req.GetWorkspaceId(),
+		URL:           req.GetUrl(),
+		MonitorID:     req.GetMonitorId(),
+		Method:        req.GetMethod(),
+		CronTimestamp: req.GetCronTimestamp(),
+		Body:          req.GetBody(),
+		Status:        req.GetStatus(),
+		Timeout:       req.GetTimeout(),
+	}
+	for _, header := range req.GetHeaders() {
+		checkerRequest.Headers = append(checkerRequest.Headers, struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}{Key: header.GetKey(), Value: header.GetValue()})
+	}
+
+	result, err := s.run(ctx, checkerRequest)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("failed to wait for a worker")
+		return nil, status.Error(codes.Unavailable, "checker overloaded")
+	}
+	if result.Invalid {
+		return nil, status.Error(codes.InvalidArgument, result.Message)
+	}
+
+	return &checkerv1.RunCheckResponse{
+		Result: toResult(result),
+	}, nil
+}
+
+func (s *server) GetHealth(ctx context.Context, _ *checkerv1.GetHealthRequest) (*checkerv1.GetHealthResponse, error) {
+	h := s.health(ctx)
+
+	res := &checkerv1.GetHealthResponse{
+		Status:       toStatus(h.Report.Status),
+		Region:       h.Region,
+		QueuedChecks: int32(h.Queued),
+	}
+	if h.Draining {
+		res.Status = checkerv1.HealthStatus_HEALTH_STATUS_DRAINING
+	}
+	for name, result := range h.Report.Dependencies {
+		res.Dependencies = append(res.Dependencies, &checkerv1.DependencyHealth{
+			Name:    name,
+			Status:  toStatus(result.Status),
+			Latency: result.Latency,
+			Error:   result.Error,
+		})
+	}
+	sort.Slice(res.Dependencies, func(i, j int) bool {
+		return res.Dependencies[i].Name < res.Dependencies[j].Name
+	})
+
+	return res, nil
+}
+
+func toStatus(status health.Status) checkerv1.HealthStatus {
+	switch status {
+	case health.Degraded:
+		return checkerv1.HealthStatus_HEALTH_STATUS_DEGRADED
+	case health.Down:
+		return checkerv1.HealthStatus_HEALTH_STATUS_DOWN
+	default:
+		return checkerv1.HealthStatus_HEALTH_STATUS_OK
+	}
+}
+
+func (s *server) StreamResults(req *checkerv1.StreamResultsRequest, srv checkerv1.CheckerService_StreamResultsServer) error {
+	events, unsubscribe := s.broker.Subscribe(func(event any) bool {
+		data, ok := event.(checker.PingData)
+		return ok &&
+			(req.GetMonitorId() == "" || data.MonitorID == req.GetMonitorId()) &&
+			(req.GetWorkspaceId() == "" || data.WorkspaceID == req.GetWorkspaceId())
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case event := <-events:
+			if err := srv.Send(toResult(event.(checker.PingData))); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toResult(data checker.PingData) *checkerv1.CheckResult {
+	return &checkerv1.CheckResult{
+		WorkspaceId:   data.WorkspaceID,
+		MonitorId:     data.MonitorID,
+		Timestamp:     data.Timestamp,
+		StatusCode:    int32(data.StatusCode),
+		Latency:       data.Latency,
+		CronTimestamp: data.CronTimestamp,
+		Url:           data.URL,
+		Region:        data.Region,
+		Message:       data.Message,
+	}
+}
@@ -0,0 +1,174 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/health"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/rpc"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/stream"
+	checkerv1 "github.com/openstatushq/openstatus/apps/checker/proto/checker/v1"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type discard struct{}
+
+func (discard) SendEvent(ctx context.Context, event any) error {
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	broker := stream.NewBroker(discard{}, 10)
+	runner := checker.NewRunner(target.Client(), broker, "ams", checker.WithLimits(request.DefaultLimits))
+	health := func(context.Context) rpc.Health {
+		return rpc.Health{Region: "ams", Queued: 2, Report: health.Report{
+			Status: health.Degraded,
+			Dependencies: map[string]health.Result{
+				"tinybird": {Status: health.Down, Error: "unreachable"},
+				"status":   {Status: health.OK},
+			},
+		}}
+	}
+	run := func(ctx context.Context, req request.CheckerRequest) (checker.PingData, error) {
+		return runner.Run(ctx, req), nil
+	}
+	s := rpc.NewServer(run, health, broker, auth.NewBasic(auth.NewKeyring(auth.Key{Name: "cron-secret", Secret: "secret"})))
+
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := checkerv1.NewCheckerServiceClient(conn)
+
+	t.Run("it should reject unauthorized calls", func(t *testing.T) {
+		_, err := client.RunCheck(ctx, &checkerv1.RunCheckRequest{Url: target.URL})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("it should run the check and return its result", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(ctx, "authorization", "Basic secret")
+
+		res, err := client.RunCheck(ctx, &checkerv1.RunCheckRequest{
+			Url:       target.URL,
+			MonitorId: "1",
+			Method:    http.MethodGet,
+			Status:    "active",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusOK), res.GetResult().GetStatusCode())
+		require.Equal(t, "1", res.GetResult().GetMonitorId())
+	})
+
+	t.Run("it should reject the invalid checks", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(ctx, "authorization", "Basic secret")
+
+		_, err := client.RunCheck(ctx, &checkerv1.RunCheckRequest{Url: "ftp://127.0.0.1", MonitorId: "1"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("it should return the health of the checker", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(ctx, "authorization", "Basic secret")
+
+		res, err := client.GetHealth(ctx, &checkerv1.GetHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, checkerv1.HealthStatus_HEALTH_STATUS_DEGRADED, res.GetStatus())
+		require.Equal(t, "ams", res.GetRegion())
+		require.Equal(t, int32(2), res.GetQueuedChecks())
+		require.Len(t, res.GetDependencies(), 2)
+		require.Equal(t, "tinybird", res.GetDependencies()[1].GetName())
+		require.Equal(t, checkerv1.HealthStatus_HEALTH_STATUS_DOWN, res.GetDependencies()[1].GetStatus())
+	})
+}
+
+func TestServerSigned(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	run := func(ctx context.Context, req request.CheckerRequest) (checker.PingData, error) {
+		if req.MonitorID == "throttled" {
+			return checker.PingData{}, status.Error(codes.ResourceExhausted, "workspace rate limit exceeded")
+		}
+		return checker.PingData{MonitorID: req.MonitorID, StatusCode: http.StatusOK}, nil
+	}
+	health := func(context.Context) rpc.Health { return rpc.Health{} }
+	keys := auth.NewKeyring(auth.Key{Name: "cron-secret", Secret: "secret"})
+	s := rpc.NewServer(run, health, stream.NewBroker(discard{}, 10), auth.NewHMAC(keys, time.Minute))
+
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := checkerv1.NewCheckerServiceClient(conn)
+	sign := func(req *checkerv1.RunCheckRequest) context.Context {
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		require.NoError(t, err)
+		timestamp := time.Now().Unix()
+		return metadata.AppendToOutgoingContext(ctx,
+			auth.TimestampHeader, strconv.FormatInt(timestamp, 10),
+			auth.SignatureHeader, auth.Sign("secret", timestamp, "", http.MethodPost, "/checker.v1.CheckerService/RunCheck", body),
+		)
+	}
+
+	t.Run("it should accept the signed message", func(t *testing.T) {
+		req := &checkerv1.RunCheckRequest{Url: "https://example.com", MonitorId: "1"}
+
+		res, err := client.RunCheck(sign(req), req)
+		require.NoError(t, err)
+		require.Equal(t, "1", res.GetResult().GetMonitorId())
+	})
+
+	t.Run("it should reject another message with the signature", func(t *testing.T) {
+		ctx := sign(&checkerv1.RunCheckRequest{Url: "https://example.com", MonitorId: "2"})
+
+		_, err := client.RunCheck(ctx, &checkerv1.RunCheckRequest{Url: "https://attacker.example", MonitorId: "2"})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("it should return the refusal of the check", func(t *testing.T) {
+		req := &checkerv1.RunCheckRequest{Url: "https://example.com", MonitorId: "throttled"}
+
+		_, err := client.RunCheck(sign(req), req)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
@@ -0,0 +1,237 @@
+package sampling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+)
+
+type Mode string
+
+const (
+	// ModeFull forwards every check result.
+	ModeFull Mode = "full"
+	// ModeSample forwards the full payload of one successful result every
+	// Rate checks, and of every failure, the other successful results as
+	// slim records.
+	ModeSample Mode = "sample"
+	// ModeRollup aggregates the results into minutely rollups.
+	ModeRollup Mode = "rollup"
+)
+
+// DefaultWorkspace is the key of the config applied to the workspaces
+// without their own.
+const DefaultWorkspace = "*"
+
+type Config struct {
+	Mode Mode
+	Rate int
+}
+
+// ParseConfig parses a config such as "full", "sample:10" or "rollup".
+func ParseConfig(value string) (Config, error) {
+	mode, rate, _ := strings.Cut(value, ":")
+
+	switch Mode(mode) {
+	case ModeFull, ModeRollup:
+		return Config{Mode: Mode(mode)}, nil
+	case ModeSample:
+		n, err := strconv.Atoi(rate)
+		if err != nil || n < 1 {
+			return Config{}, fmt.Errorf("invalid sample rate %q", rate)
+		}
+		return Config{Mode: ModeSample, Rate: n}, nil
+	default:
+		return Config{}, fmt.Errorf("unknown sampling mode %q", mode)
+	}
+}
+
+type Rollup struct {
+	WorkspaceID string `json:"workspaceId"`
+	MonitorID   string `json:"monitorId"`
+	URL         string `json:"url"`
+	Region      string `json:"region"`
+	Timestamp   int64  `json:"timestamp"`
+	Count       int    `json:"count"`
+	Errors      int    `json:"errors"`
+	LatencyMin  int64  `json:"latencyMin"`
+	LatencyMax  int64  `json:"latencyMax"`
+	LatencyAvg  int64  `json:"latencyAvg"`
+
+	latencySum int64
+}
+
+func (Rollup) EventType() string {
+	return "rollup"
+}
+
+func (r *Rollup) add(data checker.PingData) {
+	if r.Count == 0 || data.Latency < r.LatencyMin {
+		r.LatencyMin = data.Latency
+	}
+	if data.Latency > r.LatencyMax {
+		r.LatencyMax = data.Latency
+	}
+	if !isSuccessful(data) {
+		r.Errors++
+	}
+	r.Count++
+	r.latencySum += data.Latency
+	r.LatencyAvg = r.latencySum / int64(r.Count)
+}
+
+type rollupKey struct {
+	monitorID string
+	region    string
+}
+
+// Sampler is a sink controlling how much of the check results is forwarded
+// to the next sink, per workspace.
+type Sampler struct {
+	next    sink.Sink
+	configs map[string]Config
+
+	mu       sync.Mutex
+	counters map[string]int
+	rollups  map[rollupKey]*Rollup
+}
+
+func New(next sink.Sink, configs map[string]Config) *Sampler {
+	return &Sampler{
+		next:     next,
+		configs:  configs,
+		counters: map[string]int{},
+		rollups:  map[rollupKey]*Rollup{},
+	}
+}
+
+func (s *Sampler) config(workspaceID string) Config {
+	if config, ok := s.configs[workspaceID]; ok {
+		return config
+	}
+	if config, ok := s.configs[DefaultWorkspace]; ok {
+		return config
+	}
+
+	return Config{Mode: ModeFull}
+}
+
+func (s *Sampler) SendEvent(ctx context.Context, event any) error {
+	data, ok := event.(checker.PingData)
+	if !ok {
+		return s.next.SendEvent(ctx, event)
+	}
+
+	switch config := s.config(data.WorkspaceID); config.Mode {
+	case ModeSample:
+		if !isSuccessful(data) {
+			return s.next.SendEvent(ctx, event)
+		}
+
+		s.mu.Lock()
+		n := s.counters[data.MonitorID]
+		s.counters[data.MonitorID] = n + 1
+		s.mu.Unlock()
+
+		if n%config.Rate != 0 {
+			return s.next.SendEvent(ctx, slim(data))
+		}
+		return s.next.SendEvent(ctx, event)
+	case ModeRollup:
+		return s.rollup(ctx, data)
+	default:
+		return s.next.SendEvent(ctx, event)
+	}
+}
+
+func (s *Sampler) rollup(ctx context.Context, data checker.PingData) error {
+	minute := time.UnixMilli(data.Timestamp).Truncate(time.Minute).UnixMilli()
+	key := rollupKey{monitorID: data.MonitorID, region: data.Region}
+
+	s.mu.Lock()
+	current, ok := s.rollups[key]
+	var closed *Rollup
+	if !ok || current.Timestamp != minute {
+		closed = current
+		current = &Rollup{
+			WorkspaceID: data.WorkspaceID,
+			MonitorID:   data.MonitorID,
+			URL:         data.URL,
+			Region:      data.Region,
+			Timestamp:   minute,
+		}
+		s.rollups[key] = current
+	}
+	current.add(data)
+	s.mu.Unlock()
+
+	if closed != nil {
+		return s.next.SendEvent(ctx, *closed)
+	}
+
+	return nil
+}
+
+// Flush sends the rollups whose minute ended before the given time. Passing
+// the zero time flushes all of them.
+func (s *Sampler) Flush(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	var closed []Rollup
+	for key, rollup := range s.rollups {
+		if before.IsZero() || rollup.Timestamp+time.Minute.Milliseconds() <= before.UnixMilli() {
+			closed = append(closed, *rollup)
+			delete(s.rollups, key)
+		}
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, rollup := range closed {
+		if err := s.next.SendEvent(ctx, rollup); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run periodically flushes the ended rollups until the context is done.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_ = s.Flush(ctx, now)
+		}
+	}
+}
+
+// slim returns the record of a result without its payload, e.g. its message
+// or url, only telling when it ran, where, and how fast.
+func slim(data checker.PingData) checker.PingData {
+	return checker.PingData{
+		WorkspaceID:   data.WorkspaceID,
+		MonitorID:     data.MonitorID,
+		Timestamp:     data.Timestamp,
+		StatusCode:    data.StatusCode,
+		Latency:       data.Latency,
+		CronTimestamp: data.CronTimestamp,
+		Region:        data.Region,
+		Degraded:      data.Degraded,
+	}
+}
+
+func isSuccessful(data checker.PingData) bool {
+	return data.StatusCode >= 200 && data.StatusCode < 300
+}
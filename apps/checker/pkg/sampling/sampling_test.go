@@ -0,0 +1,115 @@
+package sampling_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sampling"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := sampling.ParseConfig("sample:10")
+	require.NoError(t, err)
+	require.Equal(t, sampling.Config{Mode: sampling.ModeSample, Rate: 10}, config)
+
+	_, err = sampling.ParseConfig("sample:0")
+	require.Error(t, err)
+
+	_, err = sampling.ParseConfig("unknown")
+	require.Error(t, err)
+}
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("it should forward one full successful check every rate and all failures", func(t *testing.T) {
+		r := &recorder{}
+		s := sampling.New(r, map[string]sampling.Config{
+			sampling.DefaultWorkspace: {Mode: sampling.ModeSample, Rate: 10},
+		})
+
+		for i := 0; i < 20; i++ {
+			require.NoError(t, s.SendEvent(ctx, checker.PingData{MonitorID: "1", StatusCode: 200, URL: "https://openstatus.dev", Latency: 42}))
+		}
+		require.NoError(t, s.SendEvent(ctx, checker.PingData{MonitorID: "1", StatusCode: 500, URL: "https://openstatus.dev"}))
+
+		require.Len(t, r.events, 21, "every check should be recorded")
+		var full int
+		for _, event := range r.events {
+			if event.(checker.PingData).URL != "" {
+				full++
+			}
+		}
+		require.Equal(t, 3, full)
+		require.Equal(t, checker.PingData{MonitorID: "1", StatusCode: 200, Latency: 42}, r.events[1])
+	})
+
+	t.Run("it should aggregate the checks into minutely rollups", func(t *testing.T) {
+		r := &recorder{}
+		s := sampling.New(r, map[string]sampling.Config{
+			"ws": {Mode: sampling.ModeRollup},
+		})
+
+		minute := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		for i, latency := range []int64{100, 300, 200} {
+			require.NoError(t, s.SendEvent(ctx, checker.PingData{
+				WorkspaceID: "ws",
+				MonitorID:   "1",
+				StatusCode:  200,
+				Latency:     latency,
+				Timestamp:   minute.Add(time.Duration(i) * 10 * time.Second).UnixMilli(),
+			}))
+		}
+		require.Empty(t, r.events)
+
+		require.NoError(t, s.SendEvent(ctx, checker.PingData{
+			WorkspaceID: "ws",
+			MonitorID:   "1",
+			StatusCode:  500,
+			Timestamp:   minute.Add(time.Minute).UnixMilli(),
+		}))
+		require.Len(t, r.events, 1)
+
+		rollup := r.events[0].(sampling.Rollup)
+		require.Equal(t, 3, rollup.Count)
+		require.Equal(t, 0, rollup.Errors)
+		require.Equal(t, int64(100), rollup.LatencyMin)
+		require.Equal(t, int64(300), rollup.LatencyMax)
+		require.Equal(t, int64(200), rollup.LatencyAvg)
+
+		require.NoError(t, s.Flush(ctx, time.Time{}))
+		require.Len(t, r.events, 2)
+		require.Equal(t, 1, r.events[1].(sampling.Rollup).Errors)
+	})
+
+	t.Run("it should forward every check by default", func(t *testing.T) {
+		r := &recorder{}
+		s := sampling.New(r, nil)
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, s.SendEvent(ctx, checker.PingData{MonitorID: "1", StatusCode: 200}))
+		}
+		require.Len(t, r.events, 5)
+	})
+}
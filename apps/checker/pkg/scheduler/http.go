@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+type cache struct {
+	ETag     string    `json:"etag"`
+	Monitors []Monitor `json:"monitors"`
+}
+
+type httpSource struct {
+	client    *http.Client
+	url       string
+	token     string
+	cachePath string
+
+	mu    sync.Mutex
+	cache *cache
+}
+
+// NewHTTPSource returns a source pulling the monitors from the OpenStatus
+// API, revalidated with their ETag. The last monitors are cached in memory
+// and in the cache file, if any, so the checker keeps running them through
+// the outages of the API, restarts included.
+func NewHTTPSource(client *http.Client, url, token, cachePath string) Source {
+	return &httpSource{
+		client:    client,
+		url:       url,
+		token:     token,
+		cachePath: cachePath,
+	}
+}
+
+func (s *httpSource) Monitors(ctx context.Context) ([]Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cache == nil {
+		s.cache = s.load(ctx)
+	}
+
+	monitors, err := s.fetch(ctx)
+	if err != nil {
+		if s.cache == nil {
+			return nil, err
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to fetch monitors, using the cached ones")
+		return s.cache.Monitors, nil
+	}
+
+	return monitors, nil
+}
+
+func (s *httpSource) fetch(ctx context.Context) ([]Monitor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	if s.cache != nil && s.cache.ETag != "" {
+		req.Header.Set("If-None-Match", s.cache.ETag)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch monitors: %w", err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusNotModified:
+		if s.cache == nil {
+			return nil, fmt.Errorf("unable to fetch monitors: not modified without cache")
+		}
+		return s.cache.Monitors, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("unable to fetch monitors: unexpected status %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read monitors: %w", err)
+	}
+
+	var monitors []Monitor
+	if err := json.Unmarshal(body, &monitors); err != nil {
+		return nil, fmt.Errorf("unable to decode monitors: %w", err)
+	}
+
+	s.cache = &cache{ETag: res.Header.Get("ETag"), Monitors: monitors}
+	s.save(ctx)
+
+	return monitors, nil
+}
+
+// load reads the cache file, if any.
+func (s *httpSource) load(ctx context.Context) *cache {
+	if s.cachePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.cachePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to read monitors cache")
+		}
+		return nil
+	}
+
+	var c cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to decode monitors cache")
+		return nil
+	}
+
+	return &c
+}
+
+// save writes the cache file, if any, through a temporary file so it is
+// never left half written.
+func (s *httpSource) save(ctx context.Context) {
+	if s.cachePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(s.cache)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to encode monitors cache")
+		return
+	}
+
+	tmp := s.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to write monitors cache")
+		return
+	}
+	if err := os.Rename(tmp, s.cachePath); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to write monitors cache")
+	}
+}
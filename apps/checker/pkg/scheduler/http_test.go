@@ -0,0 +1,75 @@
+package scheduler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		down        atomic.Bool
+		notModified atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"monitorId":"1","url":"https://openstat.us","periodicity":"1m"}]`))
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "monitors.cache.json")
+	source := scheduler.NewHTTPSource(server.Client(), server.URL, "token", cachePath)
+
+	t.Run("it should fetch the monitors", func(t *testing.T) {
+		monitors, err := source.Monitors(ctx)
+		require.NoError(t, err)
+		require.Len(t, monitors, 1)
+		require.Equal(t, "1", monitors[0].MonitorID)
+	})
+
+	t.Run("it should revalidate the monitors with their etag", func(t *testing.T) {
+		monitors, err := source.Monitors(ctx)
+		require.NoError(t, err)
+		require.Len(t, monitors, 1)
+		require.Equal(t, int32(1), notModified.Load())
+	})
+
+	t.Run("it should keep the monitors through outages", func(t *testing.T) {
+		down.Store(true)
+		defer down.Store(false)
+
+		monitors, err := source.Monitors(ctx)
+		require.NoError(t, err)
+		require.Len(t, monitors, 1)
+
+		// The cache file outlives the restarts.
+		restarted := scheduler.NewHTTPSource(server.Client(), server.URL, "token", cachePath)
+		monitors, err = restarted.Monitors(ctx)
+		require.NoError(t, err)
+		require.Len(t, monitors, 1)
+
+		empty := scheduler.NewHTTPSource(server.Client(), server.URL, "token", "")
+		_, err = empty.Monitors(ctx)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,277 @@
+package scheduler
+
+import (
+	"context"
+	"hash/fnv"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+)
+
+// RunFunc runs a check and returns its result.
+type RunFunc func(ctx context.Context, req request.CheckerRequest) checker.PingData
+
+type job struct {
+	monitor Monitor
+	cancel  context.CancelFunc
+}
+
+// Scheduler runs the monitors of a source on their own periodicity, without
+// an external cron calling the checker.
+type Scheduler struct {
+	source  Source
+	run     RunFunc
+	refresh time.Duration
+	jitter  float64
+	missed  sink.Sink
+	region  string
+	elector Elector
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	// wg tracks the loops of the jobs, for Run to return once they are done.
+	wg sync.WaitGroup
+}
+
+type Option func(*Scheduler)
+
+// WithJitter delays the start of the checks by a deterministic offset, up to
+// the given fraction of their interval, to avoid running them all at once.
+func WithJitter(ratio float64) Option {
+	return func(s *Scheduler) {
+		s.jitter = ratio
+	}
+}
+
+// Missed is the event of a check which did not run at its tick, e.g. because
+// the previous one took longer than the interval. It tells "not checked"
+// apart from "down" in the uptime.
+type Missed struct {
+	WorkspaceID   string `json:"workspaceId"`
+	MonitorID     string `json:"monitorId"`
+	CronTimestamp int64  `json:"cronTimestamp"`
+	Timestamp     int64  `json:"timestamp"`
+	Region        string `json:"region"`
+}
+
+func (Missed) EventType() string {
+	return "missed"
+}
+
+// WithMissed sends an event to the sink for every missed check.
+func WithMissed(missed sink.Sink, region string) Option {
+	return func(s *Scheduler) {
+		s.missed = missed
+		s.region = region
+	}
+}
+
+// Elector tells whether the checker is the leader of the checkers sharing
+// the monitors.
+type Elector interface {
+	Leader() bool
+}
+
+// WithElector only runs the checks while the checker is the leader, so the
+// monitors are checked once when several checkers share them.
+func WithElector(elector Elector) Option {
+	return func(s *Scheduler) {
+		s.elector = elector
+	}
+}
+
+// New returns a scheduler reloading the monitors of the source every
+// refresh.
+func New(source Source, run RunFunc, refresh time.Duration, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		source:  source,
+		run:     run,
+		refresh: refresh,
+		jobs:    map[string]*job{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Offset returns the delay of the checks of the monitor, up to ratio of the
+// interval. It only depends on the monitor id, so a monitor keeps running
+// at the same moment of its interval.
+func Offset(monitorID string, interval time.Duration, ratio float64) time.Duration {
+	window := time.Duration(float64(interval) * ratio)
+	if window <= 0 {
+		return 0
+	}
+	// The offset has to stay in the interval for the next tick to be right.
+	if window >= interval {
+		window = interval - 1
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(monitorID))
+	return time.Duration(h.Sum64() % uint64(window))
+}
+
+// Run schedules the monitors until the context is done, and returns once the
+// running checks are done.
+func (s *Scheduler) Run(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load monitors")
+	}
+
+	ticker := time.NewTicker(s.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.stopAll()
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload monitors")
+			}
+		}
+	}
+}
+
+// Reload loads the monitors from the source, starting the new ones,
+// restarting the changed ones and stopping the removed ones.
+func (s *Scheduler) Reload(ctx context.Context) error {
+	monitors, err := s.source.Monitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, monitor := range monitors {
+		interval, err := monitor.interval()
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("monitor", monitor.MonitorID).Msg("invalid monitor, skipping")
+			continue
+		}
+		incident, err := monitor.incidentInterval()
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("monitor", monitor.MonitorID).Msg("invalid monitor, skipping")
+			continue
+		}
+		seen[monitor.MonitorID] = true
+
+		if current, ok := s.jobs[monitor.MonitorID]; ok {
+			if reflect.DeepEqual(current.monitor, monitor) {
+				continue
+			}
+			current.cancel()
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		j := &job{monitor: monitor, cancel: cancel}
+		s.jobs[monitor.MonitorID] = j
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(jobCtx, j, interval, incident)
+		}()
+	}
+
+	for monitorID, j := range s.jobs {
+		if !seen[monitorID] {
+			j.cancel()
+			delete(s.jobs, monitorID)
+		}
+	}
+
+	return nil
+}
+
+func (s *Scheduler) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for monitorID, j := range s.jobs {
+		j.cancel()
+		delete(s.jobs, monitorID)
+	}
+}
+
+// loop runs the monitor at every multiple of its interval, like a cron would,
+// delayed by its offset. While the monitor fails, it runs at every multiple
+// of its incident interval instead, if any.
+func (s *Scheduler) loop(ctx context.Context, j *job, interval, incident time.Duration) {
+	current := interval
+	var last time.Time
+	for {
+		offset := Offset(j.monitor.MonitorID, current, s.jitter)
+		next := time.Now().Add(-offset).Truncate(current).Add(current)
+		if !last.IsZero() {
+			s.reportMissed(ctx, j.monitor, last.Add(current), next, current)
+		}
+
+		timer := time.NewTimer(time.Until(next.Add(offset)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// The followers do not report the missed checks of the leader.
+		if s.elector != nil && !s.elector.Leader() {
+			last = time.Time{}
+			continue
+		}
+
+		req := j.monitor.CheckerRequest
+		req.CronTimestamp = next.UnixMilli()
+		result := s.run(ctx, req)
+		last = next
+
+		if incident > 0 {
+			current = interval
+			if failed(result) {
+				current = incident
+			}
+		}
+	}
+}
+
+// failed reports whether the check ran and failed.
+func failed(result checker.PingData) bool {
+	if result.Paused || result.Throttled || result.Inactive || result.Misrouted || result.Invalid {
+		return false
+	}
+
+	return result.StatusCode < 200 || result.StatusCode >= 300
+}
+
+// reportMissed reports the ticks from from until to, excluded.
+func (s *Scheduler) reportMissed(ctx context.Context, monitor Monitor, from, to time.Time, interval time.Duration) {
+	for tick := from; tick.Before(to); tick = tick.Add(interval) {
+		log.Ctx(ctx).Warn().Str("monitor", monitor.MonitorID).Time("tick", tick).Msg("missed check")
+		if s.missed == nil {
+			continue
+		}
+
+		err := s.missed.SendEvent(ctx, Missed{
+			WorkspaceID:   monitor.WorkspaceID,
+			MonitorID:     monitor.MonitorID,
+			CronTimestamp: tick.UnixMilli(),
+			Timestamp:     time.Now().UnixMilli(),
+			Region:        s.region,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to send missed check event")
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource []scheduler.Monitor
+
+func (s staticSource) Monitors(ctx context.Context) ([]scheduler.Monitor, error) {
+	return s, nil
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		reqs []request.CheckerRequest
+	)
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		mu.Lock()
+		defer mu.Unlock()
+		reqs = append(reqs, req)
+		return checker.PingData{StatusCode: 200}
+	}
+
+	source := staticSource{{
+		CheckerRequest: request.CheckerRequest{MonitorID: "1", URL: "https://openstat.us"},
+		Periodicity:    "50ms",
+	}, {
+		CheckerRequest: request.CheckerRequest{MonitorID: "2"},
+		Periodicity:    "invalid",
+	}}
+
+	s := scheduler.New(source, run, time.Hour)
+	go s.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reqs) >= 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, req := range reqs {
+		require.Equal(t, "1", req.MonitorID)
+		require.Zero(t, req.CronTimestamp%50, "the checks should be aligned on the periodicity")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return checker.PingData{StatusCode: 200}
+	}
+
+	source := staticSource{{
+		CheckerRequest: request.CheckerRequest{MonitorID: "1"},
+		Periodicity:    "10ms",
+	}}
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.New(source, run, time.Hour).Run(ctx)
+		close(stopped)
+	}()
+
+	<-started
+	cancel()
+
+	t.Run("it should wait for the running checks", func(t *testing.T) {
+		select {
+		case <-stopped:
+			t.Fatal("the scheduler stopped before the running check")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("the scheduler did not stop")
+		}
+	})
+}
+
+func TestOffset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should not delay without jitter", func(t *testing.T) {
+		require.Zero(t, scheduler.Offset("1", time.Minute, 0))
+	})
+
+	t.Run("it should be deterministic and within the window", func(t *testing.T) {
+		for _, id := range []string{"1", "2", "3", "42"} {
+			offset := scheduler.Offset(id, time.Minute, 0.5)
+			require.Equal(t, offset, scheduler.Offset(id, time.Minute, 0.5))
+			require.GreaterOrEqual(t, offset, time.Duration(0))
+			require.Less(t, offset, 30*time.Second)
+		}
+		require.NotEqual(t, scheduler.Offset("1", time.Minute, 1), scheduler.Offset("2", time.Minute, 1))
+	})
+
+	t.Run("it should stay in the interval", func(t *testing.T) {
+		require.Less(t, scheduler.Offset("1", time.Minute, 2), time.Minute)
+	})
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestMissed(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var once sync.Once
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		// The first check takes longer than the interval.
+		once.Do(func() { time.Sleep(120 * time.Millisecond) })
+		return checker.PingData{StatusCode: 200}
+	}
+
+	source := staticSource{{
+		CheckerRequest: request.CheckerRequest{MonitorID: "1", WorkspaceID: "2"},
+		Periodicity:    "50ms",
+	}}
+
+	r := &recorder{}
+	s := scheduler.New(source, run, time.Hour, scheduler.WithMissed(r, "ams"))
+	go s.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.events) >= 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	missed, ok := r.events[0].(scheduler.Missed)
+	require.True(t, ok)
+	require.Equal(t, "1", missed.MonitorID)
+	require.Equal(t, "2", missed.WorkspaceID)
+	require.Equal(t, "ams", missed.Region)
+	require.Zero(t, missed.CronTimestamp%50)
+	require.Equal(t, int64(50), r.events[1].(scheduler.Missed).CronTimestamp-missed.CronTimestamp)
+}
+
+type follower struct{}
+
+func (follower) Leader() bool { return false }
+
+func TestElector(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		ran bool
+	)
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = true
+		return checker.PingData{StatusCode: 200}
+	}
+
+	source := staticSource{{
+		CheckerRequest: request.CheckerRequest{MonitorID: "1"},
+		Periodicity:    "10ms",
+	}}
+
+	r := &recorder{}
+	s := scheduler.New(source, run, time.Hour, scheduler.WithElector(follower{}), scheduler.WithMissed(r, "ams"))
+	go s.Run(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.False(t, ran, "the followers should not run the checks")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.Empty(t, r.events)
+}
+
+func TestSubMinuteInterval(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		interval string
+		want     time.Duration
+		err      bool
+	}{
+		{interval: "", want: 0},
+		{interval: "1m", want: 0},
+		{interval: "10s", want: 10 * time.Second},
+		{interval: "1s", want: time.Second},
+		{interval: "7s", err: true},
+		{interval: "500ms", err: true},
+		{interval: "2m", err: true},
+		{interval: "often", err: true},
+	} {
+		got, err := scheduler.SubMinuteInterval(request.CheckerRequest{Interval: tt.interval})
+		if tt.err {
+			require.Error(t, err, tt.interval)
+			continue
+		}
+		require.NoError(t, err, tt.interval)
+		require.Equal(t, tt.want, got, tt.interval)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu   sync.Mutex
+		reqs []request.CheckerRequest
+	)
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		mu.Lock()
+		defer mu.Unlock()
+		reqs = append(reqs, req)
+		return checker.PingData{StatusCode: 200}
+	}
+
+	// The tick started long enough ago for the last runs only to remain.
+	tick := time.Now().Add(-scheduler.CronPeriod + 25*time.Millisecond).Truncate(10 * time.Millisecond)
+	scheduler.Repeat(context.Background(), request.CheckerRequest{MonitorID: "1", CronTimestamp: tick.UnixMilli()}, 10*time.Millisecond, run)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, reqs)
+	for _, req := range reqs {
+		require.Equal(t, "1", req.MonitorID)
+		require.Zero(t, (req.CronTimestamp-tick.UnixMilli())%10, "the runs should be tagged with their scheduled timestamp")
+		require.Less(t, req.CronTimestamp, tick.Add(scheduler.CronPeriod).UnixMilli())
+	}
+}
+
+func TestRepeater(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		runs.Add(1)
+		return checker.PingData{StatusCode: 200}
+	}
+
+	tick := time.Now().Add(-scheduler.CronPeriod + 100*time.Millisecond)
+	req := request.CheckerRequest{MonitorID: "1", CronTimestamp: tick.UnixMilli()}
+	r := scheduler.NewRepeater()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Repeat(context.Background(), req, 10*time.Millisecond, run)
+		}()
+	}
+	retry := req
+	retry.Retry = 1
+	r.Repeat(context.Background(), retry, 10*time.Millisecond, run)
+	wg.Wait()
+
+	require.NotZero(t, runs.Load())
+	require.LessOrEqual(t, runs.Load(), int32(10), "a single series should run for the tick")
+}
+
+func TestIncidentPeriodicity(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		reqs []request.CheckerRequest
+	)
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		mu.Lock()
+		defer mu.Unlock()
+		reqs = append(reqs, req)
+		return checker.PingData{StatusCode: 500}
+	}
+
+	source := staticSource{{
+		CheckerRequest:      request.CheckerRequest{MonitorID: "1"},
+		Periodicity:         "200ms",
+		IncidentPeriodicity: "20ms",
+	}}
+
+	s := scheduler.New(source, run, time.Hour)
+	go s.Run(ctx)
+
+	// Without the incident periodicity, the second check would take 200ms.
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reqs) >= 3
+	}, 350*time.Millisecond, 5*time.Millisecond)
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Zero(t, reqs[2].CronTimestamp%20)
+}
+
+func TestIncidentPeriodicitySkipped(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	run := func(ctx context.Context, req request.CheckerRequest) checker.PingData {
+		runs.Add(1)
+		return checker.PingData{Inactive: true}
+	}
+
+	source := staticSource{{
+		CheckerRequest:      request.CheckerRequest{MonitorID: "1"},
+		Periodicity:         "200ms",
+		IncidentPeriodicity: "20ms",
+	}}
+
+	s := scheduler.New(source, run, time.Hour)
+	go s.Run(ctx)
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, 300*time.Millisecond, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int32(1), runs.Load(), "the checks not run should not shorten the periodicity")
+}
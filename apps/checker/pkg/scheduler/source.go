@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// Monitor is a check run by the scheduler every periodicity.
+type Monitor struct {
+	request.CheckerRequest
+	Periodicity string `json:"periodicity"`
+	// Location is the private location running the monitor, if any.
+	Location string `json:"location,omitempty"`
+	// IncidentPeriodicity, e.g. "30s", replaces the periodicity while the
+	// monitor fails, for a finer measure of the downtime.
+	IncidentPeriodicity string `json:"incidentPeriodicity,omitempty"`
+}
+
+func (m Monitor) interval() (time.Duration, error) {
+	interval, err := time.ParseDuration(m.Periodicity)
+	if err != nil {
+		return 0, fmt.Errorf("invalid periodicity %q: %w", m.Periodicity, err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid periodicity %q", m.Periodicity)
+	}
+
+	return interval, nil
+}
+
+func (m Monitor) incidentInterval() (time.Duration, error) {
+	if m.IncidentPeriodicity == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(m.IncidentPeriodicity)
+	if err != nil {
+		return 0, fmt.Errorf("invalid incident periodicity %q: %w", m.IncidentPeriodicity, err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid incident periodicity %q", m.IncidentPeriodicity)
+	}
+
+	return interval, nil
+}
+
+// Source loads the monitor definitions.
+type Source interface {
+	Monitors(ctx context.Context) ([]Monitor, error)
+}
+
+type fileSource struct {
+	path string
+}
+
+// NewFileSource returns a source reading the monitors from a JSON file
+// containing an array of monitors.
+func NewFileSource(path string) Source {
+	return fileSource{path: path}
+}
+
+func (s fileSource) Monitors(ctx context.Context) ([]Monitor, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read monitors: %w", err)
+	}
+
+	var monitors []Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		return nil, fmt.Errorf("unable to decode monitors: %w", err)
+	}
+
+	return monitors, nil
+}
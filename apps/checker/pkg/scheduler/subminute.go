@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// CronPeriod is the period of the cron calling the checker.
+const CronPeriod = time.Minute
+
+// SubMinuteInterval parses the interval of a request running more than once
+// per cron tick. It returns 0 for the requests running once.
+func SubMinuteInterval(req request.CheckerRequest) (time.Duration, error) {
+	if req.Interval == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", req.Interval, err)
+	}
+	if interval < time.Second || interval > CronPeriod || CronPeriod%interval != 0 {
+		return 0, fmt.Errorf("invalid interval %q: it must divide %s, and be at least 1s", req.Interval, CronPeriod)
+	}
+	if interval == CronPeriod {
+		return 0, nil
+	}
+
+	return interval, nil
+}
+
+// Repeat runs the check at every interval of the cron tick of the request,
+// after the first one run by the caller, until the next tick. Each run is
+// tagged with its scheduled timestamp.
+func Repeat(ctx context.Context, req request.CheckerRequest, interval time.Duration, run RunFunc) {
+	tick := time.UnixMilli(req.CronTimestamp)
+	if req.CronTimestamp == 0 {
+		tick = time.Now()
+	}
+
+	// The runs already late when the request arrives are skipped.
+	next := tick.Add(interval)
+	for now := time.Now(); next.Before(now); {
+		next = next.Add(interval)
+	}
+
+	for ; next.Before(tick.Add(CronPeriod)); next = next.Add(interval) {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sub := req
+		sub.CronTimestamp = next.UnixMilli()
+		run(ctx, sub)
+	}
+}
+
+// Repeater runs the series of the sub-minute checks, at most one per monitor
+// and cron tick, e.g. when the request of a tick is delivered twice.
+type Repeater struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func NewRepeater() *Repeater {
+	return &Repeater{running: map[string]bool{}}
+}
+
+// Repeat runs the series of the request as Repeat does, unless the series of
+// its monitor for the tick already runs. The retries of a check never start
+// a series, the first run did.
+func (r *Repeater) Repeat(ctx context.Context, req request.CheckerRequest, interval time.Duration, run RunFunc) {
+	if req.Retry > 0 {
+		return
+	}
+
+	key := req.WorkspaceID + ":" + req.MonitorID + ":" + strconv.FormatInt(req.CronTimestamp, 10)
+	r.mu.Lock()
+	if r.running[key] {
+		r.mu.Unlock()
+		return
+	}
+	r.running[key] = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, key)
+		r.mu.Unlock()
+	}()
+
+	Repeat(ctx, req, interval, run)
+}
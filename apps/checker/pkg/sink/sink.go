@@ -0,0 +1,94 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var sendDuration, _ = telemetry.Meter().Int64Histogram("checker.sink.duration",
+	metric.WithUnit("ms"),
+	metric.WithDescription("Time spent sending an event to a sink."),
+)
+
+// Sink is a destination for check results.
+type Sink interface {
+	SendEvent(ctx context.Context, event any) error
+}
+
+// DefaultTimeout bounds the sending of an event to each sink.
+const DefaultTimeout = 10 * time.Second
+
+type fanout struct {
+	sinks   map[string]Sink
+	timeout time.Duration
+}
+
+type Option func(*fanout)
+
+// WithTimeout bounds the sending of an event to each sink (default 10s), so
+// a hung sink does not hold the check.
+func WithTimeout(timeout time.Duration) Option {
+	return func(f *fanout) {
+		f.timeout = timeout
+	}
+}
+
+// NewFanout returns a Sink that forwards every event to all the given sinks
+// concurrently, each within the timeout. A failing sink does not prevent the
+// others from receiving the event, the errors are only collected and
+// returned together, for the caller to log.
+func NewFanout(sinks map[string]Sink, opts ...Option) Sink {
+	f := fanout{sinks: sinks, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return f
+}
+
+func (f fanout) SendEvent(ctx context.Context, event any) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for name, s := range f.sinks {
+		wg.Add(1)
+		go func(name string, s Sink) {
+			defer wg.Done()
+
+			ctx, span := telemetry.Tracer().Start(ctx, "sink.SendEvent", trace.WithAttributes(attribute.String("sink", name)))
+			defer span.End()
+			ctx, cancel := context.WithTimeout(ctx, f.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := s.SendEvent(ctx, event)
+			sendDuration.Record(ctx, time.Since(start).Milliseconds(), metric.WithAttributes(
+				attribute.String("sink", name),
+				attribute.Bool("success", err == nil),
+			))
+
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				mu.Unlock()
+			}
+		}(name, s)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package sink_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/stretchr/testify/require"
+)
+
+type sinkFunc func(ctx context.Context, event any) error
+
+func (f sinkFunc) SendEvent(ctx context.Context, event any) error {
+	return f(ctx, event)
+}
+
+func TestFanout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("it should send the event to every sink", func(t *testing.T) {
+		var calls atomic.Int32
+		ok := sinkFunc(func(ctx context.Context, event any) error {
+			calls.Add(1)
+			return nil
+		})
+
+		s := sink.NewFanout(map[string]sink.Sink{"a": ok, "b": ok, "c": ok})
+
+		require.NoError(t, s.SendEvent(ctx, "event"))
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("it should still send to the other sinks if one fails", func(t *testing.T) {
+		var calls atomic.Int32
+		ok := sinkFunc(func(ctx context.Context, event any) error {
+			calls.Add(1)
+			return nil
+		})
+		failing := sinkFunc(func(ctx context.Context, event any) error {
+			return fmt.Errorf("unable to send request")
+		})
+
+		s := sink.NewFanout(map[string]sink.Sink{"a": ok, "b": failing, "c": ok})
+
+		err := s.SendEvent(ctx, "event")
+		require.Error(t, err)
+		require.ErrorContains(t, err, "b: unable to send request")
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("it should not wait for a hung sink", func(t *testing.T) {
+		hung := sinkFunc(func(ctx context.Context, event any) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		s := sink.NewFanout(map[string]sink.Sink{"hung": hung}, sink.WithTimeout(10*time.Millisecond))
+
+		err := s.SendEvent(ctx, "event")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "hung:")
+	})
+}
@@ -0,0 +1,120 @@
+package ssrf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked is the error of the connections to a blocked address.
+var ErrBlocked = errors.New("address not allowed")
+
+// blocked are the ranges not reachable from the public internet: loopback,
+// private, link-local, including the cloud metadata endpoints, shared,
+// multicast and reserved addresses.
+var blocked = prefixes(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func prefixes(values ...string) []netip.Prefix {
+	p := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		p = append(p, netip.MustParsePrefix(value))
+	}
+
+	return p
+}
+
+// Guard rejects the connections to the internal addresses, but the allowed
+// ones, e.g. for the self-hosted checkers monitoring their own network.
+type Guard struct {
+	allowed []netip.Prefix
+}
+
+// New returns a guard allowing the ranges, e.g. "10.1.0.0/16", on top of
+// the public addresses.
+func New(allowed []string) (*Guard, error) {
+	g := &Guard{}
+	for _, value := range allowed {
+		if value == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse range %q: %w", value, err)
+		}
+		g.allowed = append(g.allowed, prefix)
+	}
+
+	return g, nil
+}
+
+// Allowed reports whether the address can be connected to.
+func (g *Guard) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	for _, prefix := range blocked {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Control verifies the address of a connection, once resolved, for every
+// connection of a request, its redirects included.
+func (g *Guard) Control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unable to parse address %q: %w", address, err)
+	}
+	if !g.Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlocked, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// Transport returns a transport connecting only to the allowed addresses.
+// It never goes through a proxy, which would connect in its place.
+func (g *Guard) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   g.Control,
+	}).DialContext
+
+	return t
+}
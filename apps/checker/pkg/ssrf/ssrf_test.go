@@ -0,0 +1,86 @@
+package ssrf_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should block the internal addresses", func(t *testing.T) {
+		g, err := ssrf.New(nil)
+		require.NoError(t, err)
+
+		for addr, allowed := range map[string]bool{
+			"1.1.1.1":                true,
+			"2606:4700:4700::1111":   true,
+			"127.0.0.1":              false,
+			"10.0.0.1":               false,
+			"172.16.5.4":             false,
+			"192.168.1.1":            false,
+			"169.254.169.254":        false,
+			"100.64.0.1":             false,
+			"0.0.0.0":                false,
+			"::1":                    false,
+			"fe80::1":                false,
+			"fdaa::3":                false,
+			"::ffff:169.254.169.254": false,
+		} {
+			require.Equal(t, allowed, g.Allowed(netip.MustParseAddr(addr)), addr)
+		}
+	})
+
+	t.Run("it should allow the configured ranges", func(t *testing.T) {
+		g, err := ssrf.New([]string{"10.1.0.0/16"})
+		require.NoError(t, err)
+
+		require.True(t, g.Allowed(netip.MustParseAddr("10.1.2.3")))
+		require.False(t, g.Allowed(netip.MustParseAddr("10.2.0.1")))
+	})
+
+	t.Run("it should reject an invalid range", func(t *testing.T) {
+		_, err := ssrf.New([]string{"10.1.0.0"})
+		require.Error(t, err)
+	})
+
+	t.Run("it should refuse to connect to a blocked address", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		g, err := ssrf.New(nil)
+		require.NoError(t, err)
+
+		_, err = (&http.Client{Transport: g.Transport()}).Get(server.URL)
+		require.ErrorIs(t, err, ssrf.ErrBlocked)
+	})
+
+	t.Run("it should verify the redirects", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.2:0")
+		require.NoError(t, err)
+		internal := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		internal.Listener.Close()
+		internal.Listener = lis
+		internal.Start()
+		defer internal.Close()
+
+		public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.URL, http.StatusFound)
+		}))
+		defer public.Close()
+
+		// The public server stands for a public address.
+		g, err := ssrf.New([]string{"127.0.0.1/32"})
+		require.NoError(t, err)
+		client := &http.Client{Transport: g.Transport()}
+
+		_, err = client.Get(public.URL)
+		require.ErrorIs(t, err, ssrf.ErrBlocked)
+	})
+}
@@ -0,0 +1,71 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/rs/zerolog/log"
+)
+
+type Client interface {
+	SendEvent(ctx context.Context, event any) error
+}
+
+type client struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewClient returns a client emitting the check results as DogStatsD metrics
+// to the given udp address. The tags are ignored by plain StatsD servers.
+func NewClient(addr, prefix string) (Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial statsd: %w", err)
+	}
+
+	return client{
+		conn:   conn,
+		prefix: prefix,
+	}, nil
+}
+
+func (c client) SendEvent(ctx context.Context, event any) error {
+	data, ok := event.(checker.PingData)
+	if !ok {
+		return nil
+	}
+
+	success := data.StatusCode >= 200 && data.StatusCode < 300
+	tags := []string{
+		"monitor:" + data.MonitorID,
+		"workspace:" + data.WorkspaceID,
+		"region:" + data.Region,
+		"status_code:" + strconv.Itoa(data.StatusCode),
+		"success:" + strconv.FormatBool(success),
+	}
+
+	metrics := []string{
+		c.metric("check.latency", strconv.FormatInt(data.Latency, 10), "ms", tags),
+		c.metric("check.count", "1", "c", tags),
+	}
+	if !success {
+		metrics = append(metrics, c.metric("check.error", "1", "c", tags))
+	}
+
+	// A single datagram per check, metrics are separated by new lines.
+	if _, err := c.conn.Write([]byte(strings.Join(metrics, "\n"))); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to send metrics")
+		return fmt.Errorf("unable to send metrics: %w", err)
+	}
+
+	return nil
+}
+
+func (c client) metric(name, value, kind string, tags []string) string {
+	return fmt.Sprintf("%s%s:%s|%s|#%s", c.prefix, name, value, kind, strings.Join(tags, ","))
+}
@@ -0,0 +1,43 @@
+package statsd_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/statsd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := statsd.NewClient(conn.LocalAddr().String(), "openstatus.")
+	require.NoError(t, err)
+
+	err = client.SendEvent(ctx, checker.PingData{MonitorID: "1", WorkspaceID: "2", Region: "ams", StatusCode: 500, Latency: 42})
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	lines := strings.Split(string(buf[:n]), "\n")
+	tags := "monitor:1,workspace:2,region:ams,status_code:500,success:false"
+	require.Equal(t, []string{
+		"openstatus.check.latency:42|ms|#" + tags,
+		"openstatus.check.count:1|c|#" + tags,
+		"openstatus.check.error:1|c|#" + tags,
+	}, lines)
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/incident"
+	"github.com/rs/zerolog/log"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS results (
+	workspace_id   TEXT NOT NULL,
+	monitor_id     TEXT NOT NULL,
+	timestamp      INTEGER NOT NULL,
+	status_code    INTEGER NOT NULL,
+	latency        INTEGER NOT NULL,
+	cron_timestamp INTEGER NOT NULL,
+	url            TEXT NOT NULL,
+	region         TEXT NOT NULL,
+	message        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_monitor_timestamp ON results (monitor_id, timestamp);
+CREATE TABLE IF NOT EXISTS incidents (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id TEXT NOT NULL,
+	monitor_id   TEXT NOT NULL,
+	region       TEXT NOT NULL,
+	started_at   INTEGER NOT NULL,
+	resolved_at  INTEGER NOT NULL DEFAULT 0,
+	results      TEXT NOT NULL
+);
+`
+
+// Store keeps the recent check results in a local SQLite database.
+type Store struct {
+	db        *sql.DB
+	retention time.Duration
+}
+
+// Open opens, and creates if needed, the database at path. Results older
+// than the retention are pruned by Run.
+func Open(path string, retention time.Duration) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+	// SQLite only supports a single writer.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create schema: %w", err)
+	}
+
+	return &Store{
+		db:        db,
+		retention: retention,
+	}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Ping verifies the database can be read from the disk.
+func (s *Store) Ping(ctx context.Context) error {
+	var pages int64
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return fmt.Errorf("unable to read database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) SendEvent(ctx context.Context, event any) error {
+	data, ok := event.(checker.PingData)
+	if !ok {
+		return nil
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO results (workspace_id, monitor_id, timestamp, status_code, latency, cron_timestamp, url, region, message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		data.WorkspaceID, data.MonitorID, data.Timestamp, data.StatusCode, data.Latency, data.CronTimestamp, data.URL, data.Region, data.Message,
+	); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to insert result")
+		return fmt.Errorf("unable to insert result: %w", err)
+	}
+
+	return nil
+}
+
+type Query struct {
+	MonitorID string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// Results returns the results matching the query, most recent first.
+func (s *Store) Results(ctx context.Context, q Query) ([]checker.PingData, error) {
+	results := []checker.PingData{}
+	err := s.Each(ctx, q, func(data checker.PingData) error {
+		results = append(results, data)
+		return nil
+	})
+
+	return results, err
+}
+
+// pageSize is the number of results read at once by Each. The only
+// connection to the database is released between the pages, not to hold
+// the inserts of the checks during a slow export.
+const pageSize = 500
+
+// row is a result read from the database, with its rowid to read the next
+// page from.
+type row struct {
+	data  checker.PingData
+	rowID int64
+}
+
+// Each calls fn for every result matching the query, most recent first,
+// without loading them all in memory. A negative limit means no limit.
+func (s *Store) Each(ctx context.Context, q Query, fn func(checker.PingData) error) error {
+	to := q.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	// The pages are read by keyset, from the last result of the previous
+	// page.
+	timestamp, rowID := to.UnixMilli(), int64(math.MaxInt64)
+	for read := 0; limit < 0 || read < limit; {
+		size := pageSize
+		if limit > 0 {
+			size = min(size, limit-read)
+		}
+		rows, err := s.page(ctx, q.MonitorID, q.From.UnixMilli(), timestamp, rowID, size)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := fn(r.data); err != nil {
+				return err
+			}
+		}
+		if len(rows) < size {
+			return nil
+		}
+		read += len(rows)
+		timestamp, rowID = rows[len(rows)-1].data.Timestamp, rows[len(rows)-1].rowID
+	}
+
+	return nil
+}
+
+// page returns at most size results of the monitor from the timestamp,
+// before the result of the timestamp and rowid, most recent first.
+func (s *Store) page(ctx context.Context, monitorID string, from, timestamp, rowID int64, size int) ([]row, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT rowid, workspace_id, monitor_id, timestamp, status_code, latency, cron_timestamp, url, region, message
+		FROM results
+		WHERE (? = '' OR monitor_id = ?) AND timestamp >= ? AND (timestamp < ? OR timestamp = ? AND rowid < ?)
+		ORDER BY timestamp DESC, rowid DESC
+		LIMIT ?`,
+		monitorID, monitorID, from, timestamp, timestamp, rowID, size,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query results: %w", err)
+	}
+	defer rows.Close()
+
+	page := make([]row, 0, size)
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.rowID, &r.data.WorkspaceID, &r.data.MonitorID, &r.data.Timestamp, &r.data.StatusCode, &r.data.Latency, &r.data.CronTimestamp, &r.data.URL, &r.data.Region, &r.data.Message); err != nil {
+			return nil, fmt.Errorf("unable to scan result: %w", err)
+		}
+		page = append(page, r)
+	}
+
+	return page, rows.Err()
+}
+
+// OpenIncident records the incident, the incidents are never pruned.
+func (s *Store) OpenIncident(ctx context.Context, i incident.Incident) (string, error) {
+	results, err := json.Marshal(i.Results)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode results: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO incidents (workspace_id, monitor_id, region, started_at, results) VALUES (?, ?, ?, ?, ?)`,
+		i.WorkspaceID, i.MonitorID, i.Region, i.StartedAt, string(results),
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to insert incident: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("unable to get incident id: %w", err)
+	}
+
+	return strconv.FormatInt(id, 10), nil
+}
+
+func (s *Store) ResolveIncident(ctx context.Context, id string, resolvedAt int64) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE incidents SET resolved_at = ? WHERE id = ?`, resolvedAt, id); err != nil {
+		return fmt.Errorf("unable to resolve incident: %w", err)
+	}
+
+	return nil
+}
+
+// Incidents returns the incidents of the monitor, or of every monitor, most
+// recent first.
+func (s *Store) Incidents(ctx context.Context, monitorID string, limit int) ([]incident.Incident, error) {
+	if limit == 0 {
+		limit = 100
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, workspace_id, monitor_id, region, started_at, resolved_at, results
+		FROM incidents
+		WHERE (? = '' OR monitor_id = ?)
+		ORDER BY started_at DESC
+		LIMIT ?`,
+		monitorID, monitorID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []incident.Incident{}
+	for rows.Next() {
+		var (
+			i       incident.Incident
+			id      int64
+			results string
+		)
+		if err := rows.Scan(&id, &i.WorkspaceID, &i.MonitorID, &i.Region, &i.StartedAt, &i.ResolvedAt, &results); err != nil {
+			return nil, fmt.Errorf("unable to scan incident: %w", err)
+		}
+		if err := json.Unmarshal([]byte(results), &i.Results); err != nil {
+			return nil, fmt.Errorf("unable to decode results: %w", err)
+		}
+		i.ID = strconv.FormatInt(id, 10)
+		incidents = append(incidents, i)
+	}
+
+	return incidents, rows.Err()
+}
+
+// Prune deletes the results older than the retention.
+func (s *Store) Prune(ctx context.Context) error {
+	before := time.Now().Add(-s.retention).UnixMilli()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM results WHERE timestamp < ?`, before); err != nil {
+		return fmt.Errorf("unable to prune results: %w", err)
+	}
+
+	return nil
+}
+
+// Run periodically prunes the old results until the context is done.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Prune(ctx); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to prune results")
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/incident"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "checker.db"), time.Hour)
+	require.NoError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	for i, monitorID := range []string{"1", "2", "1"} {
+		require.NoError(t, s.SendEvent(ctx, checker.PingData{
+			MonitorID:  monitorID,
+			StatusCode: 200,
+			Latency:    int64(i),
+			Timestamp:  now.Add(time.Duration(i) * time.Second).UnixMilli(),
+		}))
+	}
+	require.NoError(t, s.SendEvent(ctx, checker.PingData{MonitorID: "1", Timestamp: now.Add(-2 * time.Hour).UnixMilli()}))
+
+	t.Run("it should return the results of a monitor, most recent first", func(t *testing.T) {
+		results, err := s.Results(ctx, store.Query{MonitorID: "1", To: now.Add(time.Minute)})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.Equal(t, int64(2), results[0].Latency)
+	})
+
+	t.Run("it should prune the results older than the retention", func(t *testing.T) {
+		require.NoError(t, s.Prune(ctx))
+
+		results, err := s.Results(ctx, store.Query{To: now.Add(time.Minute)})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+	})
+
+	t.Run("it should read the database", func(t *testing.T) {
+		require.NoError(t, s.Ping(ctx))
+	})
+}
+
+func TestEach(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, err := store.Open(filepath.Join(t.TempDir(), "checker.db"), time.Hour)
+	require.NoError(t, err)
+	defer s.Close()
+
+	// Several results share a timestamp, across the pages.
+	now := time.Now()
+	for i := 0; i < 1200; i++ {
+		require.NoError(t, s.SendEvent(ctx, checker.PingData{MonitorID: "1", Latency: int64(i), Timestamp: now.Add(-time.Duration(i/3) * time.Millisecond).UnixMilli()}))
+	}
+
+	t.Run("it should read every result once, most recent first", func(t *testing.T) {
+		seen := map[int64]bool{}
+		last := now.UnixMilli()
+		require.NoError(t, s.Each(ctx, store.Query{MonitorID: "1", To: now, Limit: -1}, func(data checker.PingData) error {
+			require.False(t, seen[data.Latency])
+			require.LessOrEqual(t, data.Timestamp, last)
+			seen[data.Latency], last = true, data.Timestamp
+			return nil
+		}))
+		require.Len(t, seen, 1200)
+	})
+
+	t.Run("it should insert the results during a read", func(t *testing.T) {
+		read := 0
+		require.NoError(t, s.Each(ctx, store.Query{MonitorID: "1", To: now, Limit: 600}, func(data checker.PingData) error {
+			read++
+			if read == 1 {
+				ctx, cancel := context.WithTimeout(ctx, time.Second)
+				defer cancel()
+				return s.SendEvent(ctx, checker.PingData{MonitorID: "2", Timestamp: now.UnixMilli()})
+			}
+			return nil
+		}))
+		require.Equal(t, 600, read)
+	})
+}
+
+func TestIncidents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, err := store.Open(filepath.Join(t.TempDir(), "checker.db"), time.Hour)
+	require.NoError(t, err)
+	defer s.Close()
+
+	id, err := s.OpenIncident(ctx, incident.Incident{
+		MonitorID: "1",
+		StartedAt: 1000,
+		Results:   []checker.PingData{{MonitorID: "1", StatusCode: 500}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.ResolveIncident(ctx, id, 2000))
+
+	incidents, err := s.Incidents(ctx, "1", 0)
+	require.NoError(t, err)
+	require.Len(t, incidents, 1)
+	require.Equal(t, id, incidents[0].ID)
+	require.Equal(t, int64(2000), incidents[0].ResolvedAt)
+	require.Equal(t, 500, incidents[0].Results[0].StatusCode)
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+)
+
+// Filter selects the events a subscriber receives, it receives all of them
+// when nil.
+type Filter func(event any) bool
+
+type subscriber struct {
+	events chan any
+	filter Filter
+}
+
+// Broker is a sink publishing every event to its live subscribers before
+// forwarding it to the next sink.
+type Broker struct {
+	next sink.Sink
+	size int
+
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+// NewBroker returns a Broker buffering up to size events per subscriber.
+func NewBroker(next sink.Sink, size int) *Broker {
+	return &Broker{
+		next:        next,
+		size:        size,
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+func (b *Broker) SendEvent(ctx context.Context, event any) error {
+	b.mu.RLock()
+	for s := range b.subscribers {
+		if s.filter != nil && !s.filter(event) {
+			continue
+		}
+		// Slow subscribers miss events rather than slowing down the checks.
+		select {
+		case s.events <- event:
+		default:
+		}
+	}
+	b.mu.RUnlock()
+
+	return b.next.SendEvent(ctx, event)
+}
+
+// Subscribe returns a channel receiving the events matching the filter. The
+// returned function must be called to unsubscribe.
+func (b *Broker) Subscribe(filter Filter) (<-chan any, func()) {
+	s := &subscriber{
+		events: make(chan any, b.size),
+		filter: filter,
+	}
+
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s.events, func() {
+		b.mu.Lock()
+		delete(b.subscribers, s)
+		b.mu.Unlock()
+	}
+}
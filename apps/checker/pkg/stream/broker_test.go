@@ -0,0 +1,47 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/stream"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestBroker(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &recorder{}
+	b := stream.NewBroker(r, 1)
+
+	all, unsubscribeAll := b.Subscribe(nil)
+	odd, unsubscribeOdd := b.Subscribe(func(event any) bool { return event.(int)%2 == 1 })
+	defer unsubscribeOdd()
+
+	require.NoError(t, b.SendEvent(ctx, 1))
+	require.NoError(t, b.SendEvent(ctx, 2))
+
+	// The buffer holds a single event, the second one is dropped.
+	require.Equal(t, 1, <-all)
+	require.Empty(t, all)
+	require.Equal(t, 1, <-odd)
+
+	unsubscribeAll()
+	require.NoError(t, b.SendEvent(ctx, 3))
+	require.Empty(t, all)
+	require.Equal(t, 3, <-odd)
+
+	require.Equal(t, []any{1, 2, 3}, r.events)
+}
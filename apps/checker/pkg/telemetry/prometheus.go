@@ -0,0 +1,161 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Prometheus collects the metrics of the checker when scraped, and serves
+// them in the Prometheus text format.
+type Prometheus struct {
+	reader *sdkmetric.ManualReader
+}
+
+func NewPrometheus() *Prometheus {
+	return &Prometheus{reader: sdkmetric.NewManualReader()}
+}
+
+// Reader returns the reader to register with the meter provider.
+func (p *Prometheus) Reader() sdkmetric.Reader {
+	return p.reader
+}
+
+// ServeHTTP serves the metrics collected at the time of the request.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := p.Collect(r.Context(), w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Collect collects the metrics and writes them in the text format.
+func (p *Prometheus) Collect(ctx context.Context, w io.Writer) error {
+	var rm metricdata.ResourceMetrics
+	if err := p.reader.Collect(ctx, &rm); err != nil {
+		return fmt.Errorf("unable to collect metrics: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			writeMetric(bw, m)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// units are the suffixes of the names of the metrics, by unit.
+var units = map[string]string{
+	"ms": "_milliseconds",
+	"s":  "_seconds",
+	"By": "_bytes",
+}
+
+func writeMetric(w *bufio.Writer, m metricdata.Metrics) {
+	name := sanitize(m.Name) + units[m.Unit]
+
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		writeSum(w, name, m.Description, data.IsMonotonic, data.DataPoints)
+	case metricdata.Sum[float64]:
+		writeSum(w, name, m.Description, data.IsMonotonic, data.DataPoints)
+	case metricdata.Gauge[int64]:
+		writeSum(w, name, m.Description, false, data.DataPoints)
+	case metricdata.Gauge[float64]:
+		writeSum(w, name, m.Description, false, data.DataPoints)
+	case metricdata.Histogram[int64]:
+		writeHistogram(w, name, m.Description, data.DataPoints)
+	case metricdata.Histogram[float64]:
+		writeHistogram(w, name, m.Description, data.DataPoints)
+	}
+}
+
+func writeSum[N int64 | float64](w *bufio.Writer, name, description string, monotonic bool, points []metricdata.DataPoint[N]) {
+	kind := "gauge"
+	if monotonic {
+		kind, name = "counter", name+"_total"
+	}
+	writeHeader(w, name, description, kind)
+
+	for _, point := range points {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels(point.Attributes), format(float64(point.Value)))
+	}
+}
+
+func writeHistogram[N int64 | float64](w *bufio.Writer, name, description string, points []metricdata.HistogramDataPoint[N]) {
+	writeHeader(w, name, description, "histogram")
+
+	for _, point := range points {
+		// The buckets are cumulative, the last one being +Inf.
+		var count uint64
+		for i, bound := range point.Bounds {
+			count += point.BucketCounts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(point.Attributes, "le", format(bound)), count)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(point.Attributes, "le", "+Inf"), point.Count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels(point.Attributes), format(float64(point.Sum)))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, labels(point.Attributes), point.Count)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, description, kind string) {
+	if description != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(description))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// escape escapes the values of the labels.
+var escape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels returns the labels of the attributes, along with the extra label,
+// if any, e.g. the bound of a bucket.
+func labels(set attribute.Set, extra ...string) string {
+	var pairs []string
+	iter := set.Iter()
+	for iter.Next() {
+		kv := iter.Attribute()
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, sanitize(string(kv.Key)), escape.Replace(kv.Value.Emit())))
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[0], extra[1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sanitize replaces the characters not allowed in the names of the metrics
+// and labels, e.g. the dots of "checker.ping.latency".
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func format(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,61 @@
+package telemetry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestPrometheus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	prometheus := telemetry.NewPrometheus()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(prometheus.Reader())).Meter("test")
+
+	checks, err := meter.Int64Counter("checker.checks", metric.WithDescription("Checks run."))
+	require.NoError(t, err)
+	checks.Add(ctx, 2, metric.WithAttributes(attribute.String("region", "ams"), attribute.String("status", "error")))
+
+	queued, err := meter.Int64UpDownCounter("checker.pool.queued")
+	require.NoError(t, err)
+	queued.Add(ctx, 3)
+
+	latency, err := meter.Int64Histogram("checker.ping.latency", metric.WithUnit("ms"))
+	require.NoError(t, err)
+	latency.Record(ctx, 7)
+	latency.Record(ctx, 30)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	prometheus.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+
+	t.Run("it should export the counters", func(t *testing.T) {
+		require.Contains(t, body, "# HELP checker_checks_total Checks run.\n")
+		require.Contains(t, body, "# TYPE checker_checks_total counter\n")
+		require.Contains(t, body, `checker_checks_total{region="ams",status="error"} 2`+"\n")
+	})
+
+	t.Run("it should export the up down counters as gauges", func(t *testing.T) {
+		require.Contains(t, body, "# TYPE checker_pool_queued gauge\n")
+		require.Contains(t, body, "checker_pool_queued 3\n")
+	})
+
+	t.Run("it should export the cumulative buckets of the histograms", func(t *testing.T) {
+		require.Contains(t, body, "# TYPE checker_ping_latency_milliseconds histogram\n")
+		require.Contains(t, body, `checker_ping_latency_milliseconds_bucket{le="5"} 0`+"\n")
+		require.Contains(t, body, `checker_ping_latency_milliseconds_bucket{le="10"} 1`+"\n")
+		require.Contains(t, body, `checker_ping_latency_milliseconds_bucket{le="+Inf"} 2`+"\n")
+		require.Contains(t, body, "checker_ping_latency_milliseconds_sum 37\n")
+		require.Contains(t, body, "checker_ping_latency_milliseconds_count 2\n")
+	})
+}
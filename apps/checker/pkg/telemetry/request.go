@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RequestIDHeader is the header carrying the ID of the requests, accepted
+// from the callers and sent along the calls to the other services.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the IDs accepted from the callers, the longer
+// ones being replaced.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID returns the context of the request of the ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request of the context, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestID returns the ID given by the caller when valid, or a new one.
+func requestID(given string) string {
+	valid := given != "" && len(given) <= maxRequestIDLength
+	for _, r := range given {
+		valid = valid && r > ' ' && r <= '~'
+	}
+	if valid {
+		return given
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// Transport returns a transport sending the trace context and the ID of the
+// request of the context along the requests, e.g. to the other services of
+// OpenStatus.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return transport{next: next}
+}
+
+func (t transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	// The request must not be modified, it is cloned with its headers.
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+	if id := RequestID(ctx); id != "" {
+		r.Header.Set(RequestIDHeader, id)
+	}
+
+	return t.next.RoundTrip(r)
+}
@@ -0,0 +1,65 @@
+package telemetry_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	var outbound http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outbound = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: telemetry.Transport(nil)}
+	router := gin.New()
+	router.Use(telemetry.Middleware())
+	router.GET("/checker", func(c *gin.Context) {
+		req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, upstream.URL, nil)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		c.String(http.StatusOK, telemetry.RequestID(c.Request.Context()))
+	})
+
+	t.Run("it should keep the ID given by the caller", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/checker", nil)
+		r.Header.Set("X-Request-ID", "req-1")
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, "req-1", w.Body.String())
+		require.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+		require.Equal(t, "req-1", outbound.Get("X-Request-ID"))
+	})
+
+	t.Run("it should generate an ID when none is valid", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/checker", nil)
+		r.Header.Set("X-Request-ID", "not valid")
+		router.ServeHTTP(w, r)
+
+		id := w.Header().Get("X-Request-ID")
+		require.Len(t, id, 32)
+		require.Equal(t, id, outbound.Get("X-Request-ID"))
+	})
+
+	t.Run("it should continue the trace of the caller", func(t *testing.T) {
+		traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/checker", nil)
+		r.Header.Set("traceparent", traceparent)
+		router.ServeHTTP(w, r)
+
+		require.Contains(t, w.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+		require.Contains(t, outbound.Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+	})
+}
@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/openstatushq/openstatus/apps/checker"
+
+// The trace context is propagated even when the traces are not exported, for
+// the other services to keep tracing the requests.
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Setup registers the global tracer and meter providers exporting over OTLP,
+// the metrics being also collected by the readers, e.g. Prometheus. The
+// exporters are configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables. The returned function flushes and stops them.
+func Setup(ctx context.Context, serviceName, region string, readers ...sdkmetric.Reader) (func(context.Context) error, error) {
+	res, err := newResource(serviceName, region)
+	if err != nil {
+		return nil, err
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create trace exporter: %w", err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+
+	metricExporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create metric exporter: %w", err)
+	}
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	}
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(opts...)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}, nil
+}
+
+// SetupMetrics registers the global meter provider, without exporting over
+// OTLP, the metrics only being collected by the readers.
+func SetupMetrics(serviceName, region string, readers ...sdkmetric.Reader) (func(context.Context) error, error) {
+	res, err := newResource(serviceName, region)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(meterProvider)
+
+	return meterProvider.Shutdown, nil
+}
+
+func newResource(serviceName, region string) (*resource.Resource, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.CloudRegion(region),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create resource: %w", err)
+	}
+
+	return res, nil
+}
+
+// Tracer returns the tracer used to instrument the checker.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Meter returns the meter used to instrument the checker.
+func Meter() metric.Meter {
+	return otel.Meter(instrumentationName)
+}
+
+// Middleware starts a span for every request, continuing the trace of the
+// caller if any. The ID of the request, given by the caller or generated, is
+// added to the logs of the request, and answered along with the trace
+// context.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		id := requestID(c.GetHeader(RequestIDHeader))
+		ctx, span := Tracer().Start(ctx, fmt.Sprintf("%s %s", c.Request.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(c.Request.Method),
+				semconv.HTTPRoute(route),
+				attribute.String("request.id", id),
+			),
+		)
+		defer span.End()
+
+		logger := log.Ctx(ctx).With().Str("request_id", id)
+		if sc := span.SpanContext(); sc.IsValid() {
+			logger = logger.Str("trace_id", sc.TraceID().String())
+		}
+		ctx = logger.Logger().WithContext(WithRequestID(ctx, id))
+
+		c.Header(RequestIDHeader, id)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("status code %d", status))
+		}
+	}
+}
@@ -11,33 +11,110 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-const baseURL = "https://api.tinybird.co/v0/events"
+const (
+	defaultBaseURL    = "https://api.tinybird.co/v0/events"
+	defaultDatasource = "ping_response__v5"
+	pingEventType     = "ping"
+)
 
 type Client interface {
 	SendEvent(ctx context.Context, event any) error
 }
 
+// Typed is implemented by events that can be routed to their own datasource.
+type Typed interface {
+	EventType() string
+}
+
+type Option func(*client)
+
+// WithBaseURL overrides the events API url, e.g. for the EU region.
+func WithBaseURL(baseURL string) Option {
+	return func(c *client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithDatasource sends the events of the given type to the datasource.
+func WithDatasource(eventType, datasource string) Option {
+	return func(c *client) {
+		c.datasources[eventType] = datasource
+	}
+}
+
+// WithDefaultDatasource sets the datasource used for untyped events and ping
+// events. Other typed events need a datasource of their own.
+func WithDefaultDatasource(datasource string) Option {
+	return func(c *client) {
+		c.defaultDatasource = datasource
+	}
+}
+
+// WithWait makes tinybird acknowledge the events only once they are written.
+func WithWait(wait bool) Option {
+	return func(c *client) {
+		c.wait = wait
+	}
+}
+
 type client struct {
-	httpClient *http.Client
-	apiKey     string
+	httpClient        *http.Client
+	apiKey            string
+	baseURL           string
+	defaultDatasource string
+	datasources       map[string]string
+	wait              bool
+}
+
+func NewClient(httpClient *http.Client, apiKey string, opts ...Option) Client {
+	c := &client{
+		httpClient:        httpClient,
+		apiKey:            apiKey,
+		baseURL:           defaultBaseURL,
+		defaultDatasource: defaultDatasource,
+		datasources:       map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return *c
 }
 
-func NewClient(httpClient *http.Client, apiKey string) Client {
-	return client{
-		httpClient: httpClient,
-		apiKey:     apiKey,
+func (c client) datasource(event any) (string, error) {
+	typed, ok := event.(Typed)
+	if !ok {
+		return c.defaultDatasource, nil
 	}
+	eventType := typed.EventType()
+	if datasource, ok := c.datasources[eventType]; ok {
+		return datasource, nil
+	}
+	if eventType == pingEventType {
+		return c.defaultDatasource, nil
+	}
+
+	return "", fmt.Errorf("no datasource for event type %q", eventType)
 }
 
 func (c client) SendEvent(ctx context.Context, event any) error {
-	requestURL, err := url.Parse(baseURL)
+	requestURL, err := url.Parse(c.baseURL)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("unable to parse url")
 		return fmt.Errorf("unable to parse url: %w", err)
 	}
 
+	datasource, err := c.datasource(event)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to route event")
+		return err
+	}
+
 	q := requestURL.Query()
-	q.Add("name", "ping_response__v5")
+	q.Add("name", datasource)
+	if c.wait {
+		q.Add("wait", "true")
+	}
 	requestURL.RawQuery = q.Encode()
 
 	var payload bytes.Buffer
@@ -60,7 +137,8 @@ func (c client) SendEvent(ctx context.Context, event any) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	// With wait=true tinybird answers 200, otherwise 202 once the event is queued.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		log.Ctx(ctx).Error().Str("status", resp.Status).Msg("unexpected status code")
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
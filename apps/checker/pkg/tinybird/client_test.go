@@ -76,3 +76,54 @@ func TestSendEvent(t *testing.T) {
 		require.Equal(t, "https://api.tinybird.co/v0/events?name=ping_response__v5", url)
 	})
 }
+
+type typedEvent struct{}
+
+func (typedEvent) EventType() string {
+	return "rollup"
+}
+
+func TestSendEventWithOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var url string
+	interceptor := &interceptorHTTPClient{
+		f: func(req *http.Request) (*http.Response, error) {
+			url = req.URL.String()
+			return &http.Response{
+				StatusCode: http.StatusOK,
+			}, nil
+		},
+	}
+
+	client := tinybird.NewClient(interceptor.GetHTTPClient(), "apiKey",
+		tinybird.WithBaseURL("https://api.eu.tinybird.co/v0/events"),
+		tinybird.WithDefaultDatasource("ping_response__staging"),
+		tinybird.WithDatasource("rollup", "rollup__v1"),
+		tinybird.WithWait(true),
+	)
+
+	t.Run("it should use the default datasource for untyped events", func(t *testing.T) {
+		err := client.SendEvent(ctx, "event")
+		require.NoError(t, err)
+		require.Equal(t, "https://api.eu.tinybird.co/v0/events?name=ping_response__staging&wait=true", url)
+	})
+
+	t.Run("it should route typed events to their datasource", func(t *testing.T) {
+		err := client.SendEvent(ctx, typedEvent{})
+		require.NoError(t, err)
+		require.Equal(t, "https://api.eu.tinybird.co/v0/events?name=rollup__v1&wait=true", url)
+	})
+
+	t.Run("it should not send typed events without a datasource", func(t *testing.T) {
+		url = ""
+		client := tinybird.NewClient(interceptor.GetHTTPClient(), "apiKey")
+
+		err := client.SendEvent(ctx, typedEvent{})
+		require.Error(t, err)
+		require.Empty(t, url)
+	})
+}
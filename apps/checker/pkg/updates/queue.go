@@ -0,0 +1,432 @@
+package updates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	updatesRetried, _ = telemetry.Meter().Int64Counter("checker.status_updates.retries",
+		metric.WithDescription("Retries of the failed status updates."),
+	)
+	updatesFailed, _ = telemetry.Meter().Int64Counter("checker.status_updates.failed",
+		metric.WithDescription("Status updates given up, by region and status."),
+	)
+)
+
+// idle is the delay between two deliveries without pending update.
+const idle = time.Minute
+
+// item is a pending update, persisted with the queue.
+type item struct {
+	Data     checker.UpdateData `json:"data"`
+	Attempts int                `json:"attempts"`
+	NextAt   time.Time          `json:"nextAt"`
+
+	// version tells whether the item was replaced during its delivery.
+	version uint64
+}
+
+// Queue delivers the status updates to the updater in the background,
+// retrying the failed ones with an exponential backoff. The updates of a
+// monitor in a region are delivered in order, a newer status replacing the
+// last pending one, unless an error: the API alerts on each error.
+type Queue struct {
+	updater    checker.StatusUpdater
+	path       string
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	batch      int
+	linger     time.Duration
+	timeout    time.Duration
+
+	mu sync.Mutex
+	// items are the pending updates of each monitor in a region, the oldest
+	// first.
+	items   map[string][]*item
+	version uint64
+	wake    chan struct{}
+}
+
+type Option func(*Queue)
+
+// WithRetries gives up the updates failing more than retries times (default
+// 10).
+func WithRetries(retries int) Option {
+	return func(q *Queue) {
+		q.retries = retries
+	}
+}
+
+// WithBackoff sets the delays between the retries, doubling from min up to
+// max (default 1s and 5m).
+func WithBackoff(min, max time.Duration) Option {
+	return func(q *Queue) {
+		q.minBackoff = min
+		q.maxBackoff = max
+	}
+}
+
+// WithBatch sends the updates by batches of at most size, once they waited
+// the linger, e.g. for the other changes of a fan-out run. The updates are
+// sent one by one when the updater does not support the batches, or when it
+// rejects a batch, to only give up its invalid updates.
+func WithBatch(size int, linger time.Duration) Option {
+	return func(q *Queue) {
+		q.batch = size
+		q.linger = linger
+	}
+}
+
+// WithTimeout bounds each delivery to the updater, an update or a batch
+// (default 30s).
+func WithTimeout(timeout time.Duration) Option {
+	return func(q *Queue) {
+		q.timeout = timeout
+	}
+}
+
+// New returns the queue of the updater. The pending updates are persisted to
+// the file at path, if any, and delivered again after a restart: each update
+// is appended to the file, rewritten once the deliveries are done.
+func New(updater checker.StatusUpdater, path string, opts ...Option) (*Queue, error) {
+	q := &Queue{
+		updater:    updater,
+		path:       path,
+		retries:    10,
+		minBackoff: time.Second,
+		maxBackoff: 5 * time.Minute,
+		batch:      1,
+		timeout:    30 * time.Second,
+		items:      map[string][]*item{},
+		wake:       make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	if path == "" {
+		return q, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read queue: %w", err)
+	}
+	// The file holds an item per line, or an array of items when written by
+	// a previous version.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("unable to decode queue: %w", err)
+		}
+		var items []*item
+		if bytes.HasPrefix(raw, []byte("[")) {
+			err = json.Unmarshal(raw, &items)
+		} else {
+			items = append(items, &item{})
+			err = json.Unmarshal(raw, items[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode queue: %w", err)
+		}
+		for _, i := range items {
+			q.add(i)
+		}
+	}
+
+	return q, nil
+}
+
+func key(data checker.UpdateData) string {
+	return data.MonitorId + ":" + data.Region
+}
+
+// UpdateStatus queues the update, to be delivered by Run.
+func (q *Queue) UpdateStatus(ctx context.Context, data checker.UpdateData) error {
+	q.mu.Lock()
+	i := &item{Data: data, NextAt: time.Now()}
+	q.add(i)
+	err := q.append(i)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return err
+}
+
+// add adds the item after the pending updates of its monitor in a region,
+// replacing the last one unless an error.
+func (q *Queue) add(i *item) {
+	q.version++
+	i.version = q.version
+	k := key(i.Data)
+	if pending := q.items[k]; len(pending) > 0 && pending[len(pending)-1].Data.Status != "error" {
+		pending[len(pending)-1] = i
+	} else {
+		q.items[k] = append(pending, i)
+	}
+}
+
+// Pending returns the number of updates not delivered yet.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := 0
+	for _, items := range q.items {
+		pending += len(items)
+	}
+
+	return pending
+}
+
+// Run delivers the updates until the context is done.
+func (q *Queue) Run(ctx context.Context) {
+	next := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+			// The new updates wait for the linger, to be batched with the
+			// following ones.
+			if at := time.Now().Add(q.linger); at.Before(next) {
+				next = at
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(time.Until(next))
+			}
+			continue
+		case <-timer.C:
+		}
+
+		next = q.deliver(ctx, time.Now())
+		timer.Reset(time.Until(next))
+	}
+}
+
+// Flush tries to deliver the pending updates, whatever their backoff, e.g.
+// on shutdown, until one fails. It returns an error when some remain
+// pending.
+func (q *Queue) Flush(ctx context.Context) error {
+	for pending := q.Pending(); pending > 0; {
+		q.deliver(ctx, time.Time{})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		left := q.Pending()
+		if left >= pending {
+			return fmt.Errorf("%d status updates pending", left)
+		}
+		pending = left
+	}
+
+	return nil
+}
+
+// deliver delivers the oldest update of each monitor in a region, when due
+// at now or whatever its backoff with a zero now, and returns when the next
+// one is due.
+func (q *Queue) deliver(ctx context.Context, now time.Time) time.Time {
+	q.mu.Lock()
+	var due []item
+	for _, items := range q.items {
+		if i := items[0]; now.IsZero() || !i.NextAt.After(now) {
+			due = append(due, *i)
+		}
+	}
+	q.mu.Unlock()
+
+	delivered := len(due) > 0
+	batcher, ok := q.updater.(checker.BatchStatusUpdater)
+	size := q.batch
+	if !ok || size < 1 {
+		size = 1
+	}
+	for len(due) > 0 && ctx.Err() == nil {
+		batch := due[:min(size, len(due))]
+		due = due[len(batch):]
+
+		if len(batch) == 1 {
+			q.done(ctx, batch[0], q.update(ctx, batch[0].Data))
+			continue
+		}
+
+		data := make([]checker.UpdateData, 0, len(batch))
+		for _, i := range batch {
+			data = append(data, i.Data)
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, q.timeout)
+		err := batcher.UpdateStatuses(attemptCtx, data)
+		cancel()
+		var permanent *backoff.PermanentError
+		if errors.As(err, &permanent) {
+			// A single invalid update rejects the whole batch.
+			for _, i := range batch {
+				q.done(ctx, i, q.update(ctx, i.Data))
+			}
+			continue
+		}
+		for _, i := range batch {
+			q.done(ctx, i, err)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	next := time.Now().Add(idle)
+	for _, items := range q.items {
+		if i := items[0]; i.NextAt.Before(next) {
+			next = i.NextAt
+		}
+	}
+	if delivered {
+		if err := q.save(); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to persist the status updates")
+		}
+	}
+
+	return next
+}
+
+// update delivers a single update, within the timeout.
+func (q *Queue) update(ctx context.Context, data checker.UpdateData) error {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	return q.updater.UpdateStatus(ctx, data)
+}
+
+// remove removes the oldest update of the monitor in a region.
+func (q *Queue) remove(k string) {
+	if len(q.items[k]) > 1 {
+		q.items[k] = q.items[k][1:]
+		return
+	}
+	delete(q.items, k)
+}
+
+// done records the delivery of the item, rescheduling it when it failed.
+func (q *Queue) done(ctx context.Context, i item, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	k := key(i.Data)
+	items, ok := q.items[k]
+	if !ok || items[0].version != i.version {
+		// Replaced by a newer status during the delivery.
+		return
+	}
+	current := items[0]
+	if err == nil {
+		q.remove(k)
+		return
+	}
+
+	attributes := metric.WithAttributes(attribute.String("region", i.Data.Region), attribute.String("status", i.Data.Status))
+	var permanent *backoff.PermanentError
+	if errors.As(err, &permanent) || ctx.Err() == nil && current.Attempts >= q.retries {
+		q.remove(k)
+		updatesFailed.Add(ctx, 1, attributes)
+		log.Ctx(ctx).Error().Err(err).
+			Str("monitor", i.Data.MonitorId).
+			Str("region", i.Data.Region).
+			Str("status", i.Data.Status).
+			Int("attempts", current.Attempts+1).
+			Msg("status update failed, giving up")
+		return
+	}
+
+	delay := q.maxBackoff
+	if current.Attempts < 20 {
+		delay = min(q.minBackoff<<current.Attempts, q.maxBackoff)
+	}
+	current.Attempts++
+	current.NextAt = time.Now().Add(delay)
+	updatesRetried.Add(ctx, 1, attributes)
+	log.Ctx(ctx).Warn().Err(err).
+		Str("monitor", i.Data.MonitorId).
+		Int("attempts", current.Attempts).
+		Dur("retry_in", delay).
+		Msg("status update failed, retrying")
+}
+
+// append persists the new item at the end of the file, replayed in order on
+// restart, without rewriting the pending ones.
+func (q *Queue) append(i *item) error {
+	if q.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("unable to encode queue: %w", err)
+	}
+	f, err := os.OpenFile(q.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to write queue: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write queue: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write queue: %w", err)
+	}
+
+	return nil
+}
+
+// save persists the pending updates, replacing the file at once.
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, pending := range q.items {
+		for _, i := range pending {
+			if err := enc.Encode(i); err != nil {
+				return fmt.Errorf("unable to encode queue: %w", err)
+			}
+		}
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("unable to write queue: %w", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("unable to write queue: %w", err)
+	}
+
+	return nil
+}
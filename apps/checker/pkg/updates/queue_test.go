@@ -0,0 +1,252 @@
+package updates_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/openstatushq/openstatus/apps/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/updates"
+	"github.com/stretchr/testify/require"
+)
+
+type updater struct {
+	mu      sync.Mutex
+	err     error
+	updates []checker.UpdateData
+}
+
+func (u *updater) UpdateStatus(ctx context.Context, data checker.UpdateData) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.err != nil {
+		return u.err
+	}
+	u.updates = append(u.updates, data)
+	return nil
+}
+
+func (u *updater) fail(err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.err = err
+}
+
+func (u *updater) delivered() []checker.UpdateData {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]checker.UpdateData(nil), u.updates...)
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("it should retry the failed updates", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := &updater{err: errors.New("unavailable")}
+		q, err := updates.New(u, "", updates.WithBackoff(10*time.Millisecond, 10*time.Millisecond))
+		require.NoError(t, err)
+		go q.Run(ctx)
+
+		require.NoError(t, q.UpdateStatus(ctx, checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, 1, q.Pending())
+
+		u.fail(nil)
+		require.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 10*time.Millisecond)
+		require.Len(t, u.delivered(), 1)
+	})
+
+	t.Run("it should give up after the retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := &updater{err: errors.New("unavailable")}
+		q, err := updates.New(u, "", updates.WithRetries(2), updates.WithBackoff(time.Millisecond, time.Millisecond))
+		require.NoError(t, err)
+		go q.Run(ctx)
+
+		require.NoError(t, q.UpdateStatus(ctx, checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("it should give up the permanent failures at once", func(t *testing.T) {
+		u := &updater{err: backoff.Permanent(errors.New("rejected"))}
+		q, err := updates.New(u, "")
+		require.NoError(t, err)
+
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.NoError(t, q.Flush(context.Background()))
+	})
+
+	t.Run("it should keep the last status of a monitor", func(t *testing.T) {
+		u := &updater{}
+		q, err := updates.New(u, "")
+		require.NoError(t, err)
+
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "active"}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "active", Degraded: true}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "iad", Status: "error"}))
+		require.Equal(t, 2, q.Pending())
+
+		require.NoError(t, q.Flush(context.Background()))
+		require.ElementsMatch(t, []checker.UpdateData{
+			{MonitorId: "1", Region: "ams", Status: "active", Degraded: true},
+			{MonitorId: "1", Region: "iad", Status: "error"},
+		}, u.delivered())
+	})
+
+	t.Run("it should never replace a pending error", func(t *testing.T) {
+		u := &updater{}
+		q, err := updates.New(u, "")
+		require.NoError(t, err)
+
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "active"}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.Equal(t, 2, q.Pending(), "the active status should be replaced")
+
+		require.NoError(t, q.Flush(context.Background()))
+		require.Equal(t, []checker.UpdateData{
+			{MonitorId: "1", Region: "ams", Status: "error"},
+			{MonitorId: "1", Region: "ams", Status: "error"},
+		}, u.delivered(), "the updates should be delivered in order")
+	})
+
+	t.Run("it should deliver the updates of a monitor in order", func(t *testing.T) {
+		u := &updater{err: errors.New("unavailable")}
+		q, err := updates.New(u, "")
+		require.NoError(t, err)
+
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "active"}))
+		require.Error(t, q.Flush(context.Background()))
+		require.Equal(t, 2, q.Pending())
+
+		u.fail(nil)
+		require.NoError(t, q.Flush(context.Background()))
+		require.Equal(t, []checker.UpdateData{
+			{MonitorId: "1", Region: "ams", Status: "error"},
+			{MonitorId: "1", Region: "ams", Status: "active"},
+		}, u.delivered())
+	})
+
+	t.Run("it should bound each delivery", func(t *testing.T) {
+		u := checker.StatusUpdaterFunc(func(ctx context.Context, data checker.UpdateData) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		q, err := updates.New(u, "", updates.WithTimeout(10*time.Millisecond))
+		require.NoError(t, err)
+
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.Error(t, q.Flush(context.Background()))
+		require.Equal(t, 1, q.Pending(), "the update should be retried")
+	})
+
+	t.Run("it should deliver the persisted updates after a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "updates.json")
+
+		u := &updater{err: errors.New("unavailable")}
+		q, err := updates.New(u, path)
+		require.NoError(t, err)
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.Error(t, q.Flush(context.Background()))
+
+		u = &updater{}
+		q, err = updates.New(u, path)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Pending())
+		require.NoError(t, q.Flush(context.Background()))
+		require.Len(t, u.delivered(), 1)
+	})
+
+	t.Run("it should replay the appended updates after a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "updates.json")
+
+		q, err := updates.New(&updater{}, path)
+		require.NoError(t, err)
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "active"}))
+		require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "active", Degraded: true}))
+
+		u := &updater{}
+		q, err = updates.New(u, path)
+		require.NoError(t, err)
+		require.Equal(t, 2, q.Pending(), "the replaced status should not be replayed")
+		require.NoError(t, q.Flush(context.Background()))
+		require.Equal(t, []checker.UpdateData{
+			{MonitorId: "1", Region: "ams", Status: "error"},
+			{MonitorId: "1", Region: "ams", Status: "active", Degraded: true},
+		}, u.delivered())
+	})
+
+	t.Run("it should read the queue of a previous version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "updates.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"data":{"monitorId":"1","status":"error","region":"ams"},"attempts":2}]`), 0o600))
+
+		u := &updater{}
+		q, err := updates.New(u, path)
+		require.NoError(t, err)
+		require.NoError(t, q.Flush(context.Background()))
+		require.Equal(t, []checker.UpdateData{{MonitorId: "1", Region: "ams", Status: "error"}}, u.delivered())
+	})
+}
+
+type batchUpdater struct {
+	updater
+	batches [][]checker.UpdateData
+	err     error
+}
+
+func (u *batchUpdater) UpdateStatuses(ctx context.Context, data []checker.UpdateData) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.batches = append(u.batches, data)
+	return u.err
+}
+
+func TestQueueBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	u := &batchUpdater{}
+	q, err := updates.New(u, "", updates.WithBatch(2, 20*time.Millisecond))
+	require.NoError(t, err)
+	go q.Run(ctx)
+
+	for _, monitorID := range []string{"1", "2", "3"} {
+		require.NoError(t, q.UpdateStatus(ctx, checker.UpdateData{MonitorId: monitorID, Region: "ams", Status: "error"}))
+	}
+	require.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 10*time.Millisecond)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	require.Len(t, u.batches, 1, "the updates should wait for the linger")
+	require.Len(t, u.batches[0], 2)
+	require.Len(t, u.updates, 1, "the last update of a batch should be sent alone")
+}
+
+func TestQueueBatchRejected(t *testing.T) {
+	t.Parallel()
+
+	u := &batchUpdater{err: backoff.Permanent(errors.New("invalid monitor"))}
+	q, err := updates.New(u, "", updates.WithBatch(2, 0))
+	require.NoError(t, err)
+
+	require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "1", Region: "ams", Status: "error"}))
+	require.NoError(t, q.UpdateStatus(context.Background(), checker.UpdateData{MonitorId: "2", Region: "ams", Status: "error"}))
+	require.NoError(t, q.Flush(context.Background()))
+
+	require.Len(t, u.batches, 1)
+	require.Len(t, u.delivered(), 2, "the updates of a rejected batch should be sent one by one")
+}
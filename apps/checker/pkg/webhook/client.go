@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Client interface {
+	SendEvent(ctx context.Context, event any) error
+}
+
+type client struct {
+	httpClient *http.Client
+	url        string
+	headers    map[string]string
+}
+
+func NewClient(httpClient *http.Client, url string, headers map[string]string) Client {
+	return client{
+		httpClient: httpClient,
+		url:        url,
+		headers:    headers,
+	}
+}
+
+func (c client) SendEvent(ctx context.Context, event any) error {
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to encode payload")
+		return fmt.Errorf("unable to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload.Bytes()))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to create request")
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to send request")
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		log.Ctx(ctx).Error().Str("status", resp.Status).Msg("unexpected status code")
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.24.4
+// source: checker/v1/checker.proto
+
+package checkerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CheckerService_RunCheck_FullMethodName      = "/checker.v1.CheckerService/RunCheck"
+	CheckerService_GetHealth_FullMethodName     = "/checker.v1.CheckerService/GetHealth"
+	CheckerService_StreamResults_FullMethodName = "/checker.v1.CheckerService/StreamResults"
+)
+
+// CheckerServiceClient is the client API for CheckerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CheckerServiceClient interface {
+	// RunCheck runs a check and returns its result.
+	RunCheck(ctx context.Context, in *RunCheckRequest, opts ...grpc.CallOption) (*RunCheckResponse, error)
+	// GetHealth returns the health of the checker.
+	GetHealth(ctx context.Context, in *GetHealthRequest, opts ...grpc.CallOption) (*GetHealthResponse, error)
+	// StreamResults streams the results of the checks run by the checker.
+	StreamResults(ctx context.Context, in *StreamResultsRequest, opts ...grpc.CallOption) (CheckerService_StreamResultsClient, error)
+}
+
+type checkerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCheckerServiceClient(cc grpc.ClientConnInterface) CheckerServiceClient {
+	return &checkerServiceClient{cc}
+}
+
+func (c *checkerServiceClient) RunCheck(ctx context.Context, in *RunCheckRequest, opts ...grpc.CallOption) (*RunCheckResponse, error) {
+	out := new(RunCheckResponse)
+	err := c.cc.Invoke(ctx, CheckerService_RunCheck_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *checkerServiceClient) GetHealth(ctx context.Context, in *GetHealthRequest, opts ...grpc.CallOption) (*GetHealthResponse, error) {
+	out := new(GetHealthResponse)
+	err := c.cc.Invoke(ctx, CheckerService_GetHealth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *checkerServiceClient) StreamResults(ctx context.Context, in *StreamResultsRequest, opts ...grpc.CallOption) (CheckerService_StreamResultsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CheckerService_ServiceDesc.Streams[0], CheckerService_StreamResults_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &checkerServiceStreamResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CheckerService_StreamResultsClient interface {
+	Recv() (*CheckResult, error)
+	grpc.ClientStream
+}
+
+type checkerServiceStreamResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *checkerServiceStreamResultsClient) Recv() (*CheckResult, error) {
+	m := new(CheckResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CheckerServiceServer is the server API for CheckerService service.
+// All implementations must embed UnimplementedCheckerServiceServer
+// for forward compatibility
+type CheckerServiceServer interface {
+	// RunCheck runs a check and returns its result.
+	RunCheck(context.Context, *RunCheckRequest) (*RunCheckResponse, error)
+	// GetHealth returns the health of the checker.
+	GetHealth(context.Context, *GetHealthRequest) (*GetHealthResponse, error)
+	// StreamResults streams the results of the checks run by the checker.
+	StreamResults(*StreamResultsRequest, CheckerService_StreamResultsServer) error
+	mustEmbedUnimplementedCheckerServiceServer()
+}
+
+// UnimplementedCheckerServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCheckerServiceServer struct {
+}
+
+func (UnimplementedCheckerServiceServer) RunCheck(context.Context, *RunCheckRequest) (*RunCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunCheck not implemented")
+}
+func (UnimplementedCheckerServiceServer) GetHealth(context.Context, *GetHealthRequest) (*GetHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHealth not implemented")
+}
+func (UnimplementedCheckerServiceServer) StreamResults(*StreamResultsRequest, CheckerService_StreamResultsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamResults not implemented")
+}
+func (UnimplementedCheckerServiceServer) mustEmbedUnimplementedCheckerServiceServer() {}
+
+// UnsafeCheckerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CheckerServiceServer will
+// result in compilation errors.
+type UnsafeCheckerServiceServer interface {
+	mustEmbedUnimplementedCheckerServiceServer()
+}
+
+func RegisterCheckerServiceServer(s grpc.ServiceRegistrar, srv CheckerServiceServer) {
+	s.RegisterService(&CheckerService_ServiceDesc, srv)
+}
+
+func _CheckerService_RunCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CheckerServiceServer).RunCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CheckerService_RunCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CheckerServiceServer).RunCheck(ctx, req.(*RunCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CheckerService_GetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CheckerServiceServer).GetHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CheckerService_GetHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CheckerServiceServer).GetHealth(ctx, req.(*GetHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CheckerService_StreamResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CheckerServiceServer).StreamResults(m, &checkerServiceStreamResultsServer{stream})
+}
+
+type CheckerService_StreamResultsServer interface {
+	Send(*CheckResult) error
+	grpc.ServerStream
+}
+
+type checkerServiceStreamResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *checkerServiceStreamResultsServer) Send(m *CheckResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CheckerService_ServiceDesc is the grpc.ServiceDesc for CheckerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CheckerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "checker.v1.CheckerService",
+	HandlerType: (*CheckerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RunCheck",
+			Handler:    _CheckerService_RunCheck_Handler,
+		},
+		{
+			MethodName: "GetHealth",
+			Handler:    _CheckerService_GetHealth_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamResults",
+			Handler:       _CheckerService_StreamResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "checker/v1/checker.proto",
+}
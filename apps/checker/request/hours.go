@@ -0,0 +1,95 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActiveHours restricts the checks of a monitor to some hours of some days,
+// e.g. the business hours of an internal tool.
+type ActiveHours struct {
+	// Timezone is an IANA timezone, e.g. "Europe/Paris", UTC when empty.
+	Timezone string `json:"timezone,omitempty"`
+	// Days are the days of the week, e.g. "mon", every day when empty.
+	Days []string `json:"days,omitempty"`
+	// From and To, e.g. "09:00" and "18:00", bound the hours of the days.
+	// A window ending before it starts spans midnight.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Outside is how the checks are handled outside the active hours,
+	// "skip" (the default) or "silent" to run them without updating the
+	// status.
+	Outside string `json:"outside,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Contains reports whether t is within the active hours. The night of a
+// window spanning midnight belongs to the day it starts.
+func (a ActiveHours) Contains(t time.Time) (bool, error) {
+	location := time.UTC
+	if a.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(a.Timezone); err != nil {
+			return false, fmt.Errorf("unable to load timezone: %w", err)
+		}
+	}
+
+	from, err := minutes(a.From)
+	if err != nil {
+		return false, err
+	}
+	to, err := minutes(a.To)
+	if err != nil {
+		return false, err
+	}
+
+	t = t.In(location)
+	now := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case from <= to:
+		if now < from || now >= to {
+			return false, nil
+		}
+	case now >= from:
+	case now < to:
+		day = (day + 6) % 7
+	default:
+		return false, nil
+	}
+
+	if len(a.Days) == 0 {
+		return true, nil
+	}
+	for _, name := range a.Days {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return false, fmt.Errorf("invalid day %q", name)
+		}
+		if weekday == day {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// minutes returns the minutes since midnight of a "15:04" time.
+func minutes(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", value, err)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,57 @@
+package request_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveHours(t *testing.T) {
+	t.Parallel()
+
+	business := request.ActiveHours{
+		Timezone: "America/New_York",
+		Days:     []string{"mon", "tue", "wed", "thu", "fri"},
+		From:     "09:00",
+		To:       "17:00",
+	}
+	night := request.ActiveHours{
+		Days: []string{"fri"},
+		From: "22:00",
+		To:   "06:00",
+	}
+
+	tests := []struct {
+		name   string
+		hours  request.ActiveHours
+		t      string
+		active bool
+	}{
+		{"it should be active during the business hours", business, "2023-12-04T15:00:00Z", true},
+		{"it should use the timezone", business, "2023-12-04T13:30:00Z", false},
+		{"it should end at the end of the window", business, "2023-12-04T22:00:00Z", false},
+		{"it should be inactive on the other days", business, "2023-12-03T15:00:00Z", false},
+		{"it should span midnight", night, "2023-12-08T23:00:00Z", true},
+		{"it should keep the night to its first day", night, "2023-12-09T05:00:00Z", true},
+		{"it should not start the night on the other days", night, "2023-12-09T23:00:00Z", false},
+		{"it should be inactive during the day", night, "2023-12-08T12:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.t)
+			require.NoError(t, err)
+
+			active, err := tt.hours.Contains(at)
+			require.NoError(t, err)
+			require.Equal(t, tt.active, active)
+		})
+	}
+
+	t.Run("it should reject an unknown timezone", func(t *testing.T) {
+		_, err := request.ActiveHours{Timezone: "Mars/Olympus", From: "09:00", To: "17:00"}.Contains(time.Now())
+		require.Error(t, err)
+	})
+}
@@ -11,5 +11,83 @@ type CheckerRequest struct {
 		Key   string `json:"key"`
 		Value string `json:"value"`
 	} `json:"headers,omitempty"`
-	Status   string   `json:"status"`
+	Status string `json:"status"`
+	// Retry is the number of the retry check, 0 for the scheduled one.
+	Retry int `json:"retry,omitempty"`
+	// Confirmation is set on the checks confirming the failure seen by
+	// another region, they never update the monitor status.
+	Confirmation bool `json:"confirmation,omitempty"`
+	// ReportOnly checks only return and record their result, the status of
+	// the monitor being left to the caller.
+	ReportOnly bool `json:"reportOnly,omitempty"`
+	// FailureThreshold is the number of consecutive failures flipping the
+	// monitor to error, RecoveryThreshold the number of consecutive
+	// successes recovering it. The checker defaults are used when unset.
+	FailureThreshold  int `json:"failureThreshold,omitempty"`
+	RecoveryThreshold int `json:"recoveryThreshold,omitempty"`
+	// Priority is "high" for the checks triggered by the users, which do
+	// not wait behind the scheduled ones.
+	Priority string `json:"priority,omitempty"`
+	// Regions are the regions the monitor is checked from, any when empty.
+	Regions []string `json:"regions,omitempty"`
+	// DependsOn are the monitors this one depends on: while one of them is
+	// down, the failures of this one are suppressed.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Timeout of the check, in milliseconds, the checker default when
+	// unset or 0.
+	Timeout int64 `json:"timeout,omitempty"`
+	// Interval, e.g. "10s", runs the check several times per cron tick.
+	Interval string `json:"interval,omitempty"`
+	// Assertions are the expectations on the response.
+	Assertions []Assertion `json:"assertions,omitempty"`
+	// DegradedAfter is the latency, in milliseconds, above which a
+	// successful check is degraded, never when unset.
+	DegradedAfter int64 `json:"degradedAfter,omitempty"`
+	// Severity of the monitor, "info", "warning" or "critical", selecting
+	// its thresholds and the channels notified, warning when unset.
+	Severity string `json:"severity,omitempty"`
+	// Tags of the monitor, e.g. "production", matched by the silences of
+	// the notifications.
+	Tags []string `json:"tags,omitempty"`
+	// Notifications are the channels notified of the monitor, instead of
+	// the channels of the checker matching it.
+	Notifications []NotificationChannel `json:"notifications,omitempty"`
+	// ActiveHours restricts the checks to some hours, always checked when
+	// unset.
+	ActiveHours *ActiveHours `json:"activeHours,omitempty"`
+}
+
+// TargetsRegion reports whether the check is intended for the region. The
+// confirmations are always run by the region they were sent to.
+func (r CheckerRequest) TargetsRegion(region string) bool {
+	if len(r.Regions) == 0 || r.Confirmation {
+		return true
+	}
+	for _, target := range r.Regions {
+		if target == region {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotificationChannel is a channel of the checker, by name, or a channel of
+// its own, the provider type and config of the channel.
+type NotificationChannel struct {
+	Name   string            `json:"name,omitempty"`
+	Type   string            `json:"type,omitempty"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// Assertion compares a property of the response, its "status", a "header"
+// named by key, its "body" or its "latency", with the target.
+type Assertion struct {
+	Type    string `json:"type"`
+	Key     string `json:"key,omitempty"`
+	Compare string `json:"compare"`
+	Target  string `json:"target"`
+	// Degraded assertions are also verified by the scheduled checks, a
+	// successful check failing one of them being degraded.
+	Degraded bool `json:"degraded,omitempty"`
 }
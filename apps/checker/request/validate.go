@@ -0,0 +1,176 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// Limits bound the checker requests accepted.
+type Limits struct {
+	// Schemes are the schemes of the URLs allowed.
+	Schemes []string
+	// MaxHeaders is the number of headers, MaxHeaderSize the size of their
+	// keys and values, in bytes.
+	MaxHeaders    int
+	MaxHeaderSize int
+	// MaxBodySize is the size of the body, in bytes.
+	MaxBodySize int
+	// MaxTimeout bounds the timeout of the checks.
+	MaxTimeout time.Duration
+	// Channel validates the type and the config of the notification
+	// channels given by the requests, when set.
+	Channel func(c NotificationChannel) error
+}
+
+// DefaultLimits are the limits of the checkers without their own.
+var DefaultLimits = Limits{
+	Schemes:       []string{"http", "https"},
+	MaxHeaders:    50,
+	MaxHeaderSize: 8 << 10,
+	MaxBodySize:   1 << 20,
+	MaxTimeout:    60 * time.Second,
+}
+
+// FieldError is the error of a field of the request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the fields of an invalid request.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+
+	return "invalid request: " + strings.Join(messages, ", ")
+}
+
+func (e *ValidationError) add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Prefix returns the error with the fields prefixed, e.g. by the index of
+// the request in a batch.
+func (e *ValidationError) Prefix(prefix string) *ValidationError {
+	prefixed := &ValidationError{}
+	for _, f := range e.Fields {
+		prefixed.Fields = append(prefixed.Fields, FieldError{Field: prefix + f.Field, Message: f.Message})
+	}
+
+	return prefixed
+}
+
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// Validate returns a *ValidationError listing the invalid fields of the
+// request within the limits, if any.
+func (r CheckerRequest) Validate(l Limits) error {
+	e := &ValidationError{}
+
+	if r.URL == "" {
+		e.add("url", "is required")
+	} else if u, err := url.Parse(r.URL); err != nil {
+		e.add("url", "is not a valid url")
+	} else if !contains(l.Schemes, strings.ToLower(u.Scheme)) {
+		e.add("url", "scheme %q is not allowed, only %s", u.Scheme, strings.Join(l.Schemes, ", "))
+	} else if u.Hostname() == "" {
+		e.add("url", "host is required")
+	}
+
+	if r.Method != "" && !contains(methods, strings.ToUpper(r.Method)) {
+		e.add("method", "%q is not allowed, only %s", r.Method, strings.Join(methods, ", "))
+	}
+
+	if len(r.Headers) > l.MaxHeaders {
+		e.add("headers", "at most %d headers are allowed", l.MaxHeaders)
+	}
+	size := 0
+	for i, header := range r.Headers {
+		size += len(header.Key) + len(header.Value)
+		if !httpguts.ValidHeaderFieldName(header.Key) {
+			e.add(fmt.Sprintf("headers[%d].key", i), "is not a valid header name")
+		}
+		if !httpguts.ValidHeaderFieldValue(header.Value) {
+			e.add(fmt.Sprintf("headers[%d].value", i), "is not a valid header value")
+		}
+	}
+	if size > l.MaxHeaderSize {
+		e.add("headers", "at most %d bytes of headers are allowed", l.MaxHeaderSize)
+	}
+
+	if len(r.Body) > l.MaxBodySize {
+		e.add("body", "at most %d bytes are allowed", l.MaxBodySize)
+	}
+
+	// Without timeout, the check runs with the default one of the checker.
+	if r.Timeout < 0 || time.Duration(r.Timeout)*time.Millisecond > l.MaxTimeout {
+		e.add("timeout", "must be between 0, for the default, and %d ms", l.MaxTimeout.Milliseconds())
+	}
+
+	for i, c := range r.Notifications {
+		field := fmt.Sprintf("notifications[%d]", i)
+		if c.Type == "" {
+			if c.Name == "" {
+				e.add(field, "name or type is required")
+			}
+			continue
+		}
+		if l.Channel != nil {
+			if err := l.Channel(c); err != nil {
+				e.add(field, "%s", err)
+			}
+		}
+	}
+
+	switch r.Priority {
+	case "", "normal", "high":
+	default:
+		e.add("priority", "must be normal or high")
+	}
+	switch r.Severity {
+	case "", "info", "warning", "critical":
+	default:
+		e.add("severity", "must be info, warning or critical")
+	}
+	if r.Retry < 0 {
+		e.add("retry", "must be positive")
+	}
+	if r.FailureThreshold < 0 {
+		e.add("failureThreshold", "must be positive")
+	}
+	if r.RecoveryThreshold < 0 {
+		e.add("recoveryThreshold", "must be positive")
+	}
+	if r.DegradedAfter < 0 {
+		e.add("degradedAfter", "must be positive")
+	}
+
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
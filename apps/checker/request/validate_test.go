@@ -0,0 +1,97 @@
+package request_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	type header = struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	fields := func(t *testing.T, err error) []string {
+		t.Helper()
+
+		var validation *request.ValidationError
+		require.True(t, errors.As(err, &validation))
+		names := []string{}
+		for _, f := range validation.Fields {
+			names = append(names, f.Field)
+		}
+		return names
+	}
+
+	t.Run("it should accept a valid request", func(t *testing.T) {
+		req := request.CheckerRequest{
+			URL:     "https://www.openstatus.dev",
+			Method:  "post",
+			Body:    "{}",
+			Headers: []header{{Key: "Content-Type", Value: "application/json"}},
+			Timeout: 30000,
+		}
+		require.NoError(t, req.Validate(request.DefaultLimits))
+	})
+
+	t.Run("it should list every invalid field", func(t *testing.T) {
+		req := request.CheckerRequest{
+			URL:      "file:///etc/passwd",
+			Method:   "TRACE",
+			Headers:  []header{{Key: "Bad Header", Value: "value"}, {Key: "X-Ok", Value: "line\nbreak"}},
+			Timeout:  120000,
+			Severity: "fatal",
+		}
+		require.Equal(t, []string{"url", "method", "headers[0].key", "headers[1].value", "timeout", "severity"}, fields(t, req.Validate(request.DefaultLimits)))
+	})
+
+	t.Run("it should require a url with a host", func(t *testing.T) {
+		require.Equal(t, []string{"url"}, fields(t, request.CheckerRequest{}.Validate(request.DefaultLimits)))
+		require.Equal(t, []string{"url"}, fields(t, request.CheckerRequest{URL: "https://"}.Validate(request.DefaultLimits)))
+	})
+
+	t.Run("it should enforce the limits", func(t *testing.T) {
+		limits := request.DefaultLimits
+		limits.MaxHeaders, limits.MaxHeaderSize, limits.MaxBodySize = 1, 10, 4
+
+		req := request.CheckerRequest{
+			URL:     "https://www.openstatus.dev",
+			Body:    "too large",
+			Headers: []header{{Key: "X-One", Value: "1"}, {Key: "X-Two", Value: strings.Repeat("2", 10)}},
+		}
+		require.Equal(t, []string{"headers", "headers", "body"}, fields(t, req.Validate(limits)))
+	})
+
+	t.Run("it should validate the notification channels", func(t *testing.T) {
+		limits := request.DefaultLimits
+		limits.Channel = func(c request.NotificationChannel) error {
+			if c.Type != "webhook" {
+				return errors.New("unknown provider")
+			}
+			return nil
+		}
+
+		req := request.CheckerRequest{
+			URL: "https://www.openstatus.dev",
+			Notifications: []request.NotificationChannel{
+				{Name: "ops"},
+				{Type: "webhook", Config: map[string]string{"url": "https://example.com"}},
+				{Type: "fax"},
+				{},
+			},
+		}
+		require.Equal(t, []string{"notifications[2]", "notifications[3]"}, fields(t, req.Validate(limits)))
+	})
+
+	t.Run("it should prefix the fields", func(t *testing.T) {
+		var validation *request.ValidationError
+		require.True(t, errors.As(request.CheckerRequest{}.Validate(request.DefaultLimits), &validation))
+		require.Equal(t, "[2].url", validation.Prefix("[2].").Fields[0].Field)
+	})
+}
@@ -0,0 +1,577 @@
+package checker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/flap"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/notify"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/pause"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sink"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	checksRun, _ = telemetry.Meter().Int64Counter("checker.checks",
+		metric.WithDescription("Checks run, by region and status."),
+	)
+	checkRetries, _ = telemetry.Meter().Int64Counter("checker.checks.retries",
+		metric.WithDescription("Retries of the failed pings."),
+	)
+)
+
+type statusCode int
+
+func (s statusCode) IsSuccessful() bool {
+	return s >= 200 && s < 300
+}
+
+// Confirmer confirms a failure seen by the checker before the monitor is
+// flipped to error.
+type Confirmer interface {
+	Confirm(ctx context.Context, req request.CheckerRequest) bool
+}
+
+// Maintenance tells whether a monitor is in maintenance.
+type Maintenance interface {
+	Active(req request.CheckerRequest, t time.Time) bool
+}
+
+// Limiter tells whether a check is within the rate limit of its workspace.
+type Limiter interface {
+	Allow(ctx context.Context, req request.CheckerRequest) bool
+}
+
+// Runner runs the checks: it pings the monitor, updates its status and sends
+// the result to the sink.
+type Runner struct {
+	httpClient   *http.Client
+	sink         sink.Sink
+	region       string
+	confirmer    Confirmer
+	maintenance  Maintenance
+	pauses       *pause.Registry
+	limiter      Limiter
+	notifier     notify.Notifier
+	detector     *flap.Detector
+	failures     int
+	recoveries   int
+	severities   map[string]thresholds
+	certificates *certificates
+	policy       Policy
+	audit        *audit.Log
+	updater      StatusUpdater
+	reportOnly   bool
+	timeout      time.Duration
+	limits       *request.Limits
+}
+
+// thresholds are the numbers of consecutive failures flipping a monitor to
+// error, and of consecutive successes recovering it.
+type thresholds struct {
+	failures   int
+	recoveries int
+}
+
+type RunnerOption func(*Runner)
+
+// WithConfirmer requires the failures to be confirmed before updating the
+// monitor status.
+func WithConfirmer(confirmer Confirmer) RunnerOption {
+	return func(r *Runner) {
+		r.confirmer = confirmer
+	}
+}
+
+// WithMaintenance records the checks run during a maintenance window as
+// such, without updating the status of the monitors.
+func WithMaintenance(maintenance Maintenance) RunnerOption {
+	return func(r *Runner) {
+		r.maintenance = maintenance
+	}
+}
+
+// WithPauses skips, or runs without updating the status, the checks of the
+// monitors paused in the registry.
+func WithPauses(pauses *pause.Registry) RunnerOption {
+	return func(r *Runner) {
+		r.pauses = pauses
+	}
+}
+
+// WithLimiter skips the checks over the rate limit of their workspace.
+func WithLimiter(limiter Limiter) RunnerOption {
+	return func(r *Runner) {
+		r.limiter = limiter
+	}
+}
+
+// WithStatusUpdater updates the status of the monitors with the updater,
+// instead of the API.
+func WithStatusUpdater(updater StatusUpdater) RunnerOption {
+	return func(r *Runner) {
+		r.updater = updater
+	}
+}
+
+// WithReportOnly only returns and records the results of the checks, never
+// updating the status of the monitors nor notifying their transitions: the
+// control plane decides of the status from the results.
+func WithReportOnly() RunnerOption {
+	return func(r *Runner) {
+		r.reportOnly = true
+	}
+}
+
+// WithPolicy restricts the targets the workspaces may check.
+func WithPolicy(policy Policy) RunnerOption {
+	return func(r *Runner) {
+		r.policy = policy
+	}
+}
+
+// WithAudit records the checks and the status transitions, along with who
+// triggered them, to the audit log.
+func WithAudit(log *audit.Log) RunnerOption {
+	return func(r *Runner) {
+		r.audit = log
+	}
+}
+
+// WithNotifier notifies the status transitions of the monitors.
+func WithNotifier(notifier notify.Notifier) RunnerOption {
+	return func(r *Runner) {
+		r.notifier = notifier
+	}
+}
+
+// WithDefaultTimeout bounds the checks without timeout of their own.
+func WithDefaultTimeout(timeout time.Duration) RunnerOption {
+	return func(r *Runner) {
+		r.timeout = timeout
+	}
+}
+
+// WithLimits never runs the checks whose request is invalid within the limits,
+// whatever submitted them: the http endpoints, gRPC, the schedulers or the
+// queue.
+func WithLimits(limits request.Limits) RunnerOption {
+	return func(r *Runner) {
+		r.limits = &limits
+	}
+}
+
+// WithThresholds sets the default number of consecutive failures flipping a
+// monitor to error, and of consecutive successes recovering it. Both default
+// to 1, the requests can override them per monitor.
+func WithThresholds(failures, recoveries int) RunnerOption {
+	return func(r *Runner) {
+		r.failures = failures
+		r.recoveries = recoveries
+	}
+}
+
+// WithSeverityThresholds sets the thresholds of the monitors of the severity,
+// e.g. flipping the critical monitors at the first failure and the info ones
+// after a few. The requests can still override them per monitor.
+func WithSeverityThresholds(severity string, failures, recoveries int) RunnerOption {
+	return func(r *Runner) {
+		if r.severities == nil {
+			r.severities = map[string]thresholds{}
+		}
+		r.severities[severity] = thresholds{failures: failures, recoveries: recoveries}
+	}
+}
+
+func NewRunner(httpClient *http.Client, eventSink sink.Sink, region string, opts ...RunnerOption) Runner {
+	r := Runner{
+		httpClient: httpClient,
+		sink:       eventSink,
+		region:     region,
+		detector:   flap.NewDetector(),
+		failures:   1,
+		recoveries: 1,
+		updater:    StatusUpdaterFunc(UpdateStatus),
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return r
+}
+
+// statuses are the statuses of the monitors, from the best to the worst.
+var statuses = []string{"active", "degraded", "error"}
+
+// level returns the level of the status in statuses, active when unknown.
+func level(status string) int {
+	for i, s := range statuses {
+		if s == status {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// transition records the result of the check and updates the status of the
+// monitor once enough consecutive checks agree on a new status. A monitor
+// getting worse moves to the worst status reached by failure threshold
+// checks in a row, e.g. degraded after an error and a degraded check, and a
+// monitor getting better to the best one reached by recovery threshold
+// checks in a row.
+func (r Runner) transition(ctx context.Context, req request.CheckerRequest, data UpdateData, latency int64) {
+	if req.Confirmation {
+		return
+	}
+
+	failures, recoveries := r.failures, r.recoveries
+	if t, ok := r.severities[req.Severity]; ok {
+		failures, recoveries = t.failures, t.recoveries
+	}
+	if req.FailureThreshold > 0 {
+		failures = req.FailureThreshold
+	}
+	if req.RecoveryThreshold > 0 {
+		recoveries = req.RecoveryThreshold
+	}
+
+	// Without the status of the monitor in the request, e.g. in standalone
+	// mode, the last transition of the checker is used, as for a degraded
+	// monitor, active for the main app. A monitor of an unknown status is
+	// taken as active.
+	current := req.Status
+	if last := r.detector.Status(req.MonitorID); current == "" || current == "active" && last == "degraded" {
+		current = last
+	}
+
+	from, to := level(current), level(data.Status)
+	streaks := r.detector.ObserveLevel(req.MonitorID, to, len(statuses))
+	next := -1
+	switch {
+	case to > from:
+		for l := to; l > from && next < 0; l-- {
+			if streaks.AtLeast(l) >= failures {
+				next = l
+			}
+		}
+	case to < from:
+		for l := to; l < from && next < 0; l++ {
+			if streaks.AtMost(l) >= recoveries {
+				next = l
+			}
+		}
+	}
+	if next < 0 {
+		return
+	}
+	data.Status = statuses[next]
+	if data.Status == "error" && !r.confirm(ctx, req) {
+		return
+	}
+
+	update := data
+	if update.Status == "degraded" {
+		update.Status, update.Degraded = "active", true
+	}
+	r.update(ctx, update)
+	r.record(ctx, req, audit.StatusChanged, data.Status, current)
+	r.detector.SetStatus(req.MonitorID, data.Status)
+	r.notify(ctx, req, data, current, latency)
+}
+
+// eventID returns a new ID of a result.
+func eventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// update updates the status of the monitor, logging the failures.
+func (r Runner) update(ctx context.Context, data UpdateData) {
+	if err := r.updater.UpdateStatus(ctx, data); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("monitor", data.MonitorId).Msg("error while updating status")
+	}
+}
+
+// record records the action on the monitor to the audit log, if any.
+func (r Runner) record(ctx context.Context, req request.CheckerRequest, action, status, previous string) {
+	if r.audit == nil {
+		return
+	}
+
+	r.audit.Record(ctx, audit.Event{
+		Action:      action,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Region:      r.region,
+		Status:      status,
+		Previous:    previous,
+	})
+}
+
+// notify notifies the transition of the monitor to the notifier, if any.
+func (r Runner) notify(ctx context.Context, req request.CheckerRequest, data UpdateData, previous string, latency int64) {
+	if r.notifier == nil {
+		return
+	}
+
+	// A stuck provider never holds the check.
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	err := r.notifier.Notify(ctx, notify.Notification{
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		URL:         req.URL,
+		Region:      data.Region,
+		Status:      data.Status,
+		Previous:    previous,
+		Severity:    req.Severity,
+		Tags:        req.Tags,
+		Channels:    channels(req.Notifications),
+		StatusCode:  data.StatusCode,
+		Latency:     latency,
+		Message:     data.Message,
+		Timestamp:   time.Now().UTC().UnixMilli(),
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("monitor", req.MonitorID).Msg("failed to notify the transition")
+	}
+}
+
+// notifyTimeout bounds the notification of a transition, to all its
+// channels.
+const notifyTimeout = 30 * time.Second
+
+// channels returns the notification channels of the request, if any.
+func channels(notifications []request.NotificationChannel) []notify.Channel {
+	var channels []notify.Channel
+	for _, c := range notifications {
+		channels = append(channels, notify.Channel{Name: c.Name, Type: c.Type, Config: c.Config})
+	}
+
+	return channels
+}
+
+// dependencyDown reports whether a monitor the monitor of the request depends
+// on is down, as last seen by the checker.
+func (r Runner) dependencyDown(req request.CheckerRequest) bool {
+	for _, monitorID := range req.DependsOn {
+		if r.detector.Status(monitorID) == "error" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// confirm confirms the failure with the confirmer, if any.
+func (r Runner) confirm(ctx context.Context, req request.CheckerRequest) bool {
+	if r.confirmer != nil && !r.confirmer.Confirm(ctx, req) {
+		log.Ctx(ctx).Info().Str("monitor", req.MonitorID).Msg("failure not confirmed by the other regions")
+		return false
+	}
+
+	return true
+}
+
+// Run runs the check, retrying failed pings. When every attempt failed, the
+// returned result carries the error message.
+func (r Runner) Run(ctx context.Context, req request.CheckerRequest) PingData {
+	var result PingData
+
+	// A safety net against the misrouted checks, which are never run.
+	if !req.TargetsRegion(r.region) {
+		log.Ctx(ctx).Warn().Str("monitor", req.MonitorID).Strs("regions", req.Regions).Msg("check not intended for this region, skipping it")
+		return PingData{
+			URL:           req.URL,
+			Region:        r.region,
+			CronTimestamp: req.CronTimestamp,
+			Timestamp:     req.CronTimestamp,
+			MonitorID:     req.MonitorID,
+			WorkspaceID:   req.WorkspaceID,
+			Message:       fmt.Sprintf("check not intended for region %s", r.region),
+			Misrouted:     true,
+		}
+	}
+	if r.limits != nil {
+		if err := req.Validate(*r.limits); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("monitor", req.MonitorID).Msg("invalid check request, skipping it")
+			return PingData{
+				URL:           req.URL,
+				Region:        r.region,
+				CronTimestamp: req.CronTimestamp,
+				Timestamp:     req.CronTimestamp,
+				MonitorID:     req.MonitorID,
+				WorkspaceID:   req.WorkspaceID,
+				Message:       err.Error(),
+				Invalid:       true,
+			}
+		}
+	}
+
+	if req.Timeout == 0 {
+		req.Timeout = r.timeout.Milliseconds()
+	}
+
+	r.record(ctx, req, audit.CheckRun, "", "")
+
+	var paused bool
+	if r.pauses != nil {
+		mode, ok := r.pauses.Get(req.MonitorID)
+		if ok && mode == pause.Skip {
+			log.Ctx(ctx).Debug().Str("monitor", req.MonitorID).Msg("monitor paused, skipping the check")
+			return PingData{
+				URL:           req.URL,
+				Region:        r.region,
+				CronTimestamp: req.CronTimestamp,
+				Timestamp:     req.CronTimestamp,
+				MonitorID:     req.MonitorID,
+				WorkspaceID:   req.WorkspaceID,
+				Paused:        true,
+			}
+		}
+		paused = ok
+	}
+
+	// Outside its active hours, a monitor is skipped or checked silently.
+	// A misconfigured window is ignored rather than silencing the monitor.
+	var inactive bool
+	if req.ActiveHours != nil {
+		active, err := req.ActiveHours.Contains(time.Now())
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("monitor", req.MonitorID).Msg("invalid active hours, ignoring them")
+		} else if !active && req.ActiveHours.Outside == "silent" {
+			inactive = true
+		} else if !active {
+			log.Ctx(ctx).Debug().Str("monitor", req.MonitorID).Msg("outside the active hours, skipping the check")
+			return PingData{
+				URL:           req.URL,
+				Region:        r.region,
+				CronTimestamp: req.CronTimestamp,
+				Timestamp:     req.CronTimestamp,
+				MonitorID:     req.MonitorID,
+				WorkspaceID:   req.WorkspaceID,
+				Inactive:      true,
+			}
+		}
+	}
+
+	if r.limiter != nil && !r.limiter.Allow(ctx, req) {
+		log.Ctx(ctx).Warn().Str("workspace", req.WorkspaceID).Str("monitor", req.MonitorID).Msg("workspace rate limit exceeded, skipping the check")
+		return PingData{
+			URL:           req.URL,
+			Region:        r.region,
+			CronTimestamp: req.CronTimestamp,
+			Timestamp:     req.CronTimestamp,
+			MonitorID:     req.MonitorID,
+			WorkspaceID:   req.WorkspaceID,
+			Throttled:     true,
+		}
+	}
+
+	inMaintenance := r.maintenance != nil && r.maintenance.Active(req, time.Now())
+	suppressed := r.dependencyDown(req)
+	transition := func(data UpdateData, latency int64) {
+		if inMaintenance || paused || inactive || r.reportOnly || req.ReportOnly {
+			return
+		}
+		if data.Status == "error" && suppressed {
+			log.Ctx(ctx).Info().Str("monitor", req.MonitorID).Msg("dependency down, suppressing the failure")
+			return
+		}
+		r.transition(ctx, req, data, latency)
+	}
+
+	op := func() error {
+		res, err := Ping(ctx, r.httpClient, r.policy, req)
+		if errors.Is(err, ssrf.ErrBlocked) || errors.Is(err, ErrForbidden) {
+			// The target of the monitor will not change by retrying.
+			return backoff.Permanent(fmt.Errorf("unable to ping: %w", err))
+		}
+		if err != nil {
+			return fmt.Errorf("unable to ping: %w", err)
+		}
+
+		status := "active"
+		if !statusCode(res.StatusCode).IsSuccessful() {
+			status = "error"
+		} else if res.Degraded {
+			status = "degraded"
+		}
+		if !inMaintenance && !paused && !inactive {
+			r.certificate(ctx, req, res)
+		}
+
+		res.Maintenance = inMaintenance
+		res.Paused = paused
+		res.Inactive = inactive
+		res.Suppressed = suppressed && status == "error"
+		res.EventID = eventID()
+		if err := r.sink.SendEvent(ctx, res); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to send event")
+		}
+		transition(UpdateData{
+			MonitorId:  req.MonitorID,
+			Status:     status,
+			Message:    res.Message,
+			StatusCode: res.StatusCode,
+			Region:     r.region,
+			Latency:    res.Latency,
+			Timing:     res.timing,
+			Assertions: res.failed,
+			EventID:    res.EventID,
+		}, res.Latency)
+
+		checksRun.Add(ctx, 1, metric.WithAttributes(attribute.String("region", r.region), attribute.String("status", status)))
+		result = res
+		return nil
+	}
+
+	retried := func(error, time.Duration) {
+		checkRetries.Add(ctx, 1, metric.WithAttributes(attribute.String("region", r.region)))
+	}
+	if err := backoff.RetryNotify(op, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3), retried); err != nil {
+		checksRun.Add(ctx, 1, metric.WithAttributes(attribute.String("region", r.region), attribute.String("status", "error")))
+		result = PingData{
+			URL:           req.URL,
+			Region:        r.region,
+			Message:       err.Error(),
+			CronTimestamp: req.CronTimestamp,
+			Timestamp:     req.CronTimestamp,
+			MonitorID:     req.MonitorID,
+			WorkspaceID:   req.WorkspaceID,
+			Maintenance:   inMaintenance,
+			Paused:        paused,
+			Inactive:      inactive,
+			Suppressed:    suppressed,
+			EventID:       eventID(),
+		}
+		if err := r.sink.SendEvent(ctx, result); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to send event")
+		}
+
+		transition(UpdateData{
+			MonitorId: req.MonitorID,
+			Status:    "error",
+			Message:   err.Error(),
+			Region:    r.region,
+			EventID:   result.EventID,
+		}, 0)
+	}
+
+	return result
+}
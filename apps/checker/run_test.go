@@ -0,0 +1,482 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/policy"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (r *recorder) SendEvent(ctx context.Context, event any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestRunDependencies(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := &recorder{}
+	runner := NewRunner(server.Client(), sink, "ams")
+	runner.detector.SetStatus("parent", "error")
+
+	result := runner.Run(context.Background(), request.CheckerRequest{
+		MonitorID: "child",
+		URL:       server.URL,
+		Status:    "active",
+		DependsOn: []string{"parent"},
+	})
+
+	require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	require.True(t, result.Suppressed, "the failure should be suppressed while the dependency is down")
+	require.Empty(t, runner.detector.Status("child"), "the status should not be updated")
+	require.Len(t, sink.events, 1)
+}
+
+func TestRunWrongRegion(t *testing.T) {
+	t.Parallel()
+
+	sink := &recorder{}
+	runner := NewRunner(http.DefaultClient, sink, "ams")
+
+	result := runner.Run(context.Background(), request.CheckerRequest{
+		MonitorID: "1",
+		URL:       "http://127.0.0.1:0",
+		Regions:   []string{"iad", "gru"},
+	})
+
+	require.Equal(t, "check not intended for region ams", result.Message)
+	require.True(t, result.Misrouted)
+	require.Empty(t, sink.events, "the misrouted checks should not run")
+}
+
+func TestRunInvalid(t *testing.T) {
+	t.Parallel()
+
+	sink := &recorder{}
+	runner := NewRunner(http.DefaultClient, sink, "ams", WithLimits(request.DefaultLimits))
+
+	result := runner.Run(context.Background(), request.CheckerRequest{
+		MonitorID: "1",
+		URL:       "ftp://127.0.0.1:0",
+	})
+
+	require.True(t, result.Invalid)
+	require.NotEmpty(t, result.Message)
+	require.Empty(t, sink.events, "the invalid checks should not run")
+}
+
+func TestRunActiveHours(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// An empty window, from midnight to midnight, is never active.
+	never := request.ActiveHours{From: "00:00", To: "00:00"}
+
+	t.Run("it should skip the checks outside the active hours", func(t *testing.T) {
+		sink := &recorder{}
+		runner := NewRunner(server.Client(), sink, "ams")
+
+		result := runner.Run(context.Background(), request.CheckerRequest{
+			MonitorID:   "1",
+			URL:         server.URL,
+			ActiveHours: &never,
+		})
+
+		require.True(t, result.Inactive)
+		require.Zero(t, result.StatusCode)
+		require.Empty(t, sink.events)
+	})
+
+	t.Run("it should run silent checks without updating the status", func(t *testing.T) {
+		sink := &recorder{}
+		runner := NewRunner(server.Client(), sink, "ams")
+		silent := never
+		silent.Outside = "silent"
+
+		result := runner.Run(context.Background(), request.CheckerRequest{
+			MonitorID:   "1",
+			URL:         server.URL,
+			Status:      "active",
+			ActiveHours: &silent,
+		})
+
+		require.True(t, result.Inactive)
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.Empty(t, runner.detector.Status("1"), "the status should not be updated")
+		require.Len(t, sink.events, 1)
+	})
+}
+
+func TestRunSeverityThresholds(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	runner := NewRunner(server.Client(), &recorder{}, "ams", WithSeverityThresholds("info", 3, 1))
+	req := request.CheckerRequest{MonitorID: "1", URL: server.URL, Status: "active", Severity: "info"}
+
+	for i := 0; i < 2; i++ {
+		runner.Run(context.Background(), req)
+	}
+	require.Empty(t, runner.detector.Status("1"), "the info monitor should not flip before its threshold")
+}
+
+type countingTransport struct {
+	next  http.RoundTripper
+	count atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.count.Add(1)
+	return t.next.RoundTrip(r)
+}
+
+// waitingTransport waits before the request, as for the limits of a host.
+type waitingTransport struct {
+	next http.RoundTripper
+	wait time.Duration
+}
+
+func (t waitingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	time.Sleep(t.wait)
+	return t.next.RoundTrip(r)
+}
+
+func TestPingTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: waitingTransport{next: http.DefaultTransport, wait: 100 * time.Millisecond}}
+
+	t.Run("it should not count the wait for the limits of the client", func(t *testing.T) {
+		data, err := Ping(context.Background(), client, nil, request.CheckerRequest{URL: server.URL, Method: http.MethodGet, Timeout: 50})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, data.StatusCode)
+		require.Less(t, data.Latency, int64(50))
+	})
+
+	t.Run("it should time out once the request started", func(t *testing.T) {
+		data, err := Ping(context.Background(), client, nil, request.CheckerRequest{URL: server.URL + "/slow", Method: http.MethodGet, Timeout: 50})
+		require.NoError(t, err)
+		require.Contains(t, data.Message, "Timeout after")
+		require.Less(t, data.Latency, int64(200))
+	})
+}
+
+func TestRunDefaultTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	runner := NewRunner(server.Client(), &recorder{}, "ams", WithDefaultTimeout(50*time.Millisecond))
+
+	t.Run("it should time out the checks without timeout", func(t *testing.T) {
+		data := runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Method: http.MethodGet})
+		require.Contains(t, data.Message, "Timeout after")
+	})
+
+	t.Run("it should keep the timeout of the checks", func(t *testing.T) {
+		data := runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Method: http.MethodGet, Timeout: 1000})
+		require.Empty(t, data.Message)
+		require.Equal(t, http.StatusOK, data.StatusCode)
+	})
+}
+
+func TestRunBlocked(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	guard, err := ssrf.New(nil)
+	require.NoError(t, err)
+	transport := &countingTransport{next: guard.Transport()}
+	runner := NewRunner(&http.Client{Transport: transport}, &recorder{}, "ams")
+
+	data := runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Method: http.MethodGet})
+	require.Contains(t, data.Message, "address not allowed")
+	require.Equal(t, int32(1), transport.count.Load(), "a blocked address should not be retried")
+}
+
+func TestRunPolicy(t *testing.T) {
+	t.Parallel()
+
+	var (
+		hits     atomic.Int32
+		redirect string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, redirect, http.StatusFound)
+		}
+	}))
+	defer server.Close()
+	redirect = strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	engine := policy.New(nil)
+	require.NoError(t, engine.Set([]policy.Rule{
+		{WorkspaceID: "1", Action: "deny", Hosts: []string{"127.0.0.1"}},
+		{WorkspaceID: "2", Action: "deny", Hosts: []string{"localhost"}},
+	}))
+	runner := NewRunner(server.Client(), &recorder{}, "ams", WithPolicy(engine))
+
+	t.Run("it should not check a denied target", func(t *testing.T) {
+		data := runner.Run(context.Background(), request.CheckerRequest{WorkspaceID: "1", MonitorID: "1", URL: server.URL, Method: http.MethodGet})
+		require.Contains(t, data.Message, "target forbidden")
+		require.Equal(t, int32(0), hits.Load())
+	})
+
+	t.Run("it should not follow a redirect to a denied target", func(t *testing.T) {
+		data := runner.Run(context.Background(), request.CheckerRequest{WorkspaceID: "2", MonitorID: "2", URL: server.URL + "/redirect", Method: http.MethodGet})
+		require.Contains(t, data.Message, "target forbidden")
+	})
+
+	t.Run("it should check the targets of the other workspaces", func(t *testing.T) {
+		data := runner.Run(context.Background(), request.CheckerRequest{WorkspaceID: "3", MonitorID: "3", URL: server.URL, Method: http.MethodGet})
+		require.Equal(t, http.StatusOK, data.StatusCode)
+	})
+
+	t.Run("it should not connect to a denied address", func(t *testing.T) {
+		// The host resolves to a public address when checked, and to the
+		// loopback when dialed.
+		engine := policy.New(resolver{"localhost": {netip.MustParseAddr("93.184.216.34")}})
+		require.NoError(t, engine.Set([]policy.Rule{{WorkspaceID: "4", Action: "deny", CIDRs: []string{"127.0.0.0/8", "::1/128"}}}))
+		runner := NewRunner(&http.Client{Transport: Transport(nil)}, &recorder{}, "ams", WithPolicy(engine))
+
+		hits.Store(0)
+		data := runner.Run(context.Background(), request.CheckerRequest{WorkspaceID: "4", MonitorID: "4", URL: redirect, Method: http.MethodGet})
+		require.Contains(t, data.Message, "target forbidden")
+		require.Equal(t, int32(0), hits.Load())
+	})
+
+	t.Run("it should fail the check of an unresolved target", func(t *testing.T) {
+		engine := policy.New(resolver{})
+		require.NoError(t, engine.Set([]policy.Rule{{WorkspaceID: "5", Action: "deny", CIDRs: []string{"10.0.0.0/8"}}}))
+		runner := NewRunner(server.Client(), &recorder{}, "ams", WithPolicy(engine))
+
+		data := runner.Run(context.Background(), request.CheckerRequest{WorkspaceID: "5", MonitorID: "5", URL: redirect, Method: http.MethodGet})
+		require.NotContains(t, data.Message, "target forbidden")
+		require.Contains(t, data.Message, "unable to resolve")
+	})
+}
+
+type resolver map[string][]netip.Addr
+
+func (r resolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestRunAudit(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	log := &recorder{}
+	runner := NewRunner(server.Client(), &recorder{}, "ams", WithAudit(audit.New(log)))
+
+	ctx := audit.WithActor(context.Background(), audit.Actor{Source: "http", RequestID: "req-1"})
+	ctx = auth.WithPrincipal(ctx, "cron-secret")
+	runner.Run(ctx, request.CheckerRequest{WorkspaceID: "1", MonitorID: "2", URL: server.URL, Status: "active"})
+
+	require.Len(t, log.events, 2)
+	run := log.events[0].(audit.Event)
+	require.Equal(t, audit.CheckRun, run.Action)
+	require.Equal(t, audit.Actor{Source: "http", Principal: "cron-secret", RequestID: "req-1"}, run.Actor)
+	require.Equal(t, "2", run.MonitorID)
+
+	changed := log.events[1].(audit.Event)
+	require.Equal(t, audit.StatusChanged, changed.Action)
+	require.Equal(t, "error", changed.Status)
+	require.Equal(t, "active", changed.Previous)
+	require.Equal(t, "cron-secret", changed.Principal)
+}
+
+func TestRunStatusUpdater(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var updates []UpdateData
+	updater := StatusUpdaterFunc(func(ctx context.Context, data UpdateData) error {
+		updates = append(updates, data)
+		return nil
+	})
+	runner := NewRunner(server.Client(), &recorder{}, "ams", WithStatusUpdater(updater))
+	runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Status: "active"})
+
+	require.Len(t, updates, 1)
+	require.Equal(t, "1", updates[0].MonitorId)
+	require.Equal(t, "error", updates[0].Status)
+	require.Equal(t, "ams", updates[0].Region)
+}
+
+func TestRunDegraded(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	var updates []UpdateData
+	updater := StatusUpdaterFunc(func(ctx context.Context, data UpdateData) error {
+		updates = append(updates, data)
+		return nil
+	})
+	runner := NewRunner(server.Client(), &recorder{}, "ams", WithStatusUpdater(updater))
+
+	t.Run("it should degrade the slow checks", func(t *testing.T) {
+		res := runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Status: "active", DegradedAfter: 5})
+		require.True(t, res.Degraded)
+		require.Len(t, updates, 1)
+		require.Equal(t, "active", updates[0].Status, "the main app should only know the active and error statuses")
+		require.True(t, updates[0].Degraded)
+		require.Contains(t, updates[0].Message, "Latency")
+		require.Equal(t, res.Latency, updates[0].Latency)
+		require.NotNil(t, updates[0].Timing)
+		require.NotEmpty(t, updates[0].EventID)
+		require.Equal(t, res.EventID, updates[0].EventID, "the update should refer to the result sent to the sinks")
+	})
+
+	t.Run("it should degrade the checks failing a degraded assertion", func(t *testing.T) {
+		res := runner.Run(context.Background(), request.CheckerRequest{MonitorID: "2", URL: server.URL, Status: "active", Assertions: []request.Assertion{
+			{Type: "body", Compare: "contains", Target: `"ok"`, Degraded: true},
+			{Type: "header", Key: "X-Version", Compare: "eq", Target: "2", Degraded: true},
+		}})
+		require.True(t, res.Degraded)
+		require.Equal(t, `Assertion failed: header X-Version eq "2", got ""`, res.Message)
+		require.Len(t, updates, 2)
+		require.Len(t, updates[1].Assertions, 1)
+		require.Equal(t, "X-Version", updates[1].Assertions[0].Key)
+	})
+}
+
+func TestTransitionDegraded(t *testing.T) {
+	t.Parallel()
+
+	var statuses []string
+	updater := StatusUpdaterFunc(func(ctx context.Context, data UpdateData) error {
+		if data.Degraded {
+			data.Status = "degraded"
+		}
+		statuses = append(statuses, data.Status)
+		return nil
+	})
+	runner := NewRunner(http.DefaultClient, &recorder{}, "ams", WithThresholds(2, 2), WithStatusUpdater(updater))
+	req := request.CheckerRequest{MonitorID: "1"}
+	observe := func(status string) {
+		runner.transition(context.Background(), req, UpdateData{MonitorId: "1", Status: status, Region: "ams"}, 0)
+	}
+
+	observe("error")
+	observe("degraded")
+	require.Equal(t, []string{"degraded"}, statuses, "an error and a degraded check should degrade the monitor")
+
+	observe("error")
+	observe("error")
+	require.Equal(t, []string{"degraded", "error"}, statuses)
+
+	observe("degraded")
+	observe("active")
+	require.Equal(t, []string{"degraded", "error", "degraded"}, statuses, "a degraded and an active check should only recover to degraded")
+
+	observe("active")
+	require.Equal(t, []string{"degraded", "error", "degraded", "active"}, statuses)
+
+	// The main app reports a degraded monitor as active.
+	req.Status = "active"
+	observe("degraded")
+	observe("degraded")
+	observe("degraded")
+	require.Equal(t, []string{"degraded", "error", "degraded", "active", "degraded"}, statuses, "a degraded monitor should not be degraded again")
+
+	observe("active")
+	observe("active")
+	require.Equal(t, []string{"degraded", "error", "degraded", "active", "degraded", "active"}, statuses)
+}
+
+func TestRunReportOnly(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var updates atomic.Int32
+	updater := StatusUpdaterFunc(func(ctx context.Context, data UpdateData) error {
+		updates.Add(1)
+		return nil
+	})
+
+	t.Run("it should not update the status of the report only requests", func(t *testing.T) {
+		sink := &recorder{}
+		runner := NewRunner(server.Client(), sink, "ams", WithStatusUpdater(updater))
+		res := runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Status: "active", ReportOnly: true})
+
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		require.Len(t, sink.events, 1)
+		require.Zero(t, updates.Load())
+	})
+
+	t.Run("it should not update the status in report only mode", func(t *testing.T) {
+		runner := NewRunner(server.Client(), &recorder{}, "ams", WithStatusUpdater(updater), WithReportOnly())
+		runner.Run(context.Background(), request.CheckerRequest{MonitorID: "1", URL: server.URL, Status: "active"})
+
+		require.Zero(t, updates.Load())
+		require.Empty(t, runner.detector.Status("1"))
+	})
+}
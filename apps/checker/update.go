@@ -4,33 +4,130 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertion"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/telemetry"
+)
+
+// APIURL is the base url of the API of OpenStatus, and UpdateStatusURL its
+// endpoint updating the status of a monitor.
+const (
+	APIURL          = "https://openstatus-api.fly.dev"
+	UpdateStatusURL = APIURL + "/updateStatus"
 )
 
 type UpdateData struct {
-	MonitorId  string `json:"monitorId"`
+	MonitorId string `json:"monitorId"`
+	// Status is the new status of the monitor, "active" or "error", and
+	// Degraded whether an active monitor is degraded, e.g. slow: the main app
+	// only knows the active and error statuses.
 	Status     string `json:"status"`
+	Degraded   bool   `json:"degraded,omitempty"`
 	Message    string `json:"message,omitempty"`
 	StatusCode int    `json:"statusCode,omitempty"`
 	Region     string `json:"region"`
+	// Latency of the check, in milliseconds, and Timing the duration of
+	// each phase of its request, when it got a response.
+	Latency int64   `json:"latency,omitempty"`
+	Timing  *Timing `json:"timing,omitempty"`
+	// Assertions are the assertions the check failed.
+	Assertions []assertion.Result `json:"assertions,omitempty"`
+	// EventID is the ID of the result of the check which triggered the
+	// update, as sent to the sinks.
+	EventID string `json:"eventId,omitempty"`
+}
+
+// StatusUpdater updates the status of the monitors in the main app.
+type StatusUpdater interface {
+	UpdateStatus(ctx context.Context, data UpdateData) error
+}
+
+// StatusUpdaterFunc adapts a function to a StatusUpdater.
+type StatusUpdaterFunc func(ctx context.Context, data UpdateData) error
+
+func (f StatusUpdaterFunc) UpdateStatus(ctx context.Context, data UpdateData) error {
+	return f(ctx, data)
+}
+
+// BatchStatusUpdater also updates the status of several monitors at once.
+type BatchStatusUpdater interface {
+	StatusUpdater
+	UpdateStatuses(ctx context.Context, data []UpdateData) error
+}
+
+// APIUpdater updates the status of the monitors through the API.
+type APIUpdater struct {
+	httpClient    *http.Client
+	url           string
+	batchURL      string
+	authorization string
+}
+
+var _ BatchStatusUpdater = (*APIUpdater)(nil)
+
+// NewAPIUpdater returns the updater of the API at the base url, e.g. of a
+// staging or self-hosted control plane, sending the authorization header,
+// e.g. "Basic <cron secret>".
+func NewAPIUpdater(httpClient *http.Client, baseURL, authorization string) *APIUpdater {
+	return &APIUpdater{
+		httpClient:    httpClient,
+		url:           strings.TrimSuffix(baseURL, "/") + "/updateStatus",
+		batchURL:      strings.TrimSuffix(baseURL, "/") + "/updateStatuses",
+		authorization: authorization,
+	}
+}
+
+func (u *APIUpdater) UpdateStatus(ctx context.Context, data UpdateData) error {
+	return u.post(ctx, u.url, data)
 }
 
-func UpdateStatus(ctx context.Context, updateData UpdateData) {
-	url := "https://openstatus-api.fly.dev/updateStatus"
-	basic := "Basic " + os.Getenv("CRON_SECRET")
+// UpdateStatuses posts the updates to the API at once, as an array. A control
+// plane without the batch endpoint rejects it, and the queue sends the
+// updates again one by one.
+func (u *APIUpdater) UpdateStatuses(ctx context.Context, data []UpdateData) error {
+	return u.post(ctx, u.batchURL, data)
+}
+
+// UpdateStatus updates the status of the monitor through the API of
+// OpenStatus, the default StatusUpdater.
+func UpdateStatus(ctx context.Context, updateData UpdateData) error {
+	client := &http.Client{Timeout: time.Second * 10, Transport: telemetry.Transport(nil)}
+	return NewAPIUpdater(client, APIURL, "Basic "+os.Getenv("CRON_SECRET")).UpdateStatus(ctx, updateData)
+}
+
+// post posts the payload of the updates to the endpoint of the API.
+func (u *APIUpdater) post(ctx context.Context, url string, payload any) error {
 	payloadBuf := new(bytes.Buffer)
-	json.NewEncoder(payloadBuf).Encode(updateData)
+	json.NewEncoder(payloadBuf).Encode(payload)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, payloadBuf)
-	req.Header.Set("Authorization", basic)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	if u.authorization != "" {
+		req.Header.Set("Authorization", u.authorization)
+	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: time.Second * 10}
-	if _, err = client.Do(req); err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("error while updating status")
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
 	}
-	// Should we add a retry mechanism here?
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		// The rejected updates fail the same way when retried.
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	return nil
 }
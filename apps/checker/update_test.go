@@ -0,0 +1,67 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIUpdater(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("it should post the update to the configured api", func(t *testing.T) {
+		var (
+			path, authorization string
+			data                UpdateData
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, authorization = r.URL.Path, r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+		}))
+		defer server.Close()
+
+		updater := NewAPIUpdater(server.Client(), server.URL+"/", "Bearer staging")
+		require.NoError(t, updater.UpdateStatus(ctx, UpdateData{MonitorId: "1", Status: "error", Region: "ams"}))
+
+		require.Equal(t, "/updateStatus", path)
+		require.Equal(t, "Bearer staging", authorization)
+		require.Equal(t, "1", data.MonitorId)
+	})
+
+	t.Run("it should post the batches as an array", func(t *testing.T) {
+		var (
+			path string
+			data []UpdateData
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+		}))
+		defer server.Close()
+
+		updater := NewAPIUpdater(server.Client(), server.URL, "")
+		require.NoError(t, updater.UpdateStatuses(ctx, []UpdateData{{MonitorId: "1"}, {MonitorId: "2"}}))
+
+		require.Equal(t, "/updateStatuses", path)
+		require.Len(t, data, 2)
+	})
+
+	t.Run("it should not retry the rejected updates", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := NewAPIUpdater(server.Client(), server.URL, "").UpdateStatus(ctx, UpdateData{MonitorId: "1"})
+		var permanent *backoff.PermanentError
+		require.True(t, errors.As(err, &permanent))
+	})
+}
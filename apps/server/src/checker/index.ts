@@ -9,6 +9,14 @@ import { triggerAlerting, upsertMonitorStatus } from "./alerting";
 
 export const checkerRoute = new Hono();
 
+const updateSchema = z.object({
+  monitorId: z.string(),
+  status: z.enum(["active", "error"]), // that's the new status
+  message: z.string().optional(),
+  statusCode: z.number().optional(),
+  region: z.enum(flyRegions),
+});
+
 checkerRoute.post("/updateStatus", async (c) => {
   const auth = c.req.header("Authorization");
   if (auth !== `Basic ${env.CRON_SECRET}`) {
@@ -17,22 +25,42 @@ checkerRoute.post("/updateStatus", async (c) => {
   }
 
   const json = await c.req.json();
-  const schema = z.object({
-    monitorId: z.string(),
-    status: z.enum(["active", "error"]), // that's the new status
-    message: z.string().optional(),
-    statusCode: z.number().optional(),
-    region: z.enum(flyRegions),
-  });
-
-  const result = schema.safeParse(json);
+  const result = updateSchema.safeParse(json);
   if (!result.success) {
     // console.error(result.error);
     return c.text("Unprocessable Entity", 422);
   }
-  const { monitorId, status, message, region, statusCode } = result.data;
 
-  console.log(`📝 update monitor status ${JSON.stringify(result.data)}`);
+  await updateStatus(result.data);
+  return c.text("Ok", 200);
+});
+
+// The checkers send the updates of a large outage by batches. A single
+// invalid update rejects the batch, the checker then sends them one by one.
+checkerRoute.post("/updateStatuses", async (c) => {
+  const auth = c.req.header("Authorization");
+  if (auth !== `Basic ${env.CRON_SECRET}`) {
+    console.error("Unauthorized");
+    return c.text("Unauthorized", 401);
+  }
+
+  const json = await c.req.json();
+  const result = z.array(updateSchema).safeParse(json);
+  if (!result.success) {
+    return c.text("Unprocessable Entity", 422);
+  }
+
+  // One at a time, the updates of a monitor in a region being in order.
+  for (const data of result.data) {
+    await updateStatus(data);
+  }
+  return c.text("Ok", 200);
+});
+
+async function updateStatus(data: z.infer<typeof updateSchema>) {
+  const { monitorId, status, message, region, statusCode } = data;
+
+  console.log(`📝 update monitor status ${JSON.stringify(data)}`);
 
   switch (status) {
     case "active":
@@ -77,5 +105,4 @@ checkerRoute.post("/updateStatus", async (c) => {
       });
       break;
   }
-  return c.text("Ok", 200);
-});
+}